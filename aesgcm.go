@@ -0,0 +1,124 @@
+// aesgcm.go
+package gonfig
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WithEncryptionKeyFromEnv sets the env var holding the AES key used to
+// decrypt config and dotenv files ending in ".enc", as written by
+// EncryptConfig or `gonfig encrypt`. The key is 16, 24 or 32 bytes (AES-128,
+// -192 or -256), base64 or hex encoded, e.g. from `openssl rand -base64 32`.
+//
+// Files are decrypted in memory only. This is a lighter alternative to age
+// (WithAgeIdentity) or SOPS when all that's needed is a shared secret, e.g.
+// in air-gapped deployments.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config/config.yaml.enc"),
+//	    gonfig.WithEncryptionKeyFromEnv("CONFIG_KEY"),
+//	)
+func WithEncryptionKeyFromEnv(name string) Option {
+	return func(l *loader) {
+		l.encryptionKeyEnv = name
+		l.encryptionKeyFile = ""
+	}
+}
+
+// WithEncryptionKeyFile is WithEncryptionKeyFromEnv with the key read from a
+// file, such as a mounted Kubernetes or Docker secret.
+func WithEncryptionKeyFile(path string) Option {
+	return func(l *loader) {
+		l.encryptionKeyFile = path
+		l.encryptionKeyEnv = ""
+	}
+}
+
+// EncryptConfig encrypts a config or dotenv file with AES-GCM for
+// WithEncryptionKeyFromEnv and WithEncryptionKeyFile. key is encoded like
+// the value of the key env var. The result is base64 text, safe to commit
+// or put in a ConfigMap; save it with a ".enc" suffix.
+func EncryptConfig(key string, plaintext []byte) ([]byte, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	out := base64.StdEncoding.AppendEncode(nil, sealed)
+	return append(out, '\n'), nil
+}
+
+// decryptAESGCM decrypts a ".enc" file written by EncryptConfig. Raw
+// nonce||ciphertext files are accepted as well as base64 ones.
+func (l *loader) decryptAESGCM(path string, data []byte) ([]byte, error) {
+	key, err := l.encryptionKey()
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data))); err == nil {
+		data = decoded
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("decrypt %s: file too short", path)
+	}
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	out, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: wrong key or corrupted file", path)
+	}
+	return out, nil
+}
+
+// encryptionKey returns the encoded key set by WithEncryptionKeyFromEnv or
+// WithEncryptionKeyFile.
+func (l *loader) encryptionKey() (string, error) {
+	switch {
+	case l.encryptionKeyEnv != "":
+		key, ok := l.lookupEnv(l.encryptionKeyEnv)
+		if !ok || key == "" {
+			return "", fmt.Errorf("encryption key env var %s is not set", l.encryptionKeyEnv)
+		}
+		return key, nil
+	case l.encryptionKeyFile != "":
+		data, err := os.ReadFile(l.encryptionKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("read encryption key: %w", err)
+		}
+		return string(data), nil
+	}
+	return "", fmt.Errorf("file is encrypted; set WithEncryptionKeyFromEnv or WithEncryptionKeyFile")
+}
+
+// newAESGCM returns an AES-GCM cipher for a base64 or hex encoded key.
+func newAESGCM(key string) (cipher.AEAD, error) {
+	key = strings.TrimSpace(key)
+	raw, err := hex.DecodeString(key)
+	if err != nil {
+		if raw, err = base64.StdEncoding.DecodeString(key); err != nil {
+			return nil, fmt.Errorf("encryption key is neither base64 nor hex")
+		}
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be 16, 24 or 32 bytes, got %d", len(raw))
+	}
+	return cipher.NewGCM(block)
+}
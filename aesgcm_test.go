@@ -0,0 +1,80 @@
+package gonfig
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_AESGCMEncryptedConfigAndDotenv(t *testing.T) {
+	dir := t.TempDir()
+	raw := make([]byte, 32)
+	rand.Read(raw)
+	key := base64.StdEncoding.EncodeToString(raw)
+
+	cfgPath := filepath.Join(dir, "config.yaml.enc")
+	writeEncFile(t, cfgPath, key, "app_name: ${TEST_ENC_APP}\n")
+	envPath := filepath.Join(dir, ".env.enc")
+	writeEncFile(t, envPath, key, "TEST_ENC_APP=secret-svc\n")
+	t.Setenv("TEST_ENC_APP", "")
+	t.Setenv("TEST_CONFIG_KEY", key)
+
+	cfg, err := Load[testConfig](
+		WithConfigFile(cfgPath),
+		WithDotenv(envPath),
+		WithEncryptionKeyFromEnv("TEST_CONFIG_KEY"),
+		WithStrict(),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "secret-svc" {
+		t.Fatalf("expected app_name from encrypted dotenv, got %q", cfg.AppName)
+	}
+
+	// The key may also be hex encoded and read from a file.
+	keyPath := filepath.Join(dir, "config.key")
+	if err := os.WriteFile(keyPath, []byte(hex.EncodeToString(raw)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_ENC_APP", "from-env")
+	cfg, err = Load[testConfig](WithConfigFile(cfgPath), WithEncryptionKeyFile(keyPath))
+	if err != nil {
+		t.Fatalf("Load with key file: %v", err)
+	}
+	if cfg.AppName != "from-env" {
+		t.Fatalf("AppName = %q", cfg.AppName)
+	}
+
+	if _, err := Load[testConfig](WithConfigFile(cfgPath)); err == nil {
+		t.Fatalf("expected error loading encrypted config without a key")
+	}
+	rand.Read(raw)
+	t.Setenv("TEST_CONFIG_KEY", base64.StdEncoding.EncodeToString(raw))
+	_, err = Load[testConfig](WithConfigFile(cfgPath), WithEncryptionKeyFromEnv("TEST_CONFIG_KEY"))
+	if err == nil || !strings.Contains(err.Error(), "wrong key") {
+		t.Fatalf("expected wrong key error, got %v", err)
+	}
+}
+
+func TestProfilePath_Encrypted(t *testing.T) {
+	if got := profilePath("config.yaml.enc", "prod"); got != "config.prod.yaml.enc" {
+		t.Fatalf("profilePath = %q", got)
+	}
+}
+
+func writeEncFile(t *testing.T, path, key, content string) {
+	t.Helper()
+
+	data, err := EncryptConfig(key, []byte(content))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write encrypted file: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// age.go
+package gonfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// decrypt returns the plaintext of a config or dotenv file. Files ending in
+// ".age" are decrypted with the identities set by WithAgeIdentity, and
+// files ending in ".enc" with the AES key set by WithEncryptionKeyFromEnv;
+// anything else is returned unchanged.
+func (l *loader) decrypt(path string, data []byte) ([]byte, error) {
+	if strings.HasSuffix(path, ".enc") {
+		return l.decryptAESGCM(path, data)
+	}
+	if !strings.HasSuffix(path, ".age") {
+		return data, nil
+	}
+	if len(l.ageIdentityFiles) == 0 {
+		return nil, fmt.Errorf("%s is age-encrypted; set WithAgeIdentity", path)
+	}
+
+	var ids []age.Identity
+	for _, f := range l.ageIdentityFiles {
+		parsed, err := readAgeIdentities(f)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, parsed...)
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(src)
+	}
+	r, err := age.Decrypt(src, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// cutEncryptedSuffix splits the ".age" or ".enc" suffix of an encrypted
+// file off name; suffix is "" for plaintext files.
+func cutEncryptedSuffix(name string) (base, suffix string) {
+	for _, s := range []string{".age", ".enc"} {
+		if b, ok := strings.CutSuffix(name, s); ok {
+			return b, s
+		}
+	}
+	return name, ""
+}
+
+// readAgeIdentities parses an age identity file as written by age-keygen.
+func readAgeIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read age identity: %w", err)
+	}
+	defer f.Close()
+
+	ids, err := age.ParseIdentities(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("parse age identity %s: %w", path, err)
+	}
+	return ids, nil
+}
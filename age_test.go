@@ -0,0 +1,77 @@
+package gonfig
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+func TestLoad_AgeEncryptedConfigAndDotenv(t *testing.T) {
+	dir := t.TempDir()
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generate identity: %v", err)
+	}
+	keyPath := filepath.Join(dir, "age.key")
+	if err := os.WriteFile(keyPath, []byte(id.String()+"\n"), 0o600); err != nil {
+		t.Fatalf("write identity: %v", err)
+	}
+
+	cfgPath := filepath.Join(dir, "config.yaml.age")
+	writeAgeFile(t, cfgPath, id.Recipient(), "app_name: ${TEST_AGE_APP}\n", false)
+	envPath := filepath.Join(dir, ".env.age")
+	writeAgeFile(t, envPath, id.Recipient(), "TEST_AGE_APP=secret-svc\n", true)
+	t.Setenv("TEST_AGE_APP", "")
+
+	cfg, err := Load[testConfig](
+		WithConfigFile(cfgPath),
+		WithDotenv(envPath),
+		WithAgeIdentity(keyPath),
+		WithStrict(),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "secret-svc" {
+		t.Fatalf("expected app_name from encrypted dotenv, got %q", cfg.AppName)
+	}
+
+	if _, err := Load[testConfig](WithConfigFile(cfgPath)); err == nil {
+		t.Fatalf("expected error loading encrypted config without identity")
+	}
+}
+
+func writeAgeFile(t *testing.T, path string, r age.Recipient, content string, armored bool) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var dst io.Writer = &buf
+	var aw io.WriteCloser
+	if armored {
+		aw = armor.NewWriter(&buf)
+		dst = aw
+	}
+	enc, err := age.Encrypt(dst, r)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if _, err := io.WriteString(enc, content); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if aw != nil {
+		if err := aw.Close(); err != nil {
+			t.Fatalf("armor: %v", err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
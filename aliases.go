@@ -0,0 +1,122 @@
+// aliases.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyAliases walks n alongside the type t it will be decoded into and
+// renames keys listed in a field's `gonfig:"alias=old_name"` tag to the
+// field's key, so renamed keys keep loading. Every alias in use is passed
+// to warn as a WarnDeprecated warning, with the field's deprecated= message
+// if it has one; so is the key of a field tagged `gonfig:"deprecated=message"`
+// without aliases. If both the old and the new key are set, the
+// new one wins. file names the config file a key node came from.
+func applyAliases(n *yaml.Node, t reflect.Type, path string, file func(*yaml.Node) string, warn func(Warning)) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			applyAliases(c, t, path, file, warn)
+		}
+		return
+	case yaml.AliasNode:
+		applyAliases(n.Alias, t, path, file, warn)
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode || isLeafType(t) {
+			return
+		}
+		fields, _ := structFields(t)
+		aliases := make(map[string]string)
+		for key, f := range fields {
+			for _, alias := range tagOptions(f, "alias") {
+				aliases[alias] = key
+			}
+		}
+		present := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			present[n.Content[i].Value] = true
+		}
+
+		var content []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				applyAliases(v, t, path, file, warn)
+				content = append(content, k, v)
+				continue
+			}
+			if key, ok := aliases[k.Value]; ok {
+				msg := "deprecated, use " + joinPath(path, key)
+				if m := tagOptions(fields[key], "deprecated"); len(m) > 0 {
+					msg = "deprecated: " + m[0]
+				}
+				if present[key] {
+					msg += fmt.Sprintf(" (ignored, %s is set)", key)
+				}
+				warn(Warning{
+					Kind:    WarnDeprecated,
+					File:    file(k),
+					Path:    joinPath(path, k.Value),
+					Line:    k.Line,
+					Column:  k.Column,
+					Message: msg,
+				})
+				if present[key] {
+					continue
+				}
+				k.Value = key
+			} else if m := tagOptions(fields[k.Value], "deprecated"); len(m) > 0 && len(tagOptions(fields[k.Value], "alias")) == 0 {
+				warn(Warning{
+					Kind:    WarnDeprecated,
+					File:    file(k),
+					Path:    joinPath(path, k.Value),
+					Line:    k.Line,
+					Column:  k.Column,
+					Message: "deprecated: " + m[0],
+				})
+			}
+			content = append(content, k, v)
+			if f, ok := fields[k.Value]; ok {
+				applyAliases(v, f.Type, joinPath(path, k.Value), file, warn)
+			}
+		}
+		n.Content = content
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			applyAliases(n.Content[i+1], t.Elem(), joinPath(path, n.Content[i].Value), file, warn)
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range n.Content {
+			applyAliases(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), file, warn)
+		}
+	}
+}
+
+// tagOptions returns the values of every name=value option in f's gonfig
+// tag, e.g. both old names of `gonfig:"alias=port,alias=listen_port"`.
+func tagOptions(f reflect.StructField, name string) []string {
+	var vals []string
+	for _, o := range strings.Split(f.Tag.Get("gonfig"), ",") {
+		if v, ok := strings.CutPrefix(o, name+"="); ok {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
@@ -0,0 +1,130 @@
+// audit.go
+package gonfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuditKind says what changed a running config.
+type AuditKind int
+
+const (
+	// AuditReload is a reload by Watch or NewLive after the config source
+	// (or a dotenv, secret or signature file) changed.
+	AuditReload AuditKind = iota
+	// AuditOverride is a Live.Override call.
+	AuditOverride
+)
+
+func (k AuditKind) String() string {
+	switch k {
+	case AuditReload:
+		return "reload"
+	case AuditOverride:
+		return "override"
+	}
+	return "AuditKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// AuditEntry records one change of a running config.
+type AuditEntry struct {
+	Time time.Time
+	Kind AuditKind
+	// Source names the config source, e.g. the config file.
+	Source string
+	// By is who made an override, as passed to Live.Override. It is empty
+	// for reloads.
+	By string
+	// Changes is what changed, with secrets masked as in Diff.
+	Changes Changes
+}
+
+// MarshalJSON encodes e as an object with lower-case keys, kinds as
+// strings and the time in RFC 3339, for log pipelines.
+func (e AuditEntry) MarshalJSON() ([]byte, error) {
+	type change struct {
+		Path   string `json:"path"`
+		Kind   string `json:"kind"`
+		Before any    `json:"before,omitempty"`
+		After  any    `json:"after,omitempty"`
+	}
+	changes := make([]change, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = change{Path: c.Path, Kind: c.Kind.String(), Before: c.Before, After: c.After}
+	}
+	return json.Marshal(struct {
+		Time    time.Time `json:"time"`
+		Kind    string    `json:"kind"`
+		Source  string    `json:"source"`
+		By      string    `json:"by,omitempty"`
+		Changes []change  `json:"changes"`
+	}{e.Time, e.Kind.String(), e.Source, e.By, changes})
+}
+
+// AuditSink receives an AuditEntry for every reload or override that
+// changed a running config. Audit is called from the watcher goroutine, or
+// from Live.Override, one entry at a time; errors are passed to the handler
+// set by WithReloadErrorHandler.
+type AuditSink interface {
+	Audit(e AuditEntry) error
+}
+
+// AuditFunc adapts a function to an AuditSink.
+type AuditFunc func(e AuditEntry) error
+
+// Audit calls f(e).
+func (f AuditFunc) Audit(e AuditEntry) error { return f(e) }
+
+// WithAuditSink records every runtime change of a config loaded with Watch
+// or NewLive to sink: who made it (for Live.Override), what changed and
+// when, with old and new values and secrets masked. The initial load and
+// reloads that change nothing aren't recorded.
+//
+// Example:
+//
+//	f, err := os.OpenFile("config-audit.jsonl", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithAuditSink(gonfig.NewJSONAuditSink(f)),
+//	)
+func WithAuditSink(sink AuditSink) Option {
+	return func(l *loader) {
+		l.auditSink = sink
+	}
+}
+
+// NewJSONAuditSink returns a sink that writes every entry to w as a line
+// of JSON (see AuditEntry.MarshalJSON).
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	return &jsonAuditSink{enc: json.NewEncoder(w)}
+}
+
+type jsonAuditSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (s *jsonAuditSink) Audit(e AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(e)
+}
+
+// audit records changes made by kind and by with the WithAuditSink sink.
+func (l *loader) audit(kind AuditKind, by string, changes Changes) {
+	if l.auditSink == nil || len(changes) == 0 {
+		return
+	}
+	e := AuditEntry{Time: time.Now(), Kind: kind, Source: l.configFile, By: by, Changes: changes}
+	if err := l.auditSink.Audit(e); err != nil {
+		l.reloadError(fmt.Errorf("audit %s: %w", kind, err))
+	}
+}
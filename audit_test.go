@@ -0,0 +1,99 @@
+// audit_test.go
+package gonfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLive_AuditSink(t *testing.T) {
+	type config struct {
+		Port     int    `yaml:"port"`
+		LogLevel string `yaml:"log_level"`
+		Password Secret `yaml:"password"`
+	}
+	path := writeConfig(t, "port: 8080\nlog_level: info\npassword: old\n")
+
+	var (
+		mu      sync.Mutex
+		entries []AuditEntry
+	)
+	sink := AuditFunc(func(e AuditEntry) error {
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, e)
+		return nil
+	})
+	recorded := func() []AuditEntry {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]AuditEntry(nil), entries...)
+	}
+	live, err := NewLive[config](t.Context(), WithConfigFile(path), WithAuditSink(sink), WithReloadDebounce(0))
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+
+	replaceFile(t, path, "port: 9090\nlog_level: info\npassword: new\n")
+	waitFor(t, func() bool { return len(recorded()) == 1 })
+	e := recorded()[0]
+	if e.Kind != AuditReload || e.Source != path || e.By != "" || len(e.Changes) != 2 {
+		t.Fatalf("reload entry = %+v", e)
+	}
+	if c := e.Changes[1]; c.Path != "password" || c.Before != redacted || c.After != redacted {
+		t.Fatalf("secret change not masked: %+v", c)
+	}
+
+	if err := live.Override("log_level", "debug", "alice"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+	if got := live.Get().LogLevel; got != "debug" {
+		t.Fatalf("LogLevel = %q after Override", got)
+	}
+	e = recorded()[1]
+	if e.Kind != AuditOverride || e.By != "alice" || len(e.Changes) != 1 || e.Changes[0].After != "debug" {
+		t.Fatalf("override entry = %+v", e)
+	}
+
+	if err := live.Override("no_such_key", 1, "bob"); err == nil {
+		t.Fatalf("expected Override of an unknown path to fail")
+	}
+	if n := len(recorded()); n != 2 {
+		t.Fatalf("failed override was recorded: %d entries", n)
+	}
+
+	// The override sticks through reloads.
+	replaceFile(t, path, "port: 7070\nlog_level: info\npassword: new\n")
+	waitFor(t, func() bool { return live.Get().Port == 7070 })
+	if got := live.Get().LogLevel; got != "debug" {
+		t.Fatalf("LogLevel = %q after reload, want the override", got)
+	}
+}
+
+func TestJSONAuditSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONAuditSink(&buf)
+	err := sink.Audit(AuditEntry{
+		Kind:    AuditOverride,
+		Source:  "config.yaml",
+		By:      "alice",
+		Changes: Changes{{Path: "server.port", Kind: Modified, Before: 8080, After: 9090}},
+	})
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "}\n") {
+		t.Fatalf("entry isn't a JSON line: %q", buf.String())
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	change := got["changes"].([]any)[0].(map[string]any)
+	if got["kind"] != "override" || got["by"] != "alice" || change["kind"] != "modified" || change["after"] != 9090.0 {
+		t.Fatalf("entry = %s", buf.String())
+	}
+}
@@ -0,0 +1,137 @@
+// Package aws provides gonfig resolvers backed by AWS Systems Manager
+// Parameter Store and AWS Secrets Manager, and an S3 config source.
+//
+// Placeholders:
+//
+//   - ${ssm:/app/prod/db_password}     -> SSM parameter value (decrypted)
+//   - ${awssecret:prod/db}             -> whole SecretString of a secret
+//   - ${awssecret:prod/db#password}    -> one key of a JSON SecretString
+//
+// Credentials come from the standard AWS SDK chain (env vars, shared config,
+// SSO, instance/task roles) via config.LoadDefaultConfig.
+//
+// Usage:
+//
+//	import gonfigaws "github.com/TypeTerrors/gonfig/aws"
+//
+//	awsCfg, err := config.LoadDefaultConfig(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfigaws.WithSSM(awsCfg),
+//	    gonfigaws.WithSecretsManager(awsCfg),
+//	)
+//
+// To read the config document itself from S3:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfigaws.WithS3("s3://my-bucket/services/api/config.yaml"),
+//	)
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// SSMAPI is the subset of the SSM client used by SSMResolver.
+type SSMAPI interface {
+	GetParameter(ctx context.Context, in *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// SecretsManagerAPI is the subset of the Secrets Manager client used by
+// SecretsManagerResolver.
+type SecretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, in *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// WithSSM registers the "ssm" placeholder scheme using a client built from
+// cfg.
+func WithSSM(cfg awssdk.Config) gonfig.Option {
+	return gonfig.WithResolver("ssm", SSMResolver(ssm.NewFromConfig(cfg)))
+}
+
+// WithSecretsManager registers the "awssecret" placeholder scheme using a
+// client built from cfg.
+func WithSecretsManager(cfg awssdk.Config) gonfig.Option {
+	return gonfig.WithResolver("awssecret", SecretsManagerResolver(secretsmanager.NewFromConfig(cfg)))
+}
+
+// SSMResolver resolves a parameter name (e.g. "/app/prod/token") to its
+// value. SecureString parameters are decrypted. A missing parameter is
+// reported as gonfig.ErrNotFound so ${ssm:/x:-default} works.
+func SSMResolver(client SSMAPI) gonfig.ResolverFunc {
+	return func(ctx context.Context, name string) (string, error) {
+		out, err := client.GetParameter(ctx, &ssm.GetParameterInput{
+			Name:           awssdk.String(name),
+			WithDecryption: awssdk.Bool(true),
+		})
+		if err != nil {
+			var nf *ssmtypes.ParameterNotFound
+			if errors.As(err, &nf) {
+				return "", fmt.Errorf("ssm parameter %s: %w", name, gonfig.ErrNotFound)
+			}
+			return "", fmt.Errorf("ssm parameter %s: %w", name, err)
+		}
+		if out.Parameter == nil {
+			return "", fmt.Errorf("ssm parameter %s: %w", name, gonfig.ErrNotFound)
+		}
+		return awssdk.ToString(out.Parameter.Value), nil
+	}
+}
+
+// SecretsManagerResolver resolves "name" to the secret's SecretString, or
+// "name#key" to a single key of a JSON SecretString. Missing secrets and
+// missing keys are reported as gonfig.ErrNotFound.
+func SecretsManagerResolver(client SecretsManagerAPI) gonfig.ResolverFunc {
+	return func(ctx context.Context, ref string) (string, error) {
+		name, key, hasKey := strings.Cut(ref, "#")
+		out, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+			SecretId: awssdk.String(name),
+		})
+		if err != nil {
+			var nf *smtypes.ResourceNotFoundException
+			if errors.As(err, &nf) {
+				return "", fmt.Errorf("secret %s: %w", name, gonfig.ErrNotFound)
+			}
+			return "", fmt.Errorf("secret %s: %w", name, err)
+		}
+
+		secret := awssdk.ToString(out.SecretString)
+		if out.SecretString == nil {
+			secret = string(out.SecretBinary)
+		}
+		if !hasKey {
+			return secret, nil
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+			return "", fmt.Errorf("secret %s is not a JSON object: %w", name, err)
+		}
+		raw, ok := fields[key]
+		if !ok {
+			return "", fmt.Errorf("secret %s key %s: %w", name, key, gonfig.ErrNotFound)
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s, nil
+		}
+		// Non-string JSON values (numbers, bools) are used verbatim.
+		return string(raw), nil
+	}
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+type fakeSSM map[string]string
+
+func (f fakeSSM) GetParameter(_ context.Context, in *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	v, ok := f[awssdk.ToString(in.Name)]
+	if !ok {
+		return nil, &ssmtypes.ParameterNotFound{}
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssmtypes.Parameter{Value: awssdk.String(v)}}, nil
+}
+
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) GetSecretValue(_ context.Context, in *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	v, ok := f[awssdk.ToString(in.SecretId)]
+	if !ok {
+		return nil, &smtypes.ResourceNotFoundException{}
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: awssdk.String(v)}, nil
+}
+
+func TestResolvers(t *testing.T) {
+	raw := "token: ${ssm:/app/prod/token}\n" +
+		"region: ${ssm:/app/prod/region:-us-east-1}\n" +
+		"password: ${awssecret:prod/db#password}\n" +
+		"port: ${awssecret:prod/db#port}\n"
+
+	cfg, err := gonfig.Load[map[string]any](
+		gonfig.WithBytes([]byte(raw)),
+		gonfig.WithStrict(),
+		gonfig.WithResolver("ssm", SSMResolver(fakeSSM{"/app/prod/token": "t0k3n"})),
+		gonfig.WithResolver("awssecret", SecretsManagerResolver(fakeSecrets{
+			"prod/db": `{"password":"s3cret","port":5432}`,
+		})),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["token"] != "t0k3n" || cfg["region"] != "us-east-1" || cfg["password"] != "s3cret" || cfg["port"] != 5432 {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+}
+
+func TestSecretsManagerResolver_MissingKey(t *testing.T) {
+	resolve := SecretsManagerResolver(fakeSecrets{"prod/db": `{"password":"s3cret"}`})
+
+	if _, err := resolve(context.Background(), "prod/db#user"); !errors.Is(err, gonfig.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing key, got %v", err)
+	}
+	if _, err := resolve(context.Background(), "prod/other"); !errors.Is(err, gonfig.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing secret, got %v", err)
+	}
+}
+
+type fakeS3 map[string]string
+
+func (f fakeS3) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	k := awssdk.ToString(in.Bucket) + "/" + awssdk.ToString(in.Key) + "@" + awssdk.ToString(in.VersionId)
+	v, ok := f[k]
+	if !ok {
+		return nil, &s3types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(v))}, nil
+}
+
+func TestS3Source(t *testing.T) {
+	src, err := NewS3Source(fakeS3{"bucket/svc/config.yaml@v2": "app_name: from-s3\n"}, "s3://bucket/svc/config.yaml?versionId=v2")
+	if err != nil {
+		t.Fatalf("NewS3Source: %v", err)
+	}
+
+	cfg, err := gonfig.Load[map[string]any](gonfig.WithSource(src))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["app_name"] != "from-s3" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+}
+
+func TestS3Source_NotFound(t *testing.T) {
+	src, err := NewS3Source(fakeS3{}, "s3://bucket/svc/missing.yaml")
+	if err != nil {
+		t.Fatalf("NewS3Source: %v", err)
+	}
+	_, err = src.Fetch(context.Background())
+	var nsk *s3types.NoSuchKey
+	if !errors.Is(err, fs.ErrNotExist) || !errors.As(err, &nsk) {
+		t.Fatalf("expected fs.ErrNotExist wrapping NoSuchKey, got %v", err)
+	}
+}
+
+func TestNewS3Source_InvalidURI(t *testing.T) {
+	for _, uri := range []string{"https://bucket/key", "s3://bucket", "s3:///key"} {
+		if _, err := NewS3Source(nil, uri); err == nil {
+			t.Fatalf("expected error for %q", uri)
+		}
+	}
+}
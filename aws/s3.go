@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/TypeTerrors/gonfig"
+	"github.com/TypeTerrors/gonfig/internal/remote"
+)
+
+// S3API is the subset of the S3 client used by S3Source.
+type S3API interface {
+	GetObject(ctx context.Context, in *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// WithS3 reads the config from an S3 object, e.g.
+// "s3://my-bucket/services/api/config.yaml". Pin a specific version with
+// "?versionId=...".
+//
+// The client is created on first use from the default AWS credential chain.
+func WithS3(uri string) gonfig.Option {
+	src, err := NewS3Source(nil, uri)
+	if err != nil {
+		return gonfig.WithSource(remote.ErrSource(err))
+	}
+	return gonfig.WithSource(src)
+}
+
+// S3Source fetches a config document from S3.
+type S3Source struct {
+	Bucket    string
+	Key       string
+	VersionID string
+
+	client  S3API
+	clients remote.Client[S3API]
+}
+
+// NewS3Source parses an s3://bucket/key[?versionId=...] URI. If client is
+// nil, one is created on first Fetch from the default credential chain.
+func NewS3Source(client S3API, uri string) (*S3Source, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("parse S3 URI %q: %w", uri, err)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Scheme != "s3" || u.Host == "" || key == "" {
+		return nil, fmt.Errorf("invalid S3 URI %q (expected s3://bucket/key)", uri)
+	}
+	return &S3Source{
+		Bucket:    u.Host,
+		Key:       key,
+		VersionID: u.Query().Get("versionId"),
+		client:    client,
+	}, nil
+}
+
+// Fetch implements gonfig.Source.
+func (s *S3Source) Fetch(ctx context.Context) ([]byte, error) {
+	client, err := s.clients.Get(func() (S3API, error) {
+		if s.client != nil {
+			return s.client, nil
+		}
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load AWS config: %w", err)
+		}
+		return s3.NewFromConfig(cfg), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	in := &s3.GetObjectInput{
+		Bucket: awssdk.String(s.Bucket),
+		Key:    awssdk.String(s.Key),
+	}
+	if s.VersionID != "" {
+		in.VersionId = awssdk.String(s.VersionID)
+	}
+	out, err := client.GetObject(ctx, in)
+	if err != nil {
+		var nsk *s3types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, fmt.Errorf("get %s: %w: %w", s, fs.ErrNotExist, err)
+		}
+		return nil, fmt.Errorf("get %s: %w", s, err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+// String implements fmt.Stringer so errors name the object.
+func (s *S3Source) String() string {
+	return "s3://" + s.Bucket + "/" + s.Key
+}
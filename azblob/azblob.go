@@ -0,0 +1,91 @@
+// Package azblob provides a gonfig config source backed by Azure Blob
+// Storage.
+//
+// Credentials come from azidentity.DefaultAzureCredential (environment,
+// workload identity, managed identity, Azure CLI).
+//
+// Usage:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    azblob.WithAzureBlob("https://myaccount.blob.core.windows.net/configs/api/config.yaml"),
+//	)
+package azblob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+
+	"github.com/TypeTerrors/gonfig"
+	"github.com/TypeTerrors/gonfig/internal/remote"
+)
+
+// WithAzureBlob reads the config from a blob URL, e.g.
+// "https://myaccount.blob.core.windows.net/configs/api/config.yaml".
+// Pin a specific blob version with "?versionid=...".
+func WithAzureBlob(blobURL string) gonfig.Option {
+	src, err := NewSource(nil, blobURL)
+	if err != nil {
+		return gonfig.WithSource(remote.ErrSource(err))
+	}
+	return gonfig.WithSource(src)
+}
+
+// Source fetches a config document from Azure Blob Storage.
+type Source struct {
+	URL string
+
+	cred    azcore.TokenCredential
+	clients remote.Client[*blob.Client]
+}
+
+// NewSource validates blobURL. If cred is nil, DefaultAzureCredential is
+// created on first Fetch.
+func NewSource(cred azcore.TokenCredential, blobURL string) (*Source, error) {
+	parts, err := blob.ParseURL(blobURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse blob URL %q: %w", blobURL, err)
+	}
+	if parts.ContainerName == "" || parts.BlobName == "" {
+		return nil, fmt.Errorf("invalid blob URL %q (expected https://account.blob.core.windows.net/container/blob)", blobURL)
+	}
+	return &Source{URL: blobURL, cred: cred}, nil
+}
+
+// Fetch implements gonfig.Source.
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	client, err := s.clients.Get(func() (*blob.Client, error) {
+		cred := s.cred
+		if cred == nil {
+			var err error
+			if cred, err = azidentity.NewDefaultAzureCredential(nil); err != nil {
+				return nil, fmt.Errorf("create Azure credential: %w", err)
+			}
+		}
+		return blob.NewClient(s.URL, cred, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.DownloadStream(ctx, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+			return nil, fmt.Errorf("get %s: %w: %w", s.URL, fs.ErrNotExist, err)
+		}
+		return nil, fmt.Errorf("get %s: %w", s.URL, err)
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// String implements fmt.Stringer so errors name the blob.
+func (s *Source) String() string {
+	return s.URL
+}
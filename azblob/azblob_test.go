@@ -0,0 +1,14 @@
+package azblob
+
+import "testing"
+
+func TestNewSource(t *testing.T) {
+	if _, err := NewSource(nil, "https://acct.blob.core.windows.net/configs/api/config.yaml?versionid=2024-01-01T00:00:00.0000000Z"); err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	for _, u := range []string{"https://acct.blob.core.windows.net/", "https://acct.blob.core.windows.net/configs"} {
+		if _, err := NewSource(nil, u); err == nil {
+			t.Fatalf("expected error for %q", u)
+		}
+	}
+}
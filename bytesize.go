@@ -0,0 +1,163 @@
+// bytesize.go
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ByteSize is a size in bytes that decodes from human-readable values such
+// as "512kb", "10MiB" or "1.5GB", as well as plain integers.
+//
+// Decimal units (kB, MB, GB, TB, PB, EB) are powers of 1000 and binary
+// units (KiB, MiB, GiB, TiB, PiB, EiB) powers of 1024. Units are
+// case-insensitive and the trailing "B" is optional, so "10m" is 10 MB and
+// "10mi" is 10 MiB.
+//
+// To keep a plain int64 (or other integer) field, tag it instead:
+//
+//	MaxBody int64 `yaml:"max_body" gonfig:"bytes"`
+type ByteSize int64
+
+var byteUnits = map[string]float64{
+	"":  1,
+	"k": 1e3, "m": 1e6, "g": 1e9, "t": 1e12, "p": 1e15, "e": 1e18,
+	"ki": 1 << 10, "mi": 1 << 20, "gi": 1 << 30, "ti": 1 << 40, "pi": 1 << 50, "ei": 1 << 60,
+}
+
+// ParseByteSize parses a size like "10MiB" or "512kb". Sizes of 8EiB
+// (2^63 bytes) and more don't fit in a ByteSize and fail.
+func ParseByteSize(s string) (ByteSize, error) {
+	in := strings.TrimSpace(s)
+	i := 0
+	for i < len(in) && (in[i] >= '0' && in[i] <= '9' || in[i] == '.') {
+		i++
+	}
+	num, unit := in[:i], strings.ToLower(strings.TrimSpace(in[i:]))
+	unit = strings.TrimSuffix(unit, "b")
+	mult, ok := byteUnits[unit]
+	if num == "" || !ok {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	overflow := fmt.Errorf("byte size %q overflows int64", s)
+	if !strings.Contains(num, ".") {
+		// Whole numbers are exact: multiply as integers.
+		n, err := strconv.ParseInt(num, 10, 64)
+		if errors.Is(err, strconv.ErrRange) || err == nil && n > math.MaxInt64/int64(mult) {
+			return 0, overflow
+		}
+		if err != nil {
+			return 0, fmt.Errorf("invalid byte size %q", s)
+		}
+		return ByteSize(n * int64(mult)), nil
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which is already too big.
+	size := n * mult
+	if size >= math.MaxInt64 {
+		return 0, overflow
+	}
+	return ByteSize(size), nil
+}
+
+// String formats b with the largest binary unit that divides it exactly,
+// e.g. "10MiB", falling back to bytes ("1500B").
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		name string
+		size ByteSize
+	}{{"EiB", 1 << 60}, {"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.name
+		}
+	}
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (b *ByteSize) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: byte size must be a scalar", n.Line)
+	}
+	v, err := ParseByteSize(n.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+	*b = v
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (b ByteSize) MarshalYAML() (any, error) { return b.String(), nil }
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// fieldDecoder returns the decoder selected by a field's tags, e.g.
+// `gonfig:"bytes"` on an integer field, `gonfig:"sep=;"` on a list or
+// `layout:"2006-01-02"` on a time.Time.
+func fieldDecoder(f reflect.StructField) (decodeFunc, bool) {
+	if fn, ok := timeDecoder(f); ok {
+		return fn, true
+	}
+	if seps := tagOptions(f, "sep"); len(seps) > 0 && seps[0] != "" {
+		if t, ok := listType(f.Type); ok {
+			sep := seps[0]
+			if named, ok := listSeparators[sep]; ok {
+				sep = named
+			}
+			return listDecoder(t, sep), true
+		}
+	}
+	if !hasTagOption(f, "bytes") {
+		return nil, false
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		return nil, false
+	}
+	return func(s string) (reflect.Value, error) {
+		size, err := ParseByteSize(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(t).Elem()
+		if v.CanInt() {
+			if v.OverflowInt(int64(size)) {
+				return reflect.Value{}, fmt.Errorf("byte size %q overflows %s", s, t)
+			}
+			v.SetInt(int64(size))
+		} else {
+			if v.OverflowUint(uint64(size)) {
+				return reflect.Value{}, fmt.Errorf("byte size %q overflows %s", s, t)
+			}
+			v.SetUint(uint64(size))
+		}
+		return v, nil
+	}, true
+}
@@ -0,0 +1,85 @@
+package gonfig
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]ByteSize{
+		"0":                   0,
+		"1024":                1024,
+		"512kb":               512_000,
+		"512KiB":              512 << 10,
+		"10MiB":               10 << 20,
+		"10mi":                10 << 20,
+		"1.5GB":               1_500_000_000,
+		"2 GiB":               2 << 30,
+		"1TB":                 1e12,
+		"100B":                100,
+		"7EiB":                7 << 60,
+		"9223372036854775807": math.MaxInt64,
+		"0.5EiB":              1 << 59,
+	} {
+		got, err := ParseByteSize(in)
+		if err != nil {
+			t.Fatalf("ParseByteSize(%q): %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("ParseByteSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+	for _, in := range []string{"", "MiB", "-1MB", "10XB", "1.2.3MB", "99999PiB",
+		"8EiB", "8192PiB", "9223372036854775808", "8.0EiB", "9.3EB"} {
+		if _, err := ParseByteSize(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	for b, want := range map[ByteSize]string{0: "0B", 1500: "1500B", 10 << 20: "10MiB", 3 << 30: "3GiB"} {
+		if got := b.String(); got != want {
+			t.Fatalf("ByteSize(%d).String() = %q, want %q", int64(b), got, want)
+		}
+	}
+	out, err := yaml.Marshal(map[string]ByteSize{"max": 10 << 20})
+	if err != nil || strings.TrimSpace(string(out)) != "max: 10MiB" {
+		t.Fatalf("unexpected YAML %q (%v)", out, err)
+	}
+}
+
+func TestLoad_ByteSize(t *testing.T) {
+	type config struct {
+		MaxBody  ByteSize `yaml:"max_body"`
+		Cache    int64    `yaml:"cache" gonfig:"bytes"`
+		Buffer   *uint32  `yaml:"buffer" gonfig:"bytes"`
+		Upload   int64    `yaml:"upload" gonfig:"bytes" default:"1MiB"`
+		Download ByteSize `yaml:"download"`
+	}
+	t.Setenv("APP_DOWNLOAD", "2MB")
+
+	cfg, err := Load[config](
+		WithBytes([]byte("max_body: 10MiB\ncache: 512kb\nbuffer: 64KiB\n")),
+		WithEnvOverrides("APP"),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.MaxBody != 10<<20 || cfg.Cache != 512_000 || cfg.Buffer == nil || *cfg.Buffer != 64<<10 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Upload != 1<<20 || cfg.Download != 2_000_000 {
+		t.Fatalf("unexpected default/override: upload=%d download=%d", cfg.Upload, cfg.Download)
+	}
+
+	type small struct {
+		Size uint8 `yaml:"size" gonfig:"bytes"`
+	}
+	if _, err := Load[small](WithBytes([]byte("size: 1KB\n"))); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Fatalf("expected overflow error, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -39,6 +40,10 @@ func main() {
 		runPrint(os.Args[2:])
 	case "gen-go":
 		runGenGo(os.Args[2:])
+	case "diff":
+		runDiff(os.Args[2:])
+	case "encrypt":
+		runEncrypt(os.Args[2:])
 	case "interactive", "menu":
 		runInteractive()
 	default:
@@ -230,6 +235,156 @@ func runPrint(args []string) {
 	}
 }
 
+// defaultSecretKeys are the keys whose values diff masks unless -secret-keys
+// says otherwise.
+const defaultSecretKeys = "*password*,*passwd*,*secret*,*token*,*api_key*,*apikey*,*private_key*,*credential*"
+
+// runDiff implements the "diff" subcommand. It resolves two config files
+// the same way as print and lists the values that differ, one per line,
+// with the values of secret-looking keys masked. Like diff(1), it exits
+// with status 1 when the files differ.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	var (
+		dotenvPath string
+		strict     bool
+		secretKeys string
+	)
+	fs.StringVar(&dotenvPath, "dotenv", "", "Optional .env file to load before parsing both configs")
+	fs.BoolVar(&strict, "strict", false, "Enable strict mode (missing ${VAR} without default -> error)")
+	fs.StringVar(&secretKeys, "secret-keys", defaultSecretKeys, "Comma-separated key patterns (path.Match, any case) whose values are masked")
+	if err := fs.Parse(args); err != nil {
+		log.Fatalf("failed to parse flags: %v", err)
+	}
+	if fs.NArg() != 2 {
+		log.Fatalf("usage: gonfig diff [-dotenv file] [-strict] [-secret-keys patterns] old.yaml new.yaml")
+	}
+	var cfgs [2]map[string]any
+	for i, path := range fs.Args() {
+		opts := []gonfig.Option{gonfig.WithConfigFile(path)}
+		if dotenvPath != "" {
+			opts = append(opts, gonfig.WithDotenv(dotenvPath))
+		}
+		if strict {
+			opts = append(opts, gonfig.WithStrict())
+		}
+		cfg, err := gonfig.Load[map[string]any](opts...)
+		if err != nil {
+			log.Fatalf("failed to load %s: %v", path, err)
+		}
+		maskSecrets(cfg, strings.Split(secretKeys, ","))
+		cfgs[i] = cfg
+	}
+	changes := gonfig.Diff(cfgs[0], cfgs[1])
+	fmt.Print(formatChanges(changes))
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+// runEncrypt implements the "encrypt" subcommand: it encrypts a config or
+// dotenv file for gonfig.WithEncryptionKeyFromEnv, writing file.enc next to
+// it unless -o says otherwise.
+func runEncrypt(args []string) {
+	fs := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	var keyEnv, keyFile, out string
+	fs.StringVar(&keyEnv, "key-env", "CONFIG_KEY", "Env var holding the base64 or hex AES key")
+	fs.StringVar(&keyFile, "key-file", "", "File holding the key (overrides -key-env)")
+	fs.StringVar(&out, "o", "", "Output file (default: input file + .enc)")
+	if err := fs.Parse(args); err != nil {
+		log.Fatalf("failed to parse flags: %v", err)
+	}
+	if fs.NArg() != 1 {
+		log.Fatalf("usage: gonfig encrypt [-key-env NAME | -key-file path] [-o out] config.yaml")
+	}
+	in := fs.Arg(0)
+	if out == "" {
+		out = in + ".enc"
+	}
+
+	key := os.Getenv(keyEnv)
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			log.Fatalf("failed to read key: %v", err)
+		}
+		key = string(data)
+	}
+	if key == "" {
+		log.Fatalf("no key: set $%s or -key-file (generate one with: openssl rand -base64 32)", keyEnv)
+	}
+
+	plaintext, err := os.ReadFile(in)
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", in, err)
+	}
+	sealed, err := gonfig.EncryptConfig(key, plaintext)
+	if err != nil {
+		log.Fatalf("failed to encrypt %s: %v", in, err)
+	}
+	if err := os.WriteFile(out, sealed, 0o600); err != nil {
+		log.Fatalf("failed to write %s: %v", out, err)
+	}
+}
+
+// maskSecrets turns the values of keys in v matching one of patterns into
+// gonfig.Secret, so Diff reports them changed without showing them. A
+// matching key masks everything below it.
+func maskSecrets(v any, patterns []string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if val != nil && matchesAny(strings.ToLower(k), patterns) {
+				v[k] = gonfig.Secret(fmt.Sprint(val))
+				continue
+			}
+			maskSecrets(val, patterns)
+		}
+	case []any:
+		for _, item := range v {
+			maskSecrets(item, patterns)
+		}
+	}
+}
+
+// matchesAny reports whether key matches one of patterns, compared in
+// lower case.
+func matchesAny(key string, patterns []string) bool {
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if ok, _ := path.Match(p, key); ok && p != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatChanges renders changes like a diff: "+" for added values, "-" for
+// removed ones and "~" for modified ones, with values as JSON.
+func formatChanges(changes gonfig.Changes) string {
+	var b strings.Builder
+	for _, c := range changes {
+		switch c.Kind {
+		case gonfig.Added:
+			fmt.Fprintf(&b, "+ %s: %s\n", c.Path, jsonValue(c.After))
+		case gonfig.Removed:
+			fmt.Fprintf(&b, "- %s: %s\n", c.Path, jsonValue(c.Before))
+		default:
+			fmt.Fprintf(&b, "~ %s: %s -> %s\n", c.Path, jsonValue(c.Before), jsonValue(c.After))
+		}
+	}
+	return b.String()
+}
+
+// jsonValue renders v as compact JSON, falling back to %v.
+func jsonValue(v any) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
+
 // runGenGo implements the "gen-go" subcommand. It parses the YAML config
 // structure and emits a Go struct definition. It expects flag-style args.
 func runGenGo(args []string) {
@@ -6,6 +6,8 @@ import (
 	"go/token"
 	"strings"
 	"testing"
+
+	"github.com/TypeTerrors/gonfig"
 )
 
 func TestGenerateGoCode_TopLevelSectionsBecomeNamedTypes(t *testing.T) {
@@ -105,3 +107,28 @@ func assertGeneratedGoParses(t *testing.T, code string) {
 		t.Fatalf("failed to parse generated code: %v\n\n%s", err, string(formatted))
 	}
 }
+
+func TestFormatChanges(t *testing.T) {
+	old := map[string]any{"server": map[string]any{"port": 8080, "host": "a"}, "debug": true}
+	new := map[string]any{"server": map[string]any{"port": 9090, "host": "a"}, "tags": []any{"x"}}
+
+	got := formatChanges(gonfig.Diff(old, new))
+	want := "- debug: true\n~ server.port: 8080 -> 9090\n+ tags: [\"x\"]\n"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatChanges_MasksSecrets(t *testing.T) {
+	old := map[string]any{"database": map[string]any{"host": "a", "Password": "old"}, "tokens": []any{"t1"}}
+	new := map[string]any{"database": map[string]any{"host": "b", "Password": "new"}, "api_key": "k"}
+	patterns := strings.Split(defaultSecretKeys, ",")
+	maskSecrets(old, patterns)
+	maskSecrets(new, patterns)
+
+	got := formatChanges(gonfig.Diff(old, new))
+	want := "+ api_key: \"***\"\n~ database.Password: \"***\" -> \"***\"\n~ database.host: \"a\" -> \"b\"\n- tokens: \"***\"\n"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
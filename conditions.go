@@ -0,0 +1,283 @@
+// conditions.go
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithConditionalSections lets mappings carry a when: condition that keeps
+// or prunes them, so small per-environment differences don't need a file
+// each:
+//
+//	database:
+//	  host: db.internal
+//	  replica:
+//	    when: ${APP_ENV} == "prod"
+//	    host: replica.db.internal
+//	features:
+//	  - name: tracing
+//	    when: ${APP_ENV} != "dev" && ${TRACING:-on} == on
+//
+// A condition compares operands with == and !=, combines them with &&, ||
+// and !, and groups with parentheses. Operands are quoted strings or bare
+// words, either of which may hold ${VAR} placeholders; an unset variable
+// is empty. An operand on its own is true unless it is empty, "false" or
+// "0", so when: ${ENABLE_CACHE} works too.
+//
+// Conditions are evaluated in every config layer before placeholders are
+// expanded, so a pruned section can refer to variables that aren't set.
+// The when key itself is always removed; a false condition removes its
+// mapping from the parent mapping or list, or empties the layer when it is
+// on the top-level mapping.
+func WithConditionalSections() Option {
+	return func(l *loader) {
+		l.conditions = true
+	}
+}
+
+// applyConditions evaluates the when: condition of n, if it is a mapping,
+// and those below it, and reports whether n is to be kept.
+func (l *loader) applyConditions(n *yaml.Node) (bool, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return l.applyConditions(n.Content[0])
+		}
+	case yaml.MappingNode:
+		if i := mappingIndex(n, "when"); i >= 0 {
+			cond := n.Content[i+1]
+			if cond.Kind != yaml.ScalarNode {
+				return false, fmt.Errorf("line %d: when must be a condition, not a %s", cond.Line, kindName(cond))
+			}
+			ok, err := l.evalCondition(cond.Value)
+			if err != nil {
+				return false, fmt.Errorf("line %d: when %q: %w", cond.Line, cond.Value, err)
+			}
+			if !ok {
+				return false, nil
+			}
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+		}
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			keep, err := l.applyConditions(n.Content[i+1])
+			if err != nil {
+				return false, err
+			}
+			if keep {
+				content = append(content, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		content := n.Content[:0]
+		for _, item := range n.Content {
+			keep, err := l.applyConditions(item)
+			if err != nil {
+				return false, err
+			}
+			if keep {
+				content = append(content, item)
+			}
+		}
+		n.Content = content
+	}
+	return true, nil
+}
+
+// kindName names the kind of a YAML node in errors.
+func kindName(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	return "scalar"
+}
+
+// evalCondition evaluates a when: condition.
+func (l *loader) evalCondition(s string) (bool, error) {
+	toks, err := tokenizeCondition(s)
+	if err != nil {
+		return false, err
+	}
+	p := &condParser{toks: toks, l: l}
+	ok, err := p.or()
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %s", p.toks[p.pos].text)
+	}
+	return ok, err
+}
+
+// condToken is an operator, a parenthesis or an operand of a condition.
+type condToken struct {
+	text    string
+	operand bool
+}
+
+// tokenizeCondition splits a condition into tokens. A bare operand runs
+// until whitespace, an operator or a parenthesis; ${...} placeholders are
+// kept whole, whatever their defaults contain.
+func tokenizeCondition(s string) ([]condToken, error) {
+	var toks []condToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.HasPrefix(s[i:], "==") || strings.HasPrefix(s[i:], "!=") ||
+			strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||"):
+			toks = append(toks, condToken{text: s[i : i+2]})
+			i += 2
+		case c == '!' || c == '(' || c == ')':
+			toks = append(toks, condToken{text: s[i : i+1]})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, errors.New("unterminated string")
+			}
+			toks = append(toks, condToken{text: s[i+1 : i+1+end], operand: true})
+			i += end + 2
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t=!&|()\"'", rune(s[i])) {
+				if strings.HasPrefix(s[i:], "${") {
+					depth := 0
+					for ; i < len(s); i++ {
+						if s[i] == '{' {
+							depth++
+						} else if s[i] == '}' {
+							if depth--; depth == 0 {
+								break
+							}
+						}
+					}
+					if i == len(s) {
+						return nil, errors.New("unterminated ${")
+					}
+				}
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected %q", s[i])
+			}
+			toks = append(toks, condToken{text: s[start:i], operand: true})
+		}
+	}
+	return toks, nil
+}
+
+// condParser evaluates condition tokens by recursive descent:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" or ")" | compare
+//	compare = operand [ ("==" | "!=") operand ]
+type condParser struct {
+	toks []condToken
+	pos  int
+	l    *loader
+}
+
+func (p *condParser) peek(text string) bool {
+	return p.pos < len(p.toks) && !p.toks[p.pos].operand && p.toks[p.pos].text == text
+}
+
+func (p *condParser) or() (bool, error) {
+	v, err := p.and()
+	for err == nil && p.peek("||") {
+		p.pos++
+		var r bool
+		r, err = p.and()
+		v = v || r
+	}
+	return v, err
+}
+
+func (p *condParser) and() (bool, error) {
+	v, err := p.unary()
+	for err == nil && p.peek("&&") {
+		p.pos++
+		var r bool
+		r, err = p.unary()
+		v = v && r
+	}
+	return v, err
+}
+
+func (p *condParser) unary() (bool, error) {
+	switch {
+	case p.peek("!"):
+		p.pos++
+		v, err := p.unary()
+		return !v, err
+	case p.peek("("):
+		p.pos++
+		v, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if !p.peek(")") {
+			return false, errors.New("missing )")
+		}
+		p.pos++
+		return v, nil
+	}
+	return p.compare()
+}
+
+func (p *condParser) compare() (bool, error) {
+	left, err := p.operand()
+	if err != nil {
+		return false, err
+	}
+	op := ""
+	if p.peek("==") || p.peek("!=") {
+		op = p.toks[p.pos].text
+		p.pos++
+	}
+	if op == "" {
+		return left != "" && left != "false" && left != "0", nil
+	}
+	right, err := p.operand()
+	if err != nil {
+		return false, err
+	}
+	return (left == right) == (op == "=="), nil
+}
+
+// operand expands the placeholders in the next operand, unless
+// WithoutExpansion is set. Unset variables are empty, whatever the
+// missing-variable policy.
+func (p *condParser) operand() (string, error) {
+	if p.pos == len(p.toks) {
+		return "", errors.New("missing operand")
+	}
+	tok := p.toks[p.pos]
+	if !tok.operand {
+		return "", fmt.Errorf("unexpected %s", tok.text)
+	}
+	p.pos++
+	if p.l.noExpansion {
+		return tok.text, nil
+	}
+	mode := p.l.expandMode()
+	mode.strict, mode.keep = false, false
+	v, missing, err := expandString(tok.text, p.l.lookup, mode)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range missing {
+		if m.required {
+			return "", fmt.Errorf("%s is not set: %s", m.name, m.message)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,83 @@
+// conditions_test.go
+package gonfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoad_WithConditionalSections(t *testing.T) {
+	type feature struct {
+		Name string `yaml:"name"`
+	}
+	type config struct {
+		AppName  string `yaml:"app_name"`
+		Database struct {
+			Host    string `yaml:"host"`
+			Replica *struct {
+				Host     string `yaml:"host"`
+				Password string `yaml:"password"`
+			} `yaml:"replica"`
+		} `yaml:"database"`
+		Features []feature `yaml:"features"`
+	}
+	path := writeConfig(t, `app_name: api
+database:
+  host: db.internal
+  replica:
+    when: ${APP_ENV} == "prod"
+    host: replica.db.internal
+    password: ${REPLICA_PASSWORD}
+features:
+  - name: tracing
+    when: ${APP_ENV} != dev && (${TRACING:-on} == on || ${FORCE_TRACING})
+  - name: debug-endpoints
+    when: '!${PUBLIC}'
+  - name: always
+`)
+	load := func(env map[string]string) (config, error) {
+		return Load[config](WithConfigFile(path), WithConditionalSections(), WithStrict(),
+			WithEnvLookup(func(name string) (string, bool) {
+				v, ok := env[name]
+				return v, ok
+			}))
+	}
+	names := func(cfg config) string {
+		var out []string
+		for _, f := range cfg.Features {
+			out = append(out, f.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// The pruned replica's ${REPLICA_PASSWORD} isn't needed in strict mode.
+	cfg, err := load(map[string]string{"APP_ENV": "dev"})
+	if err != nil {
+		t.Fatalf("Load dev: %v", err)
+	}
+	if cfg.Database.Replica != nil || names(cfg) != "debug-endpoints,always" {
+		t.Fatalf("dev: unexpected config: %+v", cfg)
+	}
+
+	cfg, err = load(map[string]string{"APP_ENV": "prod", "REPLICA_PASSWORD": "x", "PUBLIC": "true", "TRACING": "off", "FORCE_TRACING": "1"})
+	if err != nil {
+		t.Fatalf("Load prod: %v", err)
+	}
+	if cfg.Database.Replica == nil || cfg.Database.Replica.Host != "replica.db.internal" || names(cfg) != "tracing,always" {
+		t.Fatalf("prod: unexpected config: %+v", cfg)
+	}
+
+	path = writeConfig(t, "app_name: api\ndatabase:\n  when: ${APP_ENV} ==\n  host: x\n")
+	_, err = load(nil)
+	var pe *ParseError
+	if !errors.As(err, &pe) || !strings.Contains(err.Error(), `line 3: when "${APP_ENV} ==": missing operand`) {
+		t.Fatalf("expected a ParseError for the bad condition, got %v", err)
+	}
+
+	// Without the option, when is an ordinary key.
+	path = writeConfig(t, "app_name: api\nfeatures:\n  - name: x\n    when: 'false'\n")
+	if _, err := Load[config](WithConfigFile(path), WithKnownFieldsOnly()); err == nil {
+		t.Fatal("expected when to be an unknown key without WithConditionalSections")
+	}
+}
@@ -0,0 +1,199 @@
+// Package consul provides a gonfig config source backed by Consul KV.
+//
+// The config is either a single key holding a YAML or JSON document, or a
+// key prefix whose subtree is turned into a document: "app/server/port"
+// under prefix "app/" becomes server.port.
+//
+// The client is configured from the standard Consul environment variables
+// (CONSUL_HTTP_ADDR, CONSUL_HTTP_TOKEN, ...). The source implements
+// gonfig.Watcher using blocking queries, so Watch and NewLive reload the
+// config as soon as it changes in Consul.
+//
+// Usage:
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    consul.WithKey("services/api/config.yaml"),
+//	)
+package consul
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/TypeTerrors/gonfig"
+	"github.com/TypeTerrors/gonfig/internal/kvtree"
+	"github.com/TypeTerrors/gonfig/internal/remote"
+)
+
+// KV is the subset of the Consul KV client used by Source. *api.KV
+// implements it.
+type KV interface {
+	Get(key string, q *api.QueryOptions) (*api.KVPair, *api.QueryMeta, error)
+	List(prefix string, q *api.QueryOptions) (api.KVPairs, *api.QueryMeta, error)
+}
+
+// WithKey reads the config from a single Consul key holding a YAML or JSON
+// document.
+func WithKey(key string) gonfig.Option {
+	return gonfig.WithSource(NewSource(nil, key))
+}
+
+// WithPrefix builds the config from every key under prefix. Each key's
+// value becomes a scalar that is typed like plain YAML, so "8080" decodes
+// into an int field.
+func WithPrefix(prefix string) gonfig.Option {
+	return gonfig.WithSource(NewPrefixSource(nil, prefix))
+}
+
+// Source fetches a config document from Consul KV.
+type Source struct {
+	// Key is the key, or the key prefix when Prefix is set.
+	Key    string
+	Prefix bool
+	// WaitTime bounds each blocking query made by Watch. The default is
+	// five minutes.
+	WaitTime time.Duration
+
+	kv      KV
+	clients remote.Client[KV]
+
+	mu    sync.Mutex
+	index uint64
+}
+
+// NewSource reads a single key. If kv is nil, a client is created on first
+// Fetch from the Consul environment variables.
+func NewSource(kv KV, key string) *Source {
+	return &Source{Key: key, kv: kv}
+}
+
+// NewPrefixSource reads every key under prefix. If kv is nil, a client is
+// created on first Fetch from the Consul environment variables.
+func NewPrefixSource(kv KV, prefix string) *Source {
+	return &Source{Key: prefix, Prefix: true, kv: kv}
+}
+
+// Fetch implements gonfig.Source.
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	data, index, err := s.query(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+	return data, nil
+}
+
+// Watch implements gonfig.Watcher with Consul blocking queries, starting
+// from the index of the last Fetch. Failed queries are retried with backoff.
+func (s *Source) Watch(ctx context.Context, changed func()) error {
+	wait := s.WaitTime
+	if wait <= 0 {
+		wait = 5 * time.Minute
+	}
+	s.mu.Lock()
+	index := s.index
+	s.mu.Unlock()
+	if index == 0 {
+		// Not fetched yet: start from the current index.
+		_, next, err := s.query(ctx, 0, 0)
+		if err != nil && next == 0 {
+			return err
+		}
+		index = next
+	}
+
+	go func() {
+		backoff := time.Second
+		for {
+			_, next, err := s.query(ctx, index, wait)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && next == 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(2*backoff, 30*time.Second)
+				continue
+			}
+			backoff = time.Second
+
+			switch {
+			case next < index:
+				// The index went backwards (e.g. a snapshot restore): start
+				// over, as recommended by the Consul docs.
+				index = 0
+				changed()
+			case next != index:
+				index = next
+				changed()
+			}
+		}
+	}()
+	return nil
+}
+
+// String names the source in errors.
+func (s *Source) String() string {
+	if s.Prefix {
+		return "consul://" + s.Key + "*"
+	}
+	return "consul://" + s.Key
+}
+
+// query reads the key or prefix. With a non-zero index it blocks until the
+// data changes or wait elapses. A non-zero index is returned with the error
+// when Consul answered but the data is unusable, e.g. a missing key.
+func (s *Source) query(ctx context.Context, index uint64, wait time.Duration) ([]byte, uint64, error) {
+	kv, err := s.client()
+	if err != nil {
+		return nil, 0, err
+	}
+	q := (&api.QueryOptions{WaitIndex: index, WaitTime: wait}).WithContext(ctx)
+
+	if !s.Prefix {
+		pair, meta, err := kv.Get(s.Key, q)
+		if err != nil {
+			return nil, 0, fmt.Errorf("consul get %s: %w", s.Key, err)
+		}
+		if pair == nil {
+			return nil, meta.LastIndex, fmt.Errorf("consul key %s not found", s.Key)
+		}
+		return pair.Value, meta.LastIndex, nil
+	}
+
+	pairs, meta, err := kv.List(s.Key, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul list %s: %w", s.Key, err)
+	}
+	kvs := make([]kvtree.Pair, len(pairs))
+	for i, p := range pairs {
+		kvs[i] = kvtree.Pair{Key: p.Key, Value: p.Value}
+	}
+	data, err := kvtree.Marshal(s.Key, kvs)
+	if err != nil {
+		return nil, meta.LastIndex, fmt.Errorf("consul %w", err)
+	}
+	return data, meta.LastIndex, nil
+}
+
+func (s *Source) client() (KV, error) {
+	return s.clients.Get(func() (KV, error) {
+		if s.kv != nil {
+			return s.kv, nil
+		}
+		c, err := api.NewClient(api.DefaultConfig())
+		if err != nil {
+			return nil, fmt.Errorf("create consul client: %w", err)
+		}
+		return c.KV(), nil
+	})
+}
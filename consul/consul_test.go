@@ -0,0 +1,123 @@
+package consul
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// fakeKV serves pairs at index. Blocking queries wait on bump.
+type fakeKV struct {
+	pairs api.KVPairs
+	index uint64
+	bump  chan uint64
+}
+
+func (f *fakeKV) wait(q *api.QueryOptions) uint64 {
+	if q.WaitIndex == 0 || q.WaitIndex != f.index {
+		return f.index
+	}
+	select {
+	case f.index = <-f.bump:
+	case <-q.Context().Done():
+	}
+	return f.index
+}
+
+func (f *fakeKV) Get(key string, q *api.QueryOptions) (*api.KVPair, *api.QueryMeta, error) {
+	index := f.wait(q)
+	for _, p := range f.pairs {
+		if p.Key == key {
+			return p, &api.QueryMeta{LastIndex: index}, nil
+		}
+	}
+	return nil, &api.QueryMeta{LastIndex: index}, nil
+}
+
+func (f *fakeKV) List(prefix string, q *api.QueryOptions) (api.KVPairs, *api.QueryMeta, error) {
+	return f.pairs, &api.QueryMeta{LastIndex: f.wait(q)}, nil
+}
+
+type config struct {
+	Server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"server"`
+}
+
+func TestKey(t *testing.T) {
+	kv := &fakeKV{index: 1, pairs: api.KVPairs{
+		{Key: "svc/config.yaml", Value: []byte("server:\n  port: 8080\n")},
+	}}
+	cfg, err := gonfig.Load[config](gonfig.WithSource(NewSource(kv, "svc/config.yaml")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8080 {
+		t.Fatalf("expected port 8080, got %d", cfg.Server.Port)
+	}
+
+	_, err = gonfig.Load[config](gonfig.WithSource(NewSource(kv, "svc/missing")))
+	if err == nil {
+		t.Fatalf("expected error for missing key")
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	kv := &fakeKV{index: 1, pairs: api.KVPairs{
+		{Key: "svc/"},
+		{Key: "svc/server/host", Value: []byte("${HOST:-localhost}")},
+		{Key: "svc/server/port", Value: []byte("8080")},
+		{Key: "svcother/server/port", Value: []byte("1")},
+	}}
+	cfg, err := gonfig.Load[config](gonfig.WithSource(NewPrefixSource(kv, "svc")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Host != "localhost" || cfg.Server.Port != 8080 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestPrefix_Conflict(t *testing.T) {
+	kv := &fakeKV{index: 1, pairs: api.KVPairs{
+		{Key: "svc/server", Value: []byte("x")},
+		{Key: "svc/server/port", Value: []byte("8080")},
+	}}
+	if _, err := NewPrefixSource(kv, "svc/").Fetch(context.Background()); err == nil {
+		t.Fatalf("expected error for a key that is both a value and a folder")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	kv := &fakeKV{index: 1, bump: make(chan uint64), pairs: api.KVPairs{
+		{Key: "svc/config.yaml", Value: []byte("server:\n  port: 8080\n")},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := gonfig.Watch[config](ctx, gonfig.WithSource(NewSource(kv, "svc/config.yaml")))
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if cfg := <-updates; cfg.Server.Port != 8080 {
+		t.Fatalf("expected initial port 8080, got %d", cfg.Server.Port)
+	}
+
+	kv.pairs[0] = &api.KVPair{Key: "svc/config.yaml", Value: []byte("server:\n  port: 9090\n")}
+	kv.bump <- 2
+
+	select {
+	case cfg := <-updates:
+		if cfg.Server.Port != 9090 {
+			t.Fatalf("expected reloaded port 9090, got %d", cfg.Server.Port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reloaded config")
+	}
+}
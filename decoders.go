@@ -0,0 +1,392 @@
+// decoders.go
+package gonfig
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeFunc parses a scalar into a value of the registered type.
+type decodeFunc func(s string) (reflect.Value, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[reflect.Type]decodeFunc{}
+)
+
+func init() {
+	RegisterDecoder(func(s string) (url.URL, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return url.URL{}, err
+		}
+		return *u, nil
+	})
+	RegisterDecoder(func(s string) (net.IPNet, error) {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return net.IPNet{}, err
+		}
+		return *n, nil
+	})
+}
+
+// RegisterDecoder makes gonfig decode YAML scalars into fields of type T (or
+// *T) with fn. Use it for third-party types that don't implement
+// yaml.Unmarshaler or encoding.TextUnmarshaler. Registered decoders also
+// apply to `default:"..."` tags and WithEnvOverrides.
+//
+// Out of the box, url.URL and net.IPNet (CIDR notation) are registered;
+// time.Duration, time.Time (RFC 3339, or see the `layout` and `tz` tags),
+// net.IP, netip.Addr, netip.Prefix and *regexp.Regexp already decode
+// natively.
+//
+// Register decoders during init; registering a type again replaces its
+// decoder.
+//
+// Example:
+//
+//	func init() {
+//	    gonfig.RegisterDecoder(func(s string) (decimal.Decimal, error) {
+//	        return decimal.NewFromString(s)
+//	    })
+//	}
+func RegisterDecoder[T any](fn func(s string) (T, error)) {
+	t := reflect.TypeFor[T]()
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[t] = func(s string) (reflect.Value, error) {
+		v, err := fn(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+}
+
+// decoderFor returns the decoder for t or, if t is a pointer, for its
+// element type.
+func decoderFor(t reflect.Type) (decodeFunc, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	if fn, ok := decoders[t]; ok {
+		return fn, true
+	}
+	if t.Kind() == reflect.Pointer {
+		fn, ok := decoders[t.Elem()]
+		return fn, ok
+	}
+	return nil, false
+}
+
+// setDecoded sets v, of the registered type or a pointer to it, from s.
+func setDecoded(v reflect.Value, fn decodeFunc, s string) error {
+	dv, err := fn(s)
+	if err != nil {
+		return err
+	}
+	if v.Kind() == reflect.Pointer && dv.Type() != v.Type() {
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(dv)
+		dv = p
+	}
+	v.Set(dv)
+	return nil
+}
+
+// decodeWithHooks decodes n into out, using registered decoders for scalars
+// whose target type has one. yaml.v3 has no decode hooks, so those scalars
+// are swapped for the target's zero value while yaml.v3 decodes, and set
+// afterwards. Fields tagged `gonfig:"squash"`, which yaml.v3 doesn't know
+// about, are decoded from their parent's mapping in between.
+func decodeWithHooks(n *yaml.Node, out any) error {
+	t := reflect.TypeOf(out).Elem()
+	hooked := make(map[*yaml.Node]*hook)
+	hookedNodes(n, t, nil, hooked)
+	squash := hasSquash(t, make(map[reflect.Type]bool))
+	if len(hooked) == 0 && !squash {
+		return n.Decode(out)
+	}
+
+	for node, h := range hooked {
+		h.orig = *node
+		*node = zeroNode(h.target)
+	}
+	err := n.Decode(out)
+	if err == nil && squash {
+		err = decodeSquashed(n, reflect.ValueOf(out).Elem())
+	}
+	for node, h := range hooked {
+		*node = h.orig
+	}
+	if err != nil {
+		return err
+	}
+	return applyHooks(n, reflect.ValueOf(out).Elem(), hooked)
+}
+
+// hook is a scalar decoded by a registered or tag-selected decoder, or a
+// section decoded into a registered variant (RegisterVariant).
+type hook struct {
+	target  reflect.Type
+	fn      decodeFunc
+	variant bool
+	orig    yaml.Node
+}
+
+// zeroNode encodes the zero value of t. Unlike a null, it decodes into
+// every kind, so sequence items and map entries aren't dropped.
+func zeroNode(t reflect.Type) yaml.Node {
+	var n yaml.Node
+	if err := n.Encode(reflect.Zero(t).Interface()); err != nil {
+		return yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	}
+	return n
+}
+
+// hookedNodes walks n alongside the type t it will be decoded into and
+// records every non-null scalar whose target has a decoder: fn, selected by
+// the enclosing field's tag, or one registered for t. Scalars written for a
+// list are split on commas. Mappings decoded into an interface with
+// registered variants are recorded too.
+func hookedNodes(n *yaml.Node, t reflect.Type, fn decodeFunc, out map[*yaml.Node]*hook) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			hookedNodes(n.Content[0], t, fn, out)
+		}
+		return
+	case yaml.AliasNode:
+		hookedNodes(n.Alias, t, fn, out)
+		return
+	case yaml.MappingNode:
+		if fn == nil && hasVariants(t) {
+			out[n] = &hook{target: t, variant: true}
+			return
+		}
+	}
+	if fn == nil {
+		fn, _ = decoderFor(t)
+	}
+	if fn == nil && n.Kind == yaml.ScalarNode {
+		// A single "a,b,c" for a list.
+		if lt, ok := listType(t); ok {
+			fn = listDecoder(lt, ",")
+		}
+	}
+	if fn != nil && n.Kind == yaml.ScalarNode {
+		if n.Tag != "!!null" {
+			out[n] = &hook{target: t, fn: fn}
+		}
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		fields, _ := structFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				hookedNodes(v, t, nil, out)
+				continue
+			}
+			if f, ok := fields[k.Value]; ok {
+				fieldFn, _ := fieldDecoder(f)
+				hookedNodes(v, f.Type, fieldFn, out)
+			}
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			hookedNodes(n.Content[i+1], t.Elem(), nil, out)
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range n.Content {
+			hookedNodes(item, t.Elem(), nil, out)
+		}
+	}
+}
+
+// applyHooks walks n alongside the decoded value v and sets the values of
+// the hooked scalars.
+func applyHooks(n *yaml.Node, v reflect.Value, hooked map[*yaml.Node]*hook) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return applyHooks(n.Content[0], v, hooked)
+	case yaml.AliasNode:
+		return applyHooks(n.Alias, v, hooked)
+	}
+	if h, ok := hooked[n]; ok && h.variant {
+		dv, err := decodeVariant(h.target, n)
+		if err != nil {
+			return err
+		}
+		v.Set(dv)
+		return nil
+	}
+	if h, ok := hooked[n]; ok {
+		if err := setDecoded(v, h.fn, n.Value); err != nil {
+			return fmt.Errorf("line %d: cannot decode %q into %s: %w", n.Line, n.Value, v.Type(), err)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return applyHooks(n, v.Elem(), hooked)
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, val := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				if err := applyHooks(val, v, hooked); err != nil {
+					return err
+				}
+				continue
+			}
+			f, _, ok := fieldByKey(v, k.Value)
+			if !ok {
+				continue
+			}
+			if err := applyHooks(val, f, hooked); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode || v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := reflect.ValueOf(n.Content[i].Value).Convert(v.Type().Key())
+			cur := v.MapIndex(key)
+			if !cur.IsValid() {
+				continue
+			}
+			// Map elements are not addressable: copy, update, store back.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(cur)
+			if err := applyHooks(n.Content[i+1], elem, hooked); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, item := range n.Content {
+			if i >= v.Len() {
+				break
+			}
+			if err := applyHooks(item, v.Index(i), hooked); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldByKey returns the field of struct v decoded from YAML key, and its
+// struct field, looking through inlined structs.
+func fieldByKey(v reflect.Value, key string) (reflect.Value, reflect.StructField, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, inline, skip := yamlFieldName(f)
+		if skip {
+			continue
+		}
+		if !inline {
+			if name == key {
+				return v.Field(i), f, true
+			}
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct {
+			if inner, sf, ok := fieldByKey(fv, key); ok {
+				return inner, sf, true
+			}
+		}
+	}
+	return reflect.Value{}, reflect.StructField{}, false
+}
+
+// listType returns the slice type behind t if values of t can be written as
+// a separated string, i.e. t is a slice (or pointer to one) other than
+// []byte without a decoder of its own.
+func listType(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice || t.Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	if _, ok := decoderFor(t); ok {
+		return nil, false
+	}
+	return t, true
+}
+
+// listSeparators are the names sep= accepts for separators that can't be
+// written in a gonfig tag, such as a comma, or are easy to misread there.
+var listSeparators = map[string]string{
+	"comma":   ",",
+	"space":   " ",
+	"tab":     "\t",
+	"newline": "\n",
+}
+
+// listDecoder returns a decoder that splits a scalar on sep into a slice of
+// type t, setting each trimmed item like an env override. An empty string
+// is an empty list.
+func listDecoder(t reflect.Type, sep string) decodeFunc {
+	return func(s string) (reflect.Value, error) {
+		out := reflect.MakeSlice(t, 0, 0)
+		if strings.TrimSpace(s) == "" {
+			return out, nil
+		}
+		for i, item := range strings.Split(s, sep) {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := setFromString(elem, strings.TrimSpace(item)); err != nil {
+				return reflect.Value{}, fmt.Errorf("item %d: %w", i, err)
+			}
+			out = reflect.Append(out, elem)
+		}
+		return out, nil
+	}
+}
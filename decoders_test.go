@@ -0,0 +1,170 @@
+package gonfig
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testLevel int
+
+func init() {
+	RegisterDecoder(func(s string) (testLevel, error) {
+		switch s {
+		case "low":
+			return 1, nil
+		case "high":
+			return 2, nil
+		}
+		return 0, errors.New("unknown level")
+	})
+}
+
+func TestLoad_DecodeHooks(t *testing.T) {
+	type config struct {
+		Timeout  time.Duration        `yaml:"timeout"`
+		Endpoint *url.URL             `yaml:"endpoint"`
+		Mirrors  []url.URL            `yaml:"mirrors"`
+		Network  netip.Prefix         `yaml:"network"`
+		Legacy   *net.IPNet           `yaml:"legacy"`
+		Pattern  *regexp.Regexp       `yaml:"pattern"`
+		Since    time.Time            `yaml:"since"`
+		Level    testLevel            `yaml:"level"`
+		Levels   map[string]testLevel `yaml:"levels"`
+		Fallback *url.URL             `yaml:"fallback" default:"http://localhost:8080"`
+	}
+
+	cfg, err := Load[config](WithBytes([]byte(`
+timeout: 30s
+endpoint: https://api.example.com/v1
+mirrors: [https://a.example.com, https://b.example.com]
+network: 10.0.0.0/8
+legacy: 192.168.0.0/16
+pattern: ^svc-[a-z]+$
+since: 2024-01-02T03:04:05Z
+level: high
+levels:
+  db: low
+`)))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Timeout != 30*time.Second {
+		t.Fatalf("timeout: got %v", cfg.Timeout)
+	}
+	if cfg.Endpoint == nil || cfg.Endpoint.Host != "api.example.com" || cfg.Endpoint.Path != "/v1" {
+		t.Fatalf("endpoint: got %v", cfg.Endpoint)
+	}
+	if len(cfg.Mirrors) != 2 || cfg.Mirrors[1].Host != "b.example.com" {
+		t.Fatalf("mirrors: got %v", cfg.Mirrors)
+	}
+	if cfg.Network.String() != "10.0.0.0/8" {
+		t.Fatalf("network: got %v", cfg.Network)
+	}
+	if cfg.Legacy == nil || cfg.Legacy.String() != "192.168.0.0/16" {
+		t.Fatalf("legacy: got %v", cfg.Legacy)
+	}
+	if cfg.Pattern == nil || !cfg.Pattern.MatchString("svc-api") {
+		t.Fatalf("pattern: got %v", cfg.Pattern)
+	}
+	if cfg.Since.Year() != 2024 {
+		t.Fatalf("since: got %v", cfg.Since)
+	}
+	if cfg.Level != 2 || cfg.Levels["db"] != 1 {
+		t.Fatalf("levels: got %v %v", cfg.Level, cfg.Levels)
+	}
+	if cfg.Fallback == nil || cfg.Fallback.Port() != "8080" {
+		t.Fatalf("fallback default: got %v", cfg.Fallback)
+	}
+}
+
+func TestLoad_DecodeHookErrors(t *testing.T) {
+	type config struct {
+		Level testLevel `yaml:"level"`
+	}
+	_, err := Load[config](WithBytes([]byte("\nlevel: extreme\n")))
+	var pErr *ParseError
+	if !errors.As(err, &pErr) {
+		t.Fatalf("expected *ParseError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "line 2") || !strings.Contains(err.Error(), "unknown level") {
+		t.Fatalf("expected line and decoder error, got %v", err)
+	}
+}
+
+func TestLoad_DecodeHooksWithEnvOverrides(t *testing.T) {
+	type config struct {
+		Endpoint url.URL `yaml:"endpoint"`
+	}
+	t.Setenv("APP_ENDPOINT", "https://override.example.com")
+	cfg, err := Load[config](WithBytes([]byte("endpoint: https://api.example.com\n")), WithEnvOverrides("APP"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Endpoint.Host != "override.example.com" {
+		t.Fatalf("expected overridden endpoint, got %v", cfg.Endpoint.String())
+	}
+}
+
+func TestLoad_Squash(t *testing.T) {
+	type TLSConfig struct {
+		CertFile string  `yaml:"cert_file" gonfig:"required"`
+		MinTLS   string  `yaml:"min_tls" default:"1.2"`
+		CA       url.URL `yaml:"ca"`
+		KeyFile  string  `yaml:"key_file" env:"TEST_SQUASH_KEY"`
+	}
+	type server struct {
+		Port      int `yaml:"port"`
+		TLSConfig `gonfig:"squash"`
+	}
+	type config struct {
+		Server server `yaml:"server"`
+		Admin  struct {
+			TLS TLSConfig `gonfig:"squash"`
+		} `yaml:"admin"`
+	}
+	t.Setenv("TEST_SQUASH_KEY", "/etc/key.pem")
+	t.Setenv("APP_ADMIN_CERT_FILE", "/etc/admin.pem")
+	raw := "server:\n  port: 8443\n  cert_file: /etc/cert.pem\n  ca: https://ca.example.com\nadmin:\n  cert_file: placeholder\n"
+
+	cfg, report, err := LoadWithReport[config](WithBytes([]byte(raw)), WithEnvOverrides("APP"), WithKnownFieldsOnly())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	s := cfg.Server
+	if s.Port != 8443 || s.CertFile != "/etc/cert.pem" || s.MinTLS != "1.2" || s.CA.Host != "ca.example.com" || s.KeyFile != "/etc/key.pem" {
+		t.Fatalf("Server = %+v", s)
+	}
+	if cfg.Admin.TLS.CertFile != "/etc/admin.pem" {
+		t.Fatalf("Admin.TLS = %+v", cfg.Admin.TLS)
+	}
+	for path, kind := range map[string]OriginKind{
+		"server.cert_file": FromFile,
+		"server.min_tls":   FromDefault,
+		"server.key_file":  FromEnv,
+		"admin.cert_file":  FromEnv,
+	} {
+		if got := report.Origins[path].Kind; got != kind {
+			t.Errorf("origin of %s = %v, want %v", path, got, kind)
+		}
+	}
+
+	view, err := NewView(cfg)
+	if err != nil {
+		t.Fatalf("NewView: %v", err)
+	}
+	if got := view.GetString("admin.cert_file"); got != "/etc/admin.pem" {
+		t.Fatalf("view admin.cert_file = %q", got)
+	}
+
+	_, err = Load[config](WithBytes([]byte("server:\n  port: 1\n")))
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Path != "server.cert_file" {
+		t.Fatalf("expected required error for server.cert_file, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+// defaults.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// applyDefaults fills fields tagged with `default:"..."` before the config is
+// decoded, so values present in the YAML win and missing ones keep the
+// default. Tag values are parsed like env overrides: strings verbatim,
+// everything else as YAML, e.g. `default:"30s"` or `default:"[a, b]"`.
+//
+// record is called with the path of every field set from a tag. Nested
+// structs are walked; nil pointers to structs, and elements of slices
+// and maps, are left alone since they don't exist yet.
+func applyDefaults(v reflect.Value, path string, record func(path string)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key, inline, skip := yamlFieldName(f)
+		if skip {
+			continue
+		}
+		fieldPath := path
+		if !inline {
+			fieldPath = joinPath(path, key)
+		}
+		fv := v.Field(i)
+
+		if def, ok := f.Tag.Lookup("default"); ok {
+			set := setFromString
+			if fn, ok := fieldDecoder(f); ok {
+				set = func(v reflect.Value, s string) error { return setDecoded(v, fn, s) }
+			}
+			if err := set(fv, def); err != nil {
+				return fmt.Errorf("default for %s: %w", fieldPath, err)
+			}
+			record(fieldPath)
+			continue
+		}
+		if fv.Kind() == reflect.Struct && !isLeafType(fv.Type()) {
+			if err := applyDefaults(fv, fieldPath, record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setDefaults calls SetDefaults() on v and on every nested struct that
+// implements it with a pointer receiver, innermost first so an outer
+// SetDefaults can still adjust what an inner one chose.
+func setDefaults(v reflect.Value) {
+	if v.Kind() == reflect.Struct && !isLeafType(v.Type()) {
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.CanAddr() {
+				setDefaults(fv)
+			}
+		}
+	}
+	if d, ok := v.Addr().Interface().(interface{ SetDefaults() }); ok {
+		d.SetDefaults()
+	}
+}
@@ -0,0 +1,81 @@
+// Package di provides gonfig configs to dependency injection containers:
+// modules for Uber fx and provider functions for Google wire.
+//
+// With fx, add a module for the config type; the rest of the graph takes
+// Config (or *gonfig.Live[Config]) as a parameter:
+//
+//	import gonfigdi "github.com/TypeTerrors/gonfig/di"
+//
+//	fx.New(
+//	    gonfigdi.Module[Config](
+//	        gonfig.WithConfigFile("config.yaml"),
+//	        gonfig.WithEnvOverrides("APP"),
+//	    ),
+//	    fx.Invoke(func(cfg Config) { /* ... */ }),
+//	).Run()
+//
+// LiveModule provides a hot-reloaded *gonfig.Live[Config] instead, whose
+// watcher stops with the app.
+//
+// wire can't use generic functions as providers, so wrap Load or NewLive in
+// a provider of your own:
+//
+//	func provideConfig() (Config, error) {
+//	    return gonfigdi.Load[Config](gonfig.WithConfigFile("config.yaml"))
+//	}
+//
+//	func provideLive(ctx context.Context) (*gonfig.Live[Config], func(), error) {
+//	    return gonfigdi.NewLive[Config](ctx, gonfig.WithConfigFile("config.yaml"))
+//	}
+package di
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// Module returns an fx module that loads T with opts when the app is built
+// and provides it to the graph. A load error fails the app.
+func Module[T any](opts ...gonfig.Option) fx.Option {
+	return fx.Module("gonfig", fx.Provide(func() (T, error) {
+		return Load[T](opts...)
+	}))
+}
+
+// LiveModule returns an fx module that provides a *gonfig.Live[T] loaded
+// with opts, and T as loaded at startup. The Live handle keeps watching the
+// config source until the app stops; components that must see reloads
+// should take it rather than T.
+func LiveModule[T any](opts ...gonfig.Option) fx.Option {
+	return fx.Module("gonfig", fx.Provide(func(lc fx.Lifecycle) (*gonfig.Live[T], T, error) {
+		live, stop, err := NewLive[T](context.Background(), opts...)
+		if err != nil {
+			var zero T
+			return nil, zero, err
+		}
+		lc.Append(fx.StopHook(stop))
+		return live, live.Get(), nil
+	}))
+}
+
+// Load is a provider of T loaded with opts. It is gonfig.Load, for
+// symmetry with NewLive.
+func Load[T any](opts ...gonfig.Option) (T, error) {
+	return gonfig.Load[T](opts...)
+}
+
+// NewLive is a provider of a *gonfig.Live[T] loaded with opts, in the shape
+// wire expects: the returned cleanup function stops the watcher. It also
+// stops when ctx is cancelled.
+func NewLive[T any](ctx context.Context, opts ...gonfig.Option) (*gonfig.Live[T], func(), error) {
+	ctx, cancel := context.WithCancel(ctx)
+	live, err := gonfig.NewLive[T](ctx, opts...)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return live, cancel, nil
+}
@@ -0,0 +1,76 @@
+package di
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+type config struct {
+	Port int `yaml:"port"`
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestModule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "port: 8080\n")
+
+	var cfg config
+	app := fxtest.New(t, Module[config](gonfig.WithConfigFile(path)), fx.Populate(&cfg))
+	app.RequireStart().RequireStop()
+	if cfg.Port != 8080 {
+		t.Fatalf("Port = %d, want 8080", cfg.Port)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	if err := fx.New(Module[config](gonfig.WithConfigFile(missing)), fx.Populate(&cfg), fx.NopLogger).Err(); err == nil {
+		t.Fatalf("expected the app to fail on a missing config")
+	}
+}
+
+func TestLiveModule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "port: 8080\n")
+
+	var (
+		live *gonfig.Live[config]
+		cfg  config
+	)
+	app := fxtest.New(t,
+		LiveModule[config](gonfig.WithConfigFile(path), gonfig.WithReloadDebounce(time.Millisecond)),
+		fx.Populate(&live, &cfg),
+	)
+	app.RequireStart()
+	if cfg.Port != 8080 || live.Get().Port != 8080 {
+		t.Fatalf("Port = %d, live %d, want 8080", cfg.Port, live.Get().Port)
+	}
+
+	writeConfig(t, path, "port: 9090\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for live.Get().Port != 9090 {
+		if time.Now().After(deadline) {
+			t.Fatalf("live config not reloaded, Port = %d", live.Get().Port)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	app.RequireStop()
+	time.Sleep(50 * time.Millisecond)
+	writeConfig(t, path, "port: 7070\n")
+	time.Sleep(200 * time.Millisecond)
+	if got := live.Get().Port; got != 9090 {
+		t.Fatalf("config reloaded after the app stopped, Port = %d", got)
+	}
+}
@@ -0,0 +1,205 @@
+// diff.go
+package gonfig
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind says how a value differs between two configs.
+type ChangeKind int
+
+const (
+	// Added values are only in the new config.
+	Added ChangeKind = iota
+	// Removed values are only in the old config.
+	Removed
+	// Modified values are in both, with different contents.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Change is a single difference between two configs.
+type Change struct {
+	// Path is the YAML path of the value, e.g. "server.port" or
+	// "servers[0].host". It is "" when the configs differ as a whole.
+	Path string
+	Kind ChangeKind
+	// Before and After are the old and new values: nil for an added or
+	// removed value, and "***" for secrets (Secret values and fields
+	// tagged `gonfig:"secret"`).
+	Before any
+	After  any
+}
+
+// Changes is the list of differences between two configs, in field order
+// with map keys sorted.
+type Changes []Change
+
+// Touches reports whether anything at path or below it changed, so
+// changes.Touches("server") is true when server.port changed.
+func (c Changes) Touches(path string) bool {
+	for _, ch := range c {
+		if isUnder(ch.Path, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// At returns the changes at path or below it.
+func (c Changes) At(path string) Changes {
+	var out Changes
+	for _, ch := range c {
+		if isUnder(ch.Path, path) {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// isUnder reports whether p is path or a path below it.
+func isUnder(p, path string) bool {
+	return path == "" || p == path || strings.HasPrefix(p, path+".") || strings.HasPrefix(p, path+"[")
+}
+
+// Diff compares two configs field by field and returns what changed from a
+// to b, by YAML path. Secret values and fields tagged `gonfig:"secret"` are
+// reported with their values masked, so the result is safe to log:
+//
+//	for _, c := range gonfig.Diff(oldCfg, newCfg) {
+//	    log.Printf("%s %s: %v -> %v", c.Path, c.Kind, c.Before, c.After)
+//	}
+//
+// a and b are normally of the same type; if they aren't, the result is a
+// single modification at path "". Pointers are followed, and nil maps and
+// slices equal empty ones.
+func Diff(a, b any) Changes {
+	return diff(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// diff compares two configs of the same type field by field.
+func diff(a, b reflect.Value) Changes {
+	var out Changes
+	diffValues("", a, b, false, &out)
+	return out
+}
+
+// diffValues appends the differences between a and b at path to out.
+// secret masks the values of every change at or below path.
+func diffValues(path string, a, b reflect.Value, secret bool, out *Changes) {
+	for a.IsValid() && (a.Kind() == reflect.Pointer || a.Kind() == reflect.Interface) && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.IsValid() && (b.Kind() == reflect.Pointer || b.Kind() == reflect.Interface) && !b.IsNil() {
+		b = b.Elem()
+	}
+	aNil, bNil := isNilValue(a), isNilValue(b)
+	switch {
+	case aNil && bNil:
+		return
+	case aNil:
+		*out = append(*out, Change{Path: path, Kind: Added, After: changeValue(b, secret)})
+		return
+	case bNil:
+		*out = append(*out, Change{Path: path, Kind: Removed, Before: changeValue(a, secret)})
+		return
+	case a.Type() != b.Type():
+		*out = append(*out, Change{Path: path, Kind: Modified, Before: changeValue(a, secret), After: changeValue(b, secret)})
+		return
+	}
+	secret = secret || a.Type() == secretType
+
+	switch a.Kind() {
+	case reflect.Struct:
+		if isLeafType(a.Type()) {
+			break
+		}
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			key, inline, skip := yamlFieldName(f)
+			if skip {
+				continue
+			}
+			fieldPath := path
+			if !inline {
+				fieldPath = joinPath(path, key)
+			}
+			diffValues(fieldPath, a.Field(i), b.Field(i), secret || hasTagOption(f, "secret"), out)
+		}
+		return
+	case reflect.Map:
+		if a.Type().Key().Kind() != reflect.String {
+			break
+		}
+		seen := make(map[string]bool)
+		var keys []reflect.Value
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			if !seen[k.String()] {
+				seen[k.String()] = true
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			diffValues(joinPath(path, k.String()), a.MapIndex(k), b.MapIndex(k), secret, out)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		if a.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			var ai, bi reflect.Value
+			if i < a.Len() {
+				ai = a.Index(i)
+			}
+			if i < b.Len() {
+				bi = b.Index(i)
+			}
+			diffValues(path+"["+strconv.Itoa(i)+"]", ai, bi, secret, out)
+		}
+		return
+	}
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		*out = append(*out, Change{Path: path, Kind: Modified, Before: changeValue(a, secret), After: changeValue(b, secret)})
+	}
+}
+
+// isNilValue reports whether v is missing or a nil pointer or interface.
+// Nil maps and slices are compared like empty ones.
+func isNilValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// changeValue returns v for a Change, masked if it is secret.
+func changeValue(v reflect.Value, secret bool) any {
+	if secret {
+		return redacted
+	}
+	return v.Interface()
+}
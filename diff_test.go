@@ -0,0 +1,62 @@
+package gonfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type tls struct {
+		Cert string `yaml:"cert"`
+	}
+	type server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+		TLS  *tls   `yaml:"tls"`
+	}
+	type config struct {
+		Server  server            `yaml:"server"`
+		Hosts   []string          `yaml:"hosts"`
+		Labels  map[string]string `yaml:"labels"`
+		Token   string            `yaml:"token" gonfig:"secret"`
+		Secret  Secret            `yaml:"secret"`
+		Ignored string            `yaml:"-"`
+	}
+	a := config{
+		Server: server{Host: "localhost", Port: 8080},
+		Hosts:  []string{"a", "b"},
+		Labels: map[string]string{"team": "core", "tier": "1"},
+		Token:  "old",
+		Secret: "same",
+	}
+	b := config{
+		Server:  server{Host: "localhost", Port: 9090, TLS: &tls{Cert: "c.pem"}},
+		Hosts:   []string{"a"},
+		Labels:  map[string]string{"team": "edge", "zone": "eu"},
+		Token:   "new",
+		Secret:  "same",
+		Ignored: "x",
+	}
+
+	want := Changes{
+		{Path: "server.port", Kind: Modified, Before: 8080, After: 9090},
+		{Path: "server.tls", Kind: Added, After: tls{Cert: "c.pem"}},
+		{Path: "hosts[1]", Kind: Removed, Before: "b"},
+		{Path: "labels.team", Kind: Modified, Before: "core", After: "edge"},
+		{Path: "labels.tier", Kind: Removed, Before: "1"},
+		{Path: "labels.zone", Kind: Added, After: "eu"},
+		{Path: "token", Kind: Modified, Before: "***", After: "***"},
+	}
+	if got := Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+	if got := Diff(&a, &a); len(got) != 0 {
+		t.Fatalf("expected no changes, got %+v", got)
+	}
+	if got := Diff(config{}, config{Hosts: []string{}, Labels: map[string]string{}}); len(got) != 0 {
+		t.Fatalf("nil and empty should be equal, got %+v", got)
+	}
+	if got := Diff(1, "1"); len(got) != 1 || got[0].Path != "" || got[0].Kind != Modified {
+		t.Fatalf("expected a single change for mismatched types, got %+v", got)
+	}
+}
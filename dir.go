@@ -0,0 +1,80 @@
+// dir.go
+package gonfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/TypeTerrors/gonfig/internal/fswatch"
+)
+
+// dirSource is a conf.d-style directory of YAML fragments. The loader reads
+// every fragment as its own layer (see loader.layers), so errors, warnings
+// and origins name the fragment; Fetch returns the merged document (with
+// the default MergeDeep strategy) for callers that use the Source directly.
+type dirSource string
+
+// files returns the *.yaml and *.yml files in the directory (optionally
+// age-encrypted) in lexical order. Hidden files are skipped.
+func (d dirSource) files() ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !isConfigFragment(e.Name()) {
+			continue
+		}
+		files = append(files, filepath.Join(string(d), e.Name()))
+	}
+	return files, nil
+}
+
+func (d dirSource) Fetch(context.Context) ([]byte, error) {
+	files, err := d.files()
+	if err != nil {
+		return nil, err
+	}
+	merged := &yaml.Node{}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		docs, err := parseDocuments(data)
+		if err != nil {
+			return nil, &ParseError{File: f, Err: err}
+		}
+		for _, doc := range docs {
+			merged = merger(nil).merge(merged, doc, nil)
+		}
+	}
+	if merged.Kind == 0 {
+		return nil, nil
+	}
+	return yaml.Marshal(merged)
+}
+
+// Watch reports fragments being added, changed or removed.
+func (d dirSource) Watch(ctx context.Context, changed func()) error {
+	return fswatch.Dirs(ctx, []string{string(d)}, func(name string) bool {
+		return isConfigFragment(filepath.Base(name)) || isDataSwap(name)
+	}, changed)
+}
+
+func (d dirSource) String() string { return string(d) }
+
+// isConfigFragment reports whether a file name in a config dir is loaded.
+func isConfigFragment(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	name, _ = cutEncryptedSuffix(name)
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
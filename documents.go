@@ -0,0 +1,101 @@
+// documents.go
+package gonfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// docSelector picks one document of a multi-document config file.
+type docSelector struct {
+	// index is the document's position, used when path is empty.
+	index int
+	// path and value select the first document whose value at path is
+	// value.
+	path  []string
+	value string
+}
+
+// WithDocument loads only document n (counting from 0) of a config file
+// that holds several YAML documents separated by "---". Without it, or
+// WithDocumentSelector, the documents are merged in order, later ones
+// winning, like config layers.
+//
+// The selection applies to every config layer (profile files and config
+// dir fragments included); a layer with fewer documents fails the load.
+func WithDocument(n int) Option {
+	return func(l *loader) {
+		l.document = &docSelector{index: n}
+	}
+}
+
+// WithDocumentSelector loads only the first document of a config file whose
+// value at path (dot-separated, like WithSection) equals value. Use it for
+// generated files that bundle configs for several components:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("bundle.yaml"),
+//	    gonfig.WithDocumentSelector("metadata.name", "api"),
+//	)
+//
+// A layer without a matching document fails the load.
+func WithDocumentSelector(path, value string) Option {
+	return func(l *loader) {
+		l.document = &docSelector{path: strings.Split(path, "."), value: value}
+	}
+}
+
+// unmarshalLayer parses the YAML documents in raw and returns the selected
+// one, or all of them merged with the WithMergeStrategy rules.
+func (l *loader) unmarshalLayer(raw []byte) (*yaml.Node, error) {
+	docs, err := parseDocuments(raw)
+	if err != nil {
+		return nil, err
+	}
+	if l.document != nil {
+		return l.document.pick(docs)
+	}
+	doc := &yaml.Node{}
+	for _, d := range docs {
+		doc = merger(l.mergeRules).merge(doc, d, nil)
+	}
+	return doc, nil
+}
+
+// parseDocuments parses every YAML document in raw.
+func parseDocuments(raw []byte) ([]*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+}
+
+// pick returns the selected document of docs.
+func (s *docSelector) pick(docs []*yaml.Node) (*yaml.Node, error) {
+	if s.path == nil {
+		if s.index < 0 || s.index >= len(docs) {
+			return nil, fmt.Errorf("document %d not found: the file has %d", s.index, len(docs))
+		}
+		return docs[s.index], nil
+	}
+	for _, d := range docs {
+		if n := nodeAt(d, s.path); n != nil && n.Kind == yaml.ScalarNode && n.Value == s.value {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("no document with %s: %s", strings.Join(s.path, "."), s.value)
+}
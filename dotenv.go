@@ -3,22 +3,291 @@ package gonfig
 
 import (
     "os"
-
-    "github.com/joho/godotenv"
+    "strings"
 )
 
-// loadDotenv loads a .env file into the process environment.
-// Returns os.ErrNotExist if the file is missing.
-func loadDotenv(path string) error {
-    // godotenv returns *os.PathError for missing file
-    err := godotenv.Overload(path)
+// loadDotenv loads a .env file and passes every variable to setenv, which
+// sets it (os.Setenv, or the loader's own map with WithEnvLookup) unless a
+// WithDotenvNoOverride file would clobber the real environment. decrypt is
+// applied to the raw file first (see loader.decrypt), and lookup resolves
+// $VAR references to variables not set earlier in the file.
+// Returns os.ErrNotExist if the file is missing, and a *DotenvError if it
+// can't be parsed.
+func loadDotenv(path string, decrypt func(path string, data []byte) ([]byte, error), lookup func(string) (string, bool), setenv func(key, value string) error) error {
+    // os.ReadFile returns *os.PathError for a missing file, which we
+    // surface as-is so the caller can check os.IsNotExist.
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return err
+    }
+    if data, err = decrypt(path, data); err != nil {
+        return err
+    }
+
+    vars, err := parseDotenv(path, string(data), lookup)
     if err != nil {
-        // If it's a path error, make sure we surface it as-is so
-        // caller can check os.IsNotExist.
-        if os.IsNotExist(err) {
+        return err
+    }
+    for _, v := range vars {
+        if err := setenv(v.key, v.value); err != nil {
             return err
         }
-        return err
     }
     return nil
-}
\ No newline at end of file
+}
+
+// dotenvVar is a single KEY=value from a .env file.
+type dotenvVar struct {
+    key, value string
+}
+
+// parseDotenv parses a .env file:
+//
+//	# comment
+//	export KEY=value            # trailing comment
+//	PLAIN = value with spaces
+//	SINGLE='literal $HOME, no escapes'
+//	DOUBLE="first line
+//	second line\twith escapes and ${EXPANSION}"
+//
+// Unquoted and double-quoted values expand $VAR and ${VAR} from earlier
+// lines, then lookup; "\$" is a literal dollar. Quoted values may span
+// lines. CRLF line endings are accepted. Variables are returned in file
+// order.
+func parseDotenv(file, src string, lookup func(string) (string, bool)) ([]dotenvVar, error) {
+    p := &dotenvParser{file: file, src: strings.ReplaceAll(src, "\r\n", "\n"), line: 1, lookup: lookup, vars: make(map[string]string)}
+    var out []dotenvVar
+    for {
+        p.skipBlank()
+        if p.pos >= len(p.src) {
+            return out, nil
+        }
+        key, err := p.key()
+        if err != nil {
+            return nil, err
+        }
+        value, err := p.value()
+        if err != nil {
+            return nil, err
+        }
+        p.vars[key] = value
+        out = append(out, dotenvVar{key: key, value: value})
+    }
+}
+
+// dotenvParser holds the state of a single parseDotenv call.
+type dotenvParser struct {
+    file   string
+    src    string
+    pos    int
+    line   int
+    lookup func(string) (string, bool)
+    // vars holds the variables parsed so far, for expansion.
+    vars map[string]string
+}
+
+func (p *dotenvParser) errorf(msg string) error {
+    return &DotenvError{File: p.file, Line: p.line, Message: msg}
+}
+
+// skipBlank skips whitespace, empty lines and comment lines.
+func (p *dotenvParser) skipBlank() {
+    for p.pos < len(p.src) {
+        switch c := p.src[p.pos]; {
+        case c == '\n':
+            p.line++
+            p.pos++
+        case c == ' ' || c == '\t':
+            p.pos++
+        case c == '#':
+            p.skipLine()
+        default:
+            return
+        }
+    }
+}
+
+// skipLine moves to the end of the current line.
+func (p *dotenvParser) skipLine() {
+    if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+        p.pos += i
+        return
+    }
+    p.pos = len(p.src)
+}
+
+func (p *dotenvParser) skipSpaces() {
+    for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+        p.pos++
+    }
+}
+
+// key parses an optional "export", the variable name and the "=" (or ":")
+// after it.
+func (p *dotenvParser) key() (string, error) {
+    if rest := p.src[p.pos:]; strings.HasPrefix(rest, "export ") || strings.HasPrefix(rest, "export\t") {
+        p.pos += len("export")
+        p.skipSpaces()
+    }
+    start := p.pos
+    for p.pos < len(p.src) && isDotenvKeyChar(p.src[p.pos], p.pos == start) {
+        p.pos++
+    }
+    key := p.src[start:p.pos]
+    if key == "" {
+        return "", p.errorf("invalid variable name")
+    }
+    p.skipSpaces()
+    if p.pos >= len(p.src) || (p.src[p.pos] != '=' && p.src[p.pos] != ':') {
+        return "", p.errorf("missing = after " + key)
+    }
+    p.pos++
+    p.skipSpaces()
+    return key, nil
+}
+
+// isDotenvKeyChar reports whether c may appear in a variable name.
+func isDotenvKeyChar(c byte, first bool) bool {
+    switch {
+    case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+        return true
+    case c >= '0' && c <= '9' || c == '.' || c == '-':
+        return !first
+    }
+    return false
+}
+
+// value parses the value after the "=", up to and including the end of its
+// line.
+func (p *dotenvParser) value() (string, error) {
+    if p.pos >= len(p.src) {
+        return "", nil
+    }
+    var value string
+    switch q := p.src[p.pos]; q {
+    case '\'', '"':
+        start := p.line
+        p.pos++
+        var b strings.Builder
+        // escaped holds the offsets in b of "$"s written as "\$"
+        escaped := make(map[int]bool)
+        for {
+            if p.pos >= len(p.src) {
+                p.line = start
+                return "", p.errorf("unterminated quoted value")
+            }
+            c := p.src[p.pos]
+            if c == q {
+                p.pos++
+                break
+            }
+            if c == '\n' {
+                p.line++
+            }
+            if c == '\\' && q == '"' && p.pos+1 < len(p.src) {
+                p.pos++
+                switch e := p.src[p.pos]; e {
+                case 'n':
+                    b.WriteByte('\n')
+                case 't':
+                    b.WriteByte('\t')
+                case 'r':
+                    b.WriteByte('\r')
+                case '$':
+                    escaped[b.Len()] = true
+                    b.WriteByte('$')
+                default:
+                    b.WriteByte(e)
+                }
+                p.pos++
+                continue
+            }
+            b.WriteByte(c)
+            p.pos++
+        }
+        value = b.String()
+        if q == '"' {
+            value = p.expand(value, escaped)
+        }
+        p.skipSpaces()
+        if p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '#' {
+            return "", p.errorf("unexpected text after quoted value")
+        }
+    default:
+        start := p.pos
+        p.skipLine()
+        raw := p.src[start:p.pos]
+        // A " #" starts a comment; a "#" inside a word doesn't.
+        for i := 0; i < len(raw); i++ {
+            if raw[i] == '#' && (i == 0 || raw[i-1] == ' ' || raw[i-1] == '\t') {
+                raw = raw[:i]
+                break
+            }
+        }
+        value = p.expand(unescapeDollars(strings.TrimSpace(raw)))
+    }
+    p.skipLine()
+    return value, nil
+}
+
+// unescapeDollars turns every "\$" in an unquoted value into "$", and
+// returns the offsets of those "$"s for expand.
+func unescapeDollars(s string) (string, map[int]bool) {
+    escaped := make(map[int]bool)
+    if !strings.Contains(s, `\$`) {
+        return s, escaped
+    }
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        if s[i] == '\\' && i+1 < len(s) && s[i+1] == '$' {
+            i++
+            escaped[b.Len()] = true
+        }
+        b.WriteByte(s[i])
+    }
+    return b.String(), escaped
+}
+
+// expand replaces $VAR and ${VAR} in s with earlier variables from the
+// file, or from lookup. Unset variables expand to "". The "$"s at the
+// offsets in escaped were written as "\$" and are literal.
+func (p *dotenvParser) expand(s string, escaped map[int]bool) string {
+    if !strings.Contains(s, "$") {
+        return s
+    }
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        if c != '$' || escaped[i] || i+1 >= len(s) {
+            b.WriteByte(c)
+            continue
+        }
+        var name string
+        if s[i+1] == '{' {
+            end := strings.IndexByte(s[i:], '}')
+            if end < 0 {
+                b.WriteByte(c)
+                continue
+            }
+            name = s[i+2 : i+end]
+            i += end
+        } else {
+            j := i + 1
+            for j < len(s) && isDotenvKeyChar(s[j], j == i+1) && s[j] != '.' && s[j] != '-' {
+                j++
+            }
+            if j == i+1 {
+                b.WriteByte(c)
+                continue
+            }
+            name = s[i+1 : j]
+            i = j - 1
+        }
+        if v, ok := p.vars[name]; ok {
+            b.WriteString(v)
+        } else if v, ok := p.lookup(name); ok {
+            b.WriteString(v)
+        }
+    }
+    return b.String()
+}
@@ -0,0 +1,81 @@
+package gonfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	src := "# comment\r\n" +
+		"export HOST=db.internal   # trailing comment\r\n" +
+		"PLAIN = value with spaces\n" +
+		"HASH=a#b\n" +
+		"EMPTY=\n" +
+		"SINGLE='literal $HOST \\n'\n" +
+		"DOUBLE=\"first line\n" +
+		"second\\tline ${HOST}:$PORT \\$5\" # comment\n" +
+		"URL=postgres://${HOST}/${MISSING}app\n" +
+		"WIN=\"C:\\\\$HOMEDIR \\\\\\$HOMEDIR\"\n" +
+		"RAW=cost\\$5 ${HOMEDIR}\n"
+	lookup := func(name string) (string, bool) {
+		env := map[string]string{"PORT": "5432", "HOMEDIR": "/home/x"}
+		v, ok := env[name]
+		return v, ok
+	}
+
+	vars, err := parseDotenv(".env", src, lookup)
+	if err != nil {
+		t.Fatalf("parseDotenv: %v", err)
+	}
+	want := []dotenvVar{
+		{"HOST", "db.internal"},
+		{"PLAIN", "value with spaces"},
+		{"HASH", "a#b"},
+		{"EMPTY", ""},
+		{"SINGLE", `literal $HOST \n`},
+		{"DOUBLE", "first line\nsecond\tline db.internal:5432 $5"},
+		{"URL", "postgres://db.internal/app"},
+		{"WIN", `C:\/home/x \$HOMEDIR`},
+		{"RAW", "cost$5 /home/x"},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Fatalf("got %q\nwant %q", vars, want)
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	lookup := func(string) (string, bool) { return "", false }
+	tests := []struct {
+		src  string
+		line int
+		msg  string
+	}{
+		{"A=1\n=2\n", 2, "invalid variable name"},
+		{"A=1\n\nB 2\n", 3, "missing = after B"},
+		{"A=1\nB=\"open\nstill open\n", 2, "unterminated quoted value"},
+		{"A='x' y\n", 1, "unexpected text after quoted value"},
+	}
+	for _, tt := range tests {
+		_, err := parseDotenv(".env", tt.src, lookup)
+		var de *DotenvError
+		if !errors.As(err, &de) || de.Line != tt.line || de.Message != tt.msg {
+			t.Fatalf("%q: got %v, want line %d: %s", tt.src, err, tt.line, tt.msg)
+		}
+	}
+}
+
+func TestLoad_DotenvParseError(t *testing.T) {
+	dotenv := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(dotenv, []byte("OK=1\nBROKEN\n"), 0o644); err != nil {
+		t.Fatalf("write dotenv: %v", err)
+	}
+	_, err := Load[testConfig](WithBytes([]byte("app_name: x\n")), WithDotenv(dotenv),
+		WithEnvLookup(func(string) (string, bool) { return "", false }))
+	var de *DotenvError
+	if !errors.As(err, &de) || err.Error() != "parse "+dotenv+":2: missing = after BROKEN" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+// dump.go
+package gonfig
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DumpOption configures Dump.
+type DumpOption func(*dumper)
+
+// Redact masks the values at the given YAML paths, e.g. "database.password".
+// A "*" segment matches any single key or list index, so "*.token" masks
+// github.token and slack.token, and "servers.*.password" masks the password
+// of every server. Segments also support path.Match patterns such as
+// "*_key".
+func Redact(patterns ...string) DumpOption {
+	return func(d *dumper) {
+		for _, p := range patterns {
+			d.patterns = append(d.patterns, strings.Split(p, "."))
+		}
+	}
+}
+
+// AsJSON makes Dump produce indented JSON instead of YAML.
+func AsJSON() DumpOption {
+	return func(d *dumper) {
+		d.json = true
+	}
+}
+
+type dumper struct {
+	patterns [][]string
+	json     bool
+}
+
+// Dump marshals a loaded config to YAML (or JSON with AsJSON) with secrets
+// masked as "***", so the effective config can be logged at startup.
+//
+// Masked are: Secret values, fields tagged `gonfig:"secret"`, and paths
+// matched by Redact.
+//
+// Example:
+//
+//	out, err := gonfig.Dump(cfg, gonfig.Redact("database.password", "*.token"))
+//	if err == nil {
+//	    log.Printf("effective config:\n%s", out)
+//	}
+func Dump(cfg any, opts ...DumpOption) ([]byte, error) {
+	d := &dumper{}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	doc, err := d.encode(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !d.json {
+		return yaml.Marshal(doc)
+	}
+	var v any
+	if err := doc.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// encode encodes cfg to a YAML tree with its secrets and the paths matched
+// by d's patterns masked.
+func (d *dumper) encode(cfg any) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := doc.Encode(cfg); err != nil {
+		return nil, err
+	}
+	flattenSquashed(&doc, reflect.ValueOf(cfg))
+	var tagged [][]string
+	secretPaths(reflect.ValueOf(cfg), nil, &tagged)
+	d.patterns = append(d.patterns, tagged...)
+	d.redact(&doc, nil)
+	return &doc, nil
+}
+
+// redact masks every value under n whose path matches a pattern.
+func (d *dumper) redact(n *yaml.Node, segs []string) {
+	if len(segs) > 0 && d.matches(segs) {
+		*n = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: redacted}
+		return
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			d.redact(c, segs)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			d.redact(n.Content[i+1], appendSeg(segs, n.Content[i].Value))
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			d.redact(c, appendSeg(segs, strconv.Itoa(i)))
+		}
+	}
+}
+
+func (d *dumper) matches(segs []string) bool {
+	for _, p := range d.patterns {
+		if matchSegs(p, segs) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegs reports whether the path segs matches pattern segment by
+// segment, with path.Match syntax in each pattern segment.
+func matchSegs(pattern, segs []string) bool {
+	if len(pattern) != len(segs) {
+		return false
+	}
+	for i, seg := range segs {
+		if m, err := path.Match(pattern[i], seg); pattern[i] != seg && (err != nil || !m) {
+			return false
+		}
+	}
+	return true
+}
+
+// secretPaths collects the paths of fields tagged `gonfig:"secret"`, with
+// list indexes and map keys as segments.
+func secretPaths(v reflect.Value, segs []string, out *[][]string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			secretPaths(v.Elem(), segs, out)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			secretPaths(v.Index(i), appendSeg(segs, strconv.Itoa(i)), out)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			secretPaths(iter.Value(), appendSeg(segs, iter.Key().String()), out)
+		}
+	case reflect.Struct:
+		if isLeafType(v.Type()) {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			key, inline, skip := yamlFieldName(f)
+			if skip {
+				continue
+			}
+			fieldSegs := segs
+			if !inline {
+				fieldSegs = appendSeg(segs, key)
+			}
+			if hasTagOption(f, "secret") {
+				*out = append(*out, fieldSegs)
+				continue
+			}
+			secretPaths(v.Field(i), fieldSegs, out)
+		}
+	}
+}
+
+// appendSeg returns segs with seg appended, without sharing its backing
+// array.
+func appendSeg(segs []string, seg string) []string {
+	return append(segs[:len(segs):len(segs)], seg)
+}
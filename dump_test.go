@@ -0,0 +1,58 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	type server struct {
+		Host     string `yaml:"host"`
+		Password string `yaml:"password"`
+	}
+	type config struct {
+		App      string            `yaml:"app"`
+		APIKey   string            `yaml:"api_key" gonfig:"secret"`
+		Database server            `yaml:"database"`
+		Servers  []server          `yaml:"servers"`
+		Tokens   map[string]string `yaml:"tokens"`
+		Session  Secret            `yaml:"session"`
+	}
+	cfg := config{
+		App:      "billing",
+		APIKey:   "key-1",
+		Database: server{Host: "db", Password: "db-pass"},
+		Servers:  []server{{Host: "a", Password: "a-pass"}, {Host: "b", Password: "b-pass"}},
+		Tokens:   map[string]string{"github": "gh-token", "slack": "sl-token"},
+		Session:  "sess-id",
+	}
+
+	out, err := Dump(cfg, Redact("database.password", "servers.*.password", "tokens.*"))
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	s := string(out)
+	for _, leaked := range []string{"key-1", "db-pass", "a-pass", "b-pass", "gh-token", "sl-token", "sess-id"} {
+		if strings.Contains(s, leaked) {
+			t.Fatalf("%q leaked in dump:\n%s", leaked, s)
+		}
+	}
+	for _, kept := range []string{"app: billing", "host: db", "host: b", "api_key: '***'"} {
+		if !strings.Contains(s, kept) {
+			t.Fatalf("expected %q in dump:\n%s", kept, s)
+		}
+	}
+
+	out, err = Dump(&cfg, AsJSON())
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(out, &m); err != nil {
+		t.Fatalf("invalid JSON %s: %v", out, err)
+	}
+	if m["api_key"] != "***" || m["session"] != "***" || m["app"] != "billing" {
+		t.Fatalf("unexpected JSON dump: %s", out)
+	}
+}
@@ -0,0 +1,155 @@
+// enum.go
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// EnumError is wrapped by the FieldError for a field whose value isn't one
+// of its allowed values, from an `enum:"..."` tag or RegisterEnum:
+//
+//	config validation failed: log_level (config.yaml:2:12): "verbose" is not allowed (allowed values: debug, info, warn, error)
+type EnumError struct {
+	// Value is the rejected value, formatted with fmt.Sprint.
+	Value string
+	// Allowed lists the allowed values in order.
+	Allowed []string
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("%q is not allowed (allowed values: %s)", e.Value, strings.Join(e.Allowed, ", "))
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = map[reflect.Type][]any{}
+)
+
+// RegisterEnum restricts fields of type T (or *T, []T) to values. Use it
+// for Go enum types, typically with the list a generator such as enumer
+// produces, so the enum tag doesn't have to repeat it on every field:
+//
+//	type Level string
+//
+//	func init() {
+//	    gonfig.RegisterEnum(LevelValues()...)
+//	}
+//
+// Registering T again replaces its values.
+func RegisterEnum[T comparable](values ...T) {
+	allowed := make([]any, len(values))
+	for i, v := range values {
+		allowed[i] = v
+	}
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+	enums[reflect.TypeFor[T]()] = allowed
+}
+
+// registeredEnum returns the values registered for t with RegisterEnum.
+func registeredEnum(t reflect.Type) ([]any, bool) {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+	values, ok := enums[t]
+	return values, ok
+}
+
+// checkEnums returns a FieldError wrapping an *EnumError for every field
+// whose value isn't allowed by its `enum:"..."` tag or by the values
+// registered for its type. Zero values are checked like any other, so an
+// optional field needs a pointer type or its zero value in the set; nil
+// pointers are skipped and elements of slices and arrays are checked one
+// by one.
+func checkEnums(v reflect.Value, path string) []error {
+	return checkFields(v, path, func(f reflect.StructField, fv reflect.Value, path string) error {
+		_, tagged := f.Tag.Lookup("enum")
+		var allowed []string
+		if tagged {
+			allowed, _ = fieldEnum(f)
+		}
+		return checkEnum(fv, path, allowed, tagged)
+	})
+}
+
+// checkEnum checks v, a field value or one of its elements, against the
+// tag's allowed values or, without a tag, against RegisterEnum.
+func checkEnum(v reflect.Value, path string, allowed []string, tagged bool) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		if !tagged && !isEnumElem(v.Type().Elem()) {
+			return nil
+		}
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			if err := checkEnum(v.Index(i), fmt.Sprintf("%s[%d]", path, i), allowed, tagged); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	value := fmt.Sprint(v.Interface())
+	if tagged {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return &FieldError{Path: path, Err: &EnumError{Value: value, Allowed: allowed}}
+	}
+	values, ok := registeredEnum(v.Type())
+	if !ok {
+		return nil
+	}
+	names := make([]string, len(values))
+	for i, a := range values {
+		if v.Interface() == a {
+			return nil
+		}
+		names[i] = fmt.Sprint(a)
+	}
+	return &FieldError{Path: path, Err: &EnumError{Value: value, Allowed: names}}
+}
+
+// isEnumElem reports whether t, the element type of a slice or array, or a
+// pointer to it, was registered with RegisterEnum.
+func isEnumElem(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	_, ok := registeredEnum(t)
+	return ok
+}
+
+// fieldEnum returns the allowed values of f, from its enum tag or
+// RegisterEnum, formatted like in an EnumError.
+func fieldEnum(f reflect.StructField) ([]string, bool) {
+	if tag, ok := f.Tag.Lookup("enum"); ok {
+		var allowed []string
+		for _, a := range strings.Split(tag, ",") {
+			allowed = append(allowed, strings.TrimSpace(a))
+		}
+		return allowed, true
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	values, ok := registeredEnum(t)
+	if !ok {
+		return nil, false
+	}
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = fmt.Sprint(v)
+	}
+	return names, true
+}
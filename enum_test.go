@@ -0,0 +1,56 @@
+// enum_test.go
+package gonfig
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testRegion string
+
+func TestLoad_EnumTags(t *testing.T) {
+	RegisterEnum[testRegion]("eu", "us")
+	type config struct {
+		LogLevel string       `yaml:"log_level" enum:"debug, info, warn, error"`
+		Codes    []int        `yaml:"codes" enum:"200,204"`
+		Region   testRegion   `yaml:"region"`
+		Replicas []testRegion `yaml:"replicas"`
+		Fallback *testRegion  `yaml:"fallback"`
+		Optional *string      `yaml:"optional" enum:"a,b"`
+		Port     int          `yaml:"port" enum:"80,443"`
+	}
+
+	cfg, err := Load[config](WithBytes([]byte("log_level: warn\ncodes: [200, 204]\nregion: eu\nreplicas: [us]\nfallback: us\nport: 443\n")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.LogLevel != "warn" || cfg.Region != "eu" || *cfg.Fallback != "us" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	_, err = Load[config](WithBytes([]byte("log_level: verbose\ncodes: [200, 500]\nregion: ap\nreplicas: [us, ap]\n")))
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	var ee *EnumError
+	if !errors.As(err, &ee) || !reflect.DeepEqual(ee.Allowed, []string{"debug", "info", "warn", "error"}) {
+		t.Fatalf("expected an *EnumError for log_level, got %v", err)
+	}
+	for _, want := range []string{
+		`log_level (<bytes>:1:12): "verbose" is not allowed (allowed values: debug, info, warn, error)`,
+		`codes[1] (<bytes>:2:14): "500" is not allowed (allowed values: 200, 204)`,
+		`region (<bytes>:3:9): "ap" is not allowed (allowed values: eu, us)`,
+		`replicas[1] (<bytes>:4:16): "ap" is not allowed`,
+		`port: "0" is not allowed (allowed values: 80, 443)`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "optional") || strings.Contains(err.Error(), "fallback") {
+		t.Fatalf("unset fields must not be checked: %v", err)
+	}
+}
@@ -2,57 +2,567 @@
 package gonfig
 
 import (
-    "fmt"
+    "context"
+    "errors"
     "os"
-    "regexp"
+    "strconv"
     "strings"
+    "unicode/utf8"
+
+    "gopkg.in/yaml.v3"
 )
 
-var rePlaceholder = regexp.MustCompile(`\$\{([^}]+)\}`)
+// lookupFunc resolves a placeholder name to its value. ok=false means the
+// name is unset, so defaults and strict mode apply; a non-nil error aborts
+// expansion.
+type lookupFunc func(name string) (val string, ok bool, err error)
 
-// expandEnv replaces ${VAR} or ${VAR:-default} with env values.
-// strict=true: missing env without default -> error.
-func expandEnv(s string, strict bool) (string, error) {
-    var missing []string
+// envLookup resolves names from the process environment.
+func envLookup(name string) (string, bool, error) {
+    val, ok := os.LookupEnv(name)
+    return val, ok, nil
+}
 
-    out := rePlaceholder.ReplaceAllStringFunc(s, func(m string) string {
-        matches := rePlaceholder.FindStringSubmatch(m)
-        if len(matches) != 2 {
-            // shouldn't happen, but be defensive
-            return m
-        }
-        inner := matches[1]
+// expandMode holds the settings that shape expansion, from the loader's
+// options or Expand's.
+type expandMode struct {
+    strict   bool            // missing vars are errors (WithStrict)
+    bare     bool            // also expand $VAR (WithBareVariables)
+    keep     bool            // leave missing vars as-is (MissingKeep)
+    optional map[string]bool // may stay unset (WithOptionalVars)
+}
 
-        name := inner
-        var def *string
+// setMissingPolicy applies a WithMissingPolicy setting.
+func (m *expandMode) setMissingPolicy(p MissingPolicy) {
+    m.strict = p == MissingError
+    m.keep = p == MissingKeep
+}
 
-        // Support syntax: VAR:-default
-        if idx := strings.Index(inner, ":-"); idx != -1 {
-            n := inner[:idx]
-            d := inner[idx+2:]
-            name = n
-            def = &d
-        }
+// ExpandOption configures Expand.
+type ExpandOption func(*expandOptions)
+
+type expandOptions struct {
+    mode      expandMode
+    lookup    func(name string) (string, bool)
+    resolvers map[string]ResolverFunc
+    ctx       context.Context
+}
+
+// ExpandStrict makes Expand fail on ${VAR} placeholders that have neither a
+// value nor a default, like WithStrict does for Load.
+func ExpandStrict() ExpandOption {
+    return func(o *expandOptions) {
+        o.mode.strict = true
+    }
+}
 
-        if val, ok := os.LookupEnv(name); ok {
-            return val
+// ExpandBareVariables makes Expand also expand bare $VAR placeholders, like
+// WithBareVariables does for Load.
+func ExpandBareVariables() ExpandOption {
+    return func(o *expandOptions) {
+        o.mode.bare = true
+    }
+}
+
+// ExpandOptional marks variables as optional, like WithOptionalVars does for
+// Load: when unset they expand to "" even in strict mode.
+func ExpandOptional(names ...string) ExpandOption {
+    return func(o *expandOptions) {
+        for _, name := range names {
+            o.mode.optional[name] = true
         }
+    }
+}
 
-        if def != nil {
-            return *def
+// ExpandMissingPolicy sets what Expand does with unset variables that have
+// no default, like WithMissingPolicy does for Load.
+func ExpandMissingPolicy(p MissingPolicy) ExpandOption {
+    return func(o *expandOptions) {
+        o.mode.setMissingPolicy(p)
+    }
+}
+
+// ExpandLookup makes Expand read variables from fn instead of the process
+// environment.
+func ExpandLookup(fn func(name string) (string, bool)) ExpandOption {
+    return func(o *expandOptions) {
+        o.lookup = fn
+    }
+}
+
+// ExpandResolver resolves ${scheme:key} placeholders with fn, like
+// WithResolver does for Load. ${file:/path} is built in.
+func ExpandResolver(scheme string, fn ResolverFunc) ExpandOption {
+    return func(o *expandOptions) {
+        o.resolvers[scheme] = fn
+    }
+}
+
+// ExpandContext passes ctx to resolvers. The default is
+// context.Background().
+func ExpandContext(ctx context.Context) ExpandOption {
+    return func(o *expandOptions) {
+        o.ctx = ctx
+    }
+}
+
+// Expand replaces placeholders in s with exactly the semantics Load applies
+// to config values, so other files can share the syntax:
+//
+//   - ${VAR}             -> the value of VAR, or "" if it is unset
+//   - ${?VAR}            -> like ${VAR}, but never an error when unset
+//   - ${VAR:-default}    -> VAR if set, otherwise default
+//   - ${VAR:?message}    -> VAR if set, otherwise an error with message
+//   - ${VAR:+alternate}  -> alternate if VAR is set, otherwise ""
+//   - ${scheme:key}      -> a resolver (see ExpandResolver)
+//   - $${VAR}            -> a literal ${VAR}
+//
+// Defaults may themselves contain placeholders. Unresolved placeholders
+// fail with a *MissingEnvError in strict mode (and for ${VAR:?message}
+// always), and failing resolvers with a *ResolveError; positions in both
+// are lines and columns within s.
+//
+// Example:
+//
+//	out, err := gonfig.Expand(string(tmpl), gonfig.ExpandStrict())
+func Expand(s string, opts ...ExpandOption) (string, error) {
+    o := &expandOptions{
+        mode:      expandMode{optional: make(map[string]bool)},
+        resolvers: map[string]ResolverFunc{"file": resolveFile},
+        ctx:       context.Background(),
+    }
+    for _, opt := range opts {
+        opt(o)
+    }
+    lookup := func(name string) (string, bool, error) {
+        if i := strings.IndexByte(name, ':'); i > 0 {
+            if fn, ok := o.resolvers[name[:i]]; ok {
+                val, err := fn(o.ctx, name[i+1:])
+                if errors.Is(err, ErrNotFound) {
+                    return "", false, nil
+                }
+                return val, err == nil, err
+            }
+        }
+        if o.lookup != nil {
+            val, ok := o.lookup(name)
+            return val, ok, nil
         }
+        return envLookup(name)
+    }
 
-        if strict {
-            missing = append(missing, name)
+    // Positions are computed as if s were a scalar at line 1, column 1.
+    n := &yaml.Node{Value: s, Line: 1, Column: 1}
+    out, miss, err := expandString(s, lookup, o.mode)
+    if err != nil {
+        return "", err.(*lookupError).locate(n, "")
+    }
+    var missing []MissingVar
+    for _, m := range miss {
+        if (o.mode.strict || m.required) && !m.isOptional(o.mode.optional) {
+            missing = append(missing, m.locate(n, ""))
         }
+    }
+    if len(missing) > 0 {
+        return "", &MissingEnvError{Vars: missing}
+    }
+    return out, nil
+}
 
-        // non-strict: replace with empty string
-        return ""
+// expandNode replaces ${VAR} or ${VAR:-default} with values from lookup in
+// every plain or quoted scalar of the YAML tree rooted at n.
+//
+// Expansion works on parsed values rather than raw text, so placeholders in
+// comments are never touched, and literal (|) and folded (>) block scalars
+// are left verbatim: they usually hold shell scripts or templates whose
+// ${...} is meant for another tool.
+//
+// mode.bare also expands $VAR (WithBareVariables).
+// Unset ${?VAR}s and vars in mode.optional (WithOptionalVars) expand to ""
+// and are neither errors nor returned in unset.
+// mode.strict: missing env without default -> *MissingEnvError.
+// Otherwise missing env without default is expanded to "" (or left as-is
+// with mode.keep) and returned in unset so the caller can warn about it.
+// A failing lookup -> *ResolveError.
+func expandNode(n *yaml.Node, mode expandMode, lookup lookupFunc) (unset []MissingVar, err error) {
+    var (
+        missing []MissingVar
+        failed  error
+    )
+    walkScalars(n, func(s *yaml.Node, path func() string) {
+        if failed != nil || s.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+            return
+        }
+        out, miss, err := expandString(s.Value, lookup, mode)
+        if err != nil {
+            failed = err.(*lookupError).locate(s, path())
+            return
+        }
+        for _, m := range miss {
+            if m.isOptional(mode.optional) {
+                continue
+            }
+            if mode.strict || m.required {
+                missing = append(missing, m.locate(s, path()))
+            } else {
+                unset = append(unset, m.locate(s, path()))
+            }
+        }
+        if out == s.Value {
+            return
+        }
+        s.Value = out
+        // Plain scalars were resolved (e.g. to !!str) before expansion;
+        // clear the tag so "${PORT}" -> "8080" decodes as an int again.
+        // Quoted and explicitly tagged scalars keep their tag.
+        if s.Style&(yaml.TaggedStyle|yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) == 0 {
+            s.Tag = ""
+        }
     })
 
+    if failed != nil {
+        return nil, failed
+    }
     if len(missing) > 0 {
-        return "", fmt.Errorf("missing required env vars: %s", strings.Join(missing, ", "))
+        return nil, &MissingEnvError{Vars: missing}
     }
+    return unset, nil
+}
 
-    return out, nil
-}
\ No newline at end of file
+// walkScalars calls fn for every scalar node under n along with a function
+// returning its dotted YAML path. Paths are only built when asked for, so
+// walking a large document allocates next to nothing. Alias nodes are
+// skipped: their anchor is visited where it is defined, so each value is
+// expanded exactly once.
+func walkScalars(n *yaml.Node, fn func(n *yaml.Node, path func() string)) {
+    w := &scalarWalker{fn: fn}
+    w.pathFn = w.path
+    w.walk(n)
+}
+
+// scalarWalker holds the state of a walkScalars call.
+type scalarWalker struct {
+    fn     func(n *yaml.Node, path func() string)
+    pathFn func() string
+    // segs are the mapping keys and list indexes (index >= 0) leading to
+    // the current node.
+    segs []pathSeg
+}
+
+type pathSeg struct {
+    key   string
+    index int
+}
+
+func (w *scalarWalker) walk(n *yaml.Node) {
+    switch n.Kind {
+    case yaml.DocumentNode:
+        for _, c := range n.Content {
+            w.walk(c)
+        }
+    case yaml.MappingNode:
+        for i := 0; i+1 < len(n.Content); i += 2 {
+            k, v := n.Content[i], n.Content[i+1]
+            w.fn(k, w.pathFn)
+            w.segs = append(w.segs, pathSeg{key: k.Value, index: -1})
+            w.walk(v)
+            w.segs = w.segs[:len(w.segs)-1]
+        }
+    case yaml.SequenceNode:
+        for i, c := range n.Content {
+            w.segs = append(w.segs, pathSeg{index: i})
+            w.walk(c)
+            w.segs = w.segs[:len(w.segs)-1]
+        }
+    case yaml.ScalarNode:
+        w.fn(n, w.pathFn)
+    }
+}
+
+// path returns the dotted YAML path of the current node, e.g.
+// "servers[0].host".
+func (w *scalarWalker) path() string {
+    var path string
+    for _, seg := range w.segs {
+        if seg.index >= 0 {
+            path += "[" + strconv.Itoa(seg.index) + "]"
+        } else {
+            path = joinPath(path, seg.key)
+        }
+    }
+    return path
+}
+
+// missingRef is an unresolved placeholder at a byte offset of a scalar value.
+// required is set for ${VAR:?message}, which fails even outside strict mode,
+// and optional for ${?VAR}, which never fails.
+type missingRef struct {
+    name     string
+    offset   int
+    required bool
+    optional bool
+    message  string
+}
+
+// isOptional reports whether the ref may stay unset: it is a ${?VAR} or
+// names one of the optional vars.
+func (m missingRef) isOptional(optional map[string]bool) bool {
+    return !m.required && (m.optional || optional[m.name])
+}
+
+// locate converts the ref into a MissingVar positioned in the config file.
+func (m missingRef) locate(n *yaml.Node, path string) MissingVar {
+    line, col := position(n, m.offset)
+    return MissingVar{
+        Name:    m.name,
+        Path:    path,
+        Line:    line,
+        Column:  col,
+        Message: m.message,
+    }
+}
+
+// lookupError is a failed lookup at a byte offset of a scalar value.
+type lookupError struct {
+    name   string
+    offset int
+    err    error
+}
+
+func (e *lookupError) Error() string { return e.name + ": " + e.err.Error() }
+
+// locate converts the failure into a *ResolveError positioned in the config
+// file.
+func (e *lookupError) locate(n *yaml.Node, path string) *ResolveError {
+    line, col := position(n, e.offset)
+    return &ResolveError{
+        Name:   e.name,
+        Path:   path,
+        Line:   line,
+        Column: col,
+        Err:    e.err,
+    }
+}
+
+// position converts a byte offset inside the value of scalar n into a line
+// and column in the config file.
+func position(n *yaml.Node, offset int) (line, col int) {
+    prefix := n.Value[:offset]
+    line, col = n.Line, n.Column
+    if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+        col++ // opening quote
+    }
+    if i := strings.LastIndexByte(prefix, '\n'); i != -1 {
+        // Multi-line flow scalar: the exact column depends on indentation
+        // we no longer have, so report the line only approximately.
+        line += strings.Count(prefix, "\n")
+        col = 1
+        prefix = prefix[i+1:]
+    }
+    return line, col + utf8.RuneCountInString(prefix)
+}
+
+// expandString replaces ${VAR}, ${VAR:-default}, ${VAR:?message} and
+// ${VAR:+alternate} in s with values from lookup and returns the
+// placeholders that could not be resolved. Missing values become "" (with
+// mode.keep, placeholders without a default are left as-is), and $${VAR}
+// is unescaped to a literal ${VAR}. With mode.bare, $VAR is expanded like
+// ${VAR}, and $$VAR is unescaped to a literal $VAR.
+//
+// Defaults and messages may themselves contain placeholders, e.g.
+// ${CACHE_HOST:-${REDIS_HOST:-localhost}}; they are only expanded when used.
+func expandString(s string, lookup lookupFunc, mode expandMode) (string, []missingRef, error) {
+    x := &expander{lookup: lookup, mode: mode}
+    out := x.expandAt(s, 0)
+    if x.err != nil {
+        return "", nil, x.err
+    }
+    return out, x.missing, nil
+}
+
+// expander holds the state of a single expandString call.
+type expander struct {
+    lookup  lookupFunc
+    mode    expandMode
+    missing []missingRef
+    err     *lookupError
+}
+
+// expandAt expands s, which starts at byte offset base of the original
+// scalar value, recording unresolved placeholders and the first lookup error.
+func (x *expander) expandAt(s string, base int) string {
+    if x.err != nil || !strings.Contains(s, "${") && !(x.mode.bare && strings.Contains(s, "$")) {
+        return s
+    }
+
+    var b strings.Builder
+    i := 0
+    for {
+        j := x.nextPlaceholder(s[i:])
+        if j == -1 {
+            b.WriteString(s[i:])
+            break
+        }
+        start := i + j
+
+        if s[start+1] != '{' {
+            // Bare $VAR
+            end := start + 1
+            for end < len(s) && isNameChar(s[end], end == start+1) {
+                end++
+            }
+            if start > i && s[start-1] == '$' {
+                // $$VAR -> literal $VAR
+                b.WriteString(s[i : start-1])
+                b.WriteString(s[start:end])
+                i = end
+                continue
+            }
+            b.WriteString(s[i:start])
+            i = end
+            name := s[start+1 : end]
+            val, ok, err := x.lookup(name)
+            if err != nil {
+                x.err = &lookupError{name: name, offset: base + start, err: err}
+                return ""
+            }
+            if !ok {
+                ref := missingRef{name: name, offset: base + start}
+                x.missing = append(x.missing, ref)
+                if x.keeps(ref) {
+                    val = s[start:end]
+                }
+            }
+            b.WriteString(val)
+            continue
+        }
+
+        end := matchBrace(s, start+2)
+        if end == -1 {
+            // Unterminated: keep the rest as-is.
+            b.WriteString(s[i:])
+            break
+        }
+
+        // $${VAR} -> literal ${VAR}
+        if start > i && s[start-1] == '$' {
+            b.WriteString(s[i : start-1])
+            b.WriteString(s[start : end+1])
+            i = end + 1
+            continue
+        }
+
+        b.WriteString(s[i:start])
+        i = end + 1
+
+        inner := s[start+2 : end]
+        if inner == "" {
+            b.WriteString("${}")
+            continue
+        }
+
+        // ${?VAR}: optional even in strict mode
+        optional := inner[0] == '?' && len(inner) > 1
+        if optional {
+            inner = inner[1:]
+        }
+        name, op, arg := splitPlaceholder(inner)
+        argBase := base + start + 2 + len(name) + len(op)
+        if optional {
+            argBase++
+        }
+
+        val, ok, err := x.lookup(name)
+        if err != nil {
+            x.err = &lookupError{name: name, offset: base + start, err: err}
+            return ""
+        }
+        if op == ":+" {
+            // ${VAR:+alternate}: alternate if VAR is set, nothing otherwise.
+            if ok {
+                b.WriteString(x.expandAt(arg, argBase))
+            }
+            continue
+        }
+        if ok {
+            b.WriteString(val)
+            continue
+        }
+
+        switch op {
+        case ":-":
+            b.WriteString(x.expandAt(arg, argBase))
+        case ":?":
+            x.missing = append(x.missing, missingRef{
+                name:     name,
+                offset:   base + start,
+                required: true,
+                message:  x.expandAt(arg, argBase),
+            })
+        default:
+            ref := missingRef{name: name, offset: base + start, optional: optional}
+            x.missing = append(x.missing, ref)
+            if x.keeps(ref) {
+                b.WriteString(s[start : end+1])
+            }
+        }
+    }
+    return b.String()
+}
+
+// keeps reports whether the unresolved ref is left in the output as-is
+// (MissingKeep).
+func (x *expander) keeps(ref missingRef) bool {
+    return x.mode.keep && !ref.isOptional(x.mode.optional)
+}
+
+// nextPlaceholder returns the index of the "$" of the next ${ in s, or of
+// the next bare $VAR if x.mode.bare is set, or -1 if there is none.
+func (x *expander) nextPlaceholder(s string) int {
+    for i := 0; i+1 < len(s); i++ {
+        if s[i] == '$' && (s[i+1] == '{' || x.mode.bare && isNameChar(s[i+1], true)) {
+            return i
+        }
+    }
+    return -1
+}
+
+// isNameChar reports whether c may appear in a bare $VAR name: letters,
+// digits (except first) and underscores.
+func isNameChar(c byte, first bool) bool {
+    return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || !first && c >= '0' && c <= '9'
+}
+
+// matchBrace returns the index of the "}" closing a placeholder whose body
+// starts at i, skipping over nested ${...}, or -1 if there is none.
+func matchBrace(s string, i int) int {
+    depth := 1
+    for ; i < len(s); i++ {
+        switch {
+        case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+            depth++
+            i++
+        case s[i] == '}':
+            depth--
+            if depth == 0 {
+                return i
+            }
+        }
+    }
+    return -1
+}
+
+// splitPlaceholder splits the inside of ${...} into the variable name, the
+// operator (":-", ":?", ":+" or "" for a bare ${VAR}) and its argument.
+func splitPlaceholder(inner string) (name, op, arg string) {
+    for i := 0; i+1 < len(inner); i++ {
+        if inner[i] != ':' {
+            continue
+        }
+        switch inner[i+1] {
+        case '-', '?', '+':
+            return inner[:i], inner[i : i+2], inner[i+2:]
+        }
+    }
+    return inner, "", ""
+}
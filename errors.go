@@ -0,0 +1,172 @@
+// errors.go
+package gonfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingEnvError is returned by Load when strict mode is enabled and one or
+// more ${VAR} placeholders have neither a value nor a default, or when a
+// ${VAR:?message} placeholder is unset (in any mode).
+//
+// The message points at every placeholder, e.g.
+//
+//	missing required env vars: TOKEN (config.yaml:12:14)
+//
+// and the same details are available programmatically:
+//
+//	var missing *gonfig.MissingEnvError
+//	if errors.As(err, &missing) {
+//	    for _, v := range missing.Vars {
+//	        log.Printf("set %s (%s line %d)", v.Name, missing.File, v.Line)
+//	    }
+//	}
+type MissingEnvError struct {
+	// File is the config source the placeholders were found in.
+	File string
+	Vars []MissingVar
+}
+
+// MissingVar describes a single unresolved placeholder.
+type MissingVar struct {
+	// Name is the env var name, e.g. "DB_PASSWORD".
+	Name string
+	// Path is the YAML path of the value holding the placeholder, e.g.
+	// "database.password".
+	Path string
+	// Line and Column are the 1-based position of the placeholder's "$".
+	Line   int
+	Column int
+	// Message is the text from a ${VAR:?message} placeholder, if any.
+	Message string
+}
+
+func (e *MissingEnvError) Error() string {
+	vars := make([]string, len(e.Vars))
+	for i, v := range e.Vars {
+		if e.File == "" {
+			vars[i] = fmt.Sprintf("%s (%d:%d)", v.Name, v.Line, v.Column)
+		} else {
+			vars[i] = fmt.Sprintf("%s (%s:%d:%d)", v.Name, e.File, v.Line, v.Column)
+		}
+		if v.Message != "" {
+			vars[i] += ": " + v.Message
+		}
+	}
+	return fmt.Sprintf("missing required env vars: %s", strings.Join(vars, ", "))
+}
+
+// ResolveError is returned by Load when a resolver registered with
+// WithResolver fails for a ${scheme:key} placeholder.
+type ResolveError struct {
+	// Name is the full placeholder name, e.g. "vault:secret/db#password".
+	Name string
+	// File, Path, Line and Column locate the placeholder like MissingVar.
+	File   string
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ResolveError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("resolve %s (%d:%d): %v", e.Name, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("resolve %s (%s:%d:%d): %v", e.Name, e.File, e.Line, e.Column, e.Err)
+}
+
+func (e *ResolveError) Unwrap() error { return e.Err }
+
+// EnvCycleError is returned (inside a *ResolveError) by Load with
+// WithRecursiveExpansion when env vars refer to each other in a cycle, or
+// nest deeper than the expansion limit.
+type EnvCycleError struct {
+	// Chain is the env vars in the order they were expanded, e.g.
+	// [A B A] when A refers to B and B back to A.
+	Chain []string
+	// TooDeep is set when the chain is too deep rather than a cycle.
+	TooDeep bool
+}
+
+func (e *EnvCycleError) Error() string {
+	if e.TooDeep {
+		return fmt.Sprintf("env vars nested deeper than %d: %s", maxEnvExpansionDepth, strings.Join(e.Chain, " -> "))
+	}
+	return "env var cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// ParseError is returned by Load when the expanded config can't be decoded
+// into the target type: malformed YAML, type mismatches, or unknown keys with
+// WithKnownFieldsOnly.
+type ParseError struct {
+	// File is the config source, e.g. "config.yaml" or "<bytes>".
+	File string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse %s: %v", e.File, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// DotenvError is returned by Load when a .env file can't be parsed.
+type DotenvError struct {
+	File string
+	// Line is the 1-based line of the problem, or of the opening quote of
+	// an unterminated value.
+	Line    int
+	Message string
+}
+
+func (e *DotenvError) Error() string {
+	return fmt.Sprintf("parse %s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// ValidationError is returned by Load when the config's Validate() method
+// fails or required fields are missing. Err is the error Validate returned,
+// or the joined FieldErrors of the missing fields.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("config validation failed: %v", e.Err)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// FieldError is a problem with a single config field, e.g. a field tagged
+// `gonfig:"required"` that was never set. It is usually found inside a
+// *ValidationError:
+//
+//	var fe *gonfig.FieldError
+//	if errors.As(err, &fe) && errors.Is(fe, gonfig.ErrRequired) {
+//	    log.Printf("%s must be set", fe.Path)
+//	}
+//
+// When the field's value (or, for a section, its key) came from a config
+// file, Load fills in where, and the message says so:
+//
+//	config validation failed: metrics.port (config.yaml:14:9): must be > 0
+type FieldError struct {
+	// Path is the YAML path of the field, e.g. "database.password".
+	Path string
+	// File, Line and Column locate the field in the config source, if
+	// known.
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s (%s:%d:%d): %v", e.Path, e.File, e.Line, e.Column, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
@@ -0,0 +1,266 @@
+// Package etcd provides a gonfig config source backed by etcd v3.
+//
+// The config is either a single key holding a YAML or JSON document, or a
+// key prefix whose subtree is turned into a document: "/app/server/port"
+// under prefix "/app/" becomes server.port.
+//
+// The source implements gonfig.Watcher with an etcd watch, so Watch and
+// NewLive reload the config as soon as it changes.
+//
+// Usage:
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    etcd.WithPrefix("/operators/ingress/",
+//	        etcd.WithEndpoints("https://etcd-0:2379", "https://etcd-1:2379"),
+//	        etcd.WithTLS(tlsConfig),
+//	        etcd.WithAuth("operator", os.Getenv("ETCD_PASSWORD")),
+//	    ),
+//	)
+package etcd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/TypeTerrors/gonfig"
+	"github.com/TypeTerrors/gonfig/internal/kvtree"
+)
+
+// Client is the subset of the etcd client used by Source.
+// *clientv3.Client implements it.
+type Client interface {
+	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+	Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan
+}
+
+// ClientOption configures the etcd client created by WithKey and WithPrefix.
+type ClientOption func(*clientv3.Config)
+
+// WithEndpoints sets the etcd endpoints. The default is the comma-separated
+// ETCD_ENDPOINTS env var, or localhost:2379.
+func WithEndpoints(endpoints ...string) ClientOption {
+	return func(c *clientv3.Config) {
+		c.Endpoints = endpoints
+	}
+}
+
+// WithTLS enables TLS, e.g. with client certificates for mutual TLS.
+func WithTLS(cfg *tls.Config) ClientOption {
+	return func(c *clientv3.Config) {
+		c.TLS = cfg
+	}
+}
+
+// WithAuth authenticates with etcd's username/password auth.
+func WithAuth(username, password string) ClientOption {
+	return func(c *clientv3.Config) {
+		c.Username = username
+		c.Password = password
+	}
+}
+
+// WithDialTimeout bounds connecting to the cluster. The default is 5
+// seconds.
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(c *clientv3.Config) {
+		c.DialTimeout = d
+	}
+}
+
+// WithKey reads the config from a single etcd key holding a YAML or JSON
+// document.
+func WithKey(key string, opts ...ClientOption) gonfig.Option {
+	s := NewSource(nil, key)
+	s.opts = opts
+	return gonfig.WithSource(s)
+}
+
+// WithPrefix builds the config from every key under prefix. Each key's
+// value becomes a scalar that is typed like plain YAML, so "8080" decodes
+// into an int field.
+func WithPrefix(prefix string, opts ...ClientOption) gonfig.Option {
+	s := NewPrefixSource(nil, prefix)
+	s.opts = opts
+	return gonfig.WithSource(s)
+}
+
+// Source fetches a config document from etcd. A client the source creates
+// itself is closed again once no Fetch or Watch is using it, so a one-shot
+// Load doesn't leave a connection behind.
+type Source struct {
+	// Key is the key, or the key prefix when Prefix is set.
+	Key    string
+	Prefix bool
+
+	opts   []ClientOption
+	client Client
+
+	mu    sync.Mutex
+	rev   int64
+	owned *clientv3.Client
+	users int
+}
+
+// NewSource reads a single key. If client is nil, one is created on first
+// Fetch.
+func NewSource(client Client, key string) *Source {
+	return &Source{Key: key, client: client}
+}
+
+// NewPrefixSource reads every key under prefix. If client is nil, one is
+// created on first Fetch.
+func NewPrefixSource(client Client, prefix string) *Source {
+	return &Source{Key: prefix, Prefix: true, client: client}
+}
+
+// Fetch implements gonfig.Source.
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	client, err := s.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer s.release()
+
+	var opts []clientv3.OpOption
+	if s.Prefix {
+		opts = append(opts, clientv3.WithPrefix())
+	}
+	resp, err := client.Get(ctx, s.Key, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("etcd get %s: %w", s.Key, err)
+	}
+	s.mu.Lock()
+	s.rev = resp.Header.GetRevision()
+	s.mu.Unlock()
+
+	if !s.Prefix {
+		if len(resp.Kvs) == 0 {
+			return nil, fmt.Errorf("etcd key %s not found", s.Key)
+		}
+		return resp.Kvs[0].Value, nil
+	}
+
+	pairs := make([]kvtree.Pair, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		pairs[i] = kvtree.Pair{Key: string(kv.Key), Value: kv.Value}
+	}
+	data, err := kvtree.Marshal(s.Key, pairs)
+	if err != nil {
+		return nil, fmt.Errorf("etcd %w", err)
+	}
+	return data, nil
+}
+
+// Watch implements gonfig.Watcher with an etcd watch starting after the
+// revision of the last Fetch. Broken watches are re-established with
+// backoff. A client created by the source stays open until ctx is done.
+func (s *Source) Watch(ctx context.Context, changed func()) error {
+	client, err := s.acquire()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	rev := s.rev
+	s.mu.Unlock()
+	if rev == 0 {
+		// Not fetched yet: start from the current revision.
+		resp, err := client.Get(ctx, s.Key, clientv3.WithCountOnly())
+		if err != nil {
+			s.release()
+			return fmt.Errorf("etcd get %s: %w", s.Key, err)
+		}
+		rev = resp.Header.GetRevision()
+	}
+
+	go func() {
+		defer s.release()
+		backoff := time.Second
+		for {
+			opts := []clientv3.OpOption{clientv3.WithRev(rev + 1)}
+			if s.Prefix {
+				opts = append(opts, clientv3.WithPrefix())
+			}
+			for resp := range client.Watch(clientv3.WithRequireLeader(ctx), s.Key, opts...) {
+				if resp.CompactRevision != 0 {
+					// Revisions we haven't seen were compacted away:
+					// reload and continue from the current revision.
+					rev = resp.CompactRevision - 1
+					changed()
+					break
+				}
+				if err := resp.Err(); err != nil {
+					break
+				}
+				if len(resp.Events) > 0 {
+					rev = resp.Header.GetRevision()
+					backoff = time.Second
+					changed()
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, 30*time.Second)
+		}
+	}()
+	return nil
+}
+
+// String names the source in errors.
+func (s *Source) String() string {
+	if s.Prefix {
+		return "etcd://" + s.Key + "*"
+	}
+	return "etcd://" + s.Key
+}
+
+// acquire returns the client to use, creating one if the source wasn't
+// given a client and none is open. Each call must be paired with release.
+func (s *Source) acquire() (Client, error) {
+	if s.client != nil {
+		return s.client, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owned == nil {
+		cfg := clientv3.Config{
+			Endpoints:   []string{"localhost:2379"},
+			DialTimeout: 5 * time.Second,
+		}
+		if env := os.Getenv("ETCD_ENDPOINTS"); env != "" {
+			cfg.Endpoints = strings.Split(env, ",")
+		}
+		for _, opt := range s.opts {
+			opt(&cfg)
+		}
+		c, err := clientv3.New(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("create etcd client: %w", err)
+		}
+		s.owned = c
+	}
+	s.users++
+	return s.owned, nil
+}
+
+// release closes the created client when its last user is done.
+func (s *Source) release() {
+	if s.client != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.users--; s.users == 0 {
+		s.owned.Close()
+		s.owned = nil
+	}
+}
@@ -0,0 +1,170 @@
+package etcd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// fakeClient serves kvs at rev; Watch delivers whatever is sent on events.
+type fakeClient struct {
+	mu     sync.Mutex
+	kvs    map[string]string
+	rev    int64
+	events chan clientv3.WatchResponse
+}
+
+func (f *fakeClient) Get(_ context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	op := clientv3.OpGet(key, opts...)
+	resp := &clientv3.GetResponse{Header: &etcdserverpb.ResponseHeader{Revision: f.rev}}
+	for k, v := range f.kvs {
+		if k == key || (len(op.RangeBytes()) > 0 && strings.HasPrefix(k, key)) {
+			resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeClient) Watch(ctx context.Context, _ string, _ ...clientv3.OpOption) clientv3.WatchChan {
+	ch := make(chan clientv3.WatchResponse)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case resp := <-f.events:
+				ch <- resp
+			}
+		}
+	}()
+	return ch
+}
+
+func (f *fakeClient) put(key, value string) {
+	f.mu.Lock()
+	f.kvs[key] = value
+	f.rev++
+	rev := f.rev
+	f.mu.Unlock()
+	f.events <- clientv3.WatchResponse{
+		Header: &etcdserverpb.ResponseHeader{Revision: rev},
+		Events: []*clientv3.Event{{Type: clientv3.EventTypePut}},
+	}
+}
+
+type config struct {
+	Server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"server"`
+}
+
+func TestKey(t *testing.T) {
+	c := &fakeClient{rev: 1, kvs: map[string]string{"/svc/config": `{"server": {"port": 8080}}`}}
+	cfg, err := gonfig.Load[config](gonfig.WithSource(NewSource(c, "/svc/config")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8080 {
+		t.Fatalf("expected port 8080, got %d", cfg.Server.Port)
+	}
+
+	if _, err := gonfig.Load[config](gonfig.WithSource(NewSource(c, "/svc/missing"))); err == nil {
+		t.Fatalf("expected error for missing key")
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	c := &fakeClient{rev: 1, kvs: map[string]string{
+		"/svc/server/host": "${HOST:-localhost}",
+		"/svc/server/port": "8080",
+	}}
+	cfg, err := gonfig.Load[config](gonfig.WithSource(NewPrefixSource(c, "/svc/")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Host != "localhost" || cfg.Server.Port != 8080 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	c := &fakeClient{
+		rev:    1,
+		kvs:    map[string]string{"/svc/server/port": "8080"},
+		events: make(chan clientv3.WatchResponse),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := gonfig.Watch[config](ctx, gonfig.WithSource(NewPrefixSource(c, "/svc/")))
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if cfg := <-updates; cfg.Server.Port != 8080 {
+		t.Fatalf("expected initial port 8080, got %d", cfg.Server.Port)
+	}
+
+	c.put("/svc/server/port", "9090")
+
+	select {
+	case cfg := <-updates:
+		if cfg.Server.Port != 9090 {
+			t.Fatalf("expected reloaded port 9090, got %d", cfg.Server.Port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reloaded config")
+	}
+}
+
+func TestCreatedClientClosed(t *testing.T) {
+	s := NewSource(nil, "/svc/config")
+	s.opts = []ClientOption{WithEndpoints("127.0.0.1:1"), WithDialTimeout(100 * time.Millisecond)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := s.Fetch(ctx); err == nil {
+		t.Fatalf("expected an error from an unreachable cluster")
+	}
+	if s.owned != nil {
+		t.Fatalf("expected the client to be closed after Fetch")
+	}
+
+	s.rev = 1
+	ctx, cancel = context.WithCancel(context.Background())
+	if err := s.Watch(ctx, func() {}); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	s.mu.Lock()
+	open := s.owned != nil
+	s.mu.Unlock()
+	if !open {
+		t.Fatalf("expected the client to stay open while watching")
+	}
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.mu.Lock()
+		open = s.owned != nil
+		s.mu.Unlock()
+		if !open {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the client to be closed once the watch ended")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
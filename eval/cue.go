@@ -0,0 +1,44 @@
+//go:build gonfig_cue
+
+package eval
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/encoding/yaml"
+)
+
+// evalCUE evaluates Path, and the package it belongs to, and returns the
+// selected value as YAML. The value must be concrete.
+func (s *Source) evalCUE(context.Context) ([]byte, error) {
+	cfg := &load.Config{Dir: filepath.Dir(s.Path)}
+	for name, value := range s.Tags {
+		cfg.Tags = append(cfg.Tags, name+"="+value)
+	}
+	insts := load.Instances([]string{filepath.Base(s.Path)}, cfg)
+	if err := insts[0].Err; err != nil {
+		return nil, fmt.Errorf("evaluate %s: %s", s.Path, errors.Details(err, nil))
+	}
+
+	v := cuecontext.New().BuildInstance(insts[0])
+	if s.Expression != "" {
+		v = v.LookupPath(cue.ParsePath(s.Expression))
+		if !v.Exists() {
+			return nil, fmt.Errorf("evaluate %s: %s not found", s.Path, s.Expression)
+		}
+	}
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, fmt.Errorf("evaluate %s: %s", s.Path, errors.Details(err, nil))
+	}
+	out, err := yaml.Encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate %s: %w", s.Path, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,13 @@
+//go:build !gonfig_cue
+
+package eval
+
+import (
+	"context"
+	"fmt"
+)
+
+// evalCUE fails: CUE support needs the gonfig_cue build tag.
+func (s *Source) evalCUE(context.Context) ([]byte, error) {
+	return nil, fmt.Errorf("evaluate %s: CUE support is not compiled in; build with -tags gonfig_cue", s.Path)
+}
@@ -0,0 +1,54 @@
+//go:build gonfig_cue
+
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+func TestSource_CUE(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.cue")
+	const src = `package config
+
+env: string | *"dev" @tag(env)
+
+#Server: {
+	port: int & >0 & <65536
+	env:  string
+}
+
+service: {
+	name:   "api"
+	server: #Server & {port: 8000 + 80, "env": env}
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := gonfig.Load[config](gonfig.WithSource(&Source{
+		Path:       path,
+		Expression: "service",
+		Tags:       map[string]string{"env": "prod"},
+	}))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "api" || cfg.Server.Port != 8080 || cfg.Server.Env != "prod" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Replace(src, "8000 + 80", "0", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = gonfig.Load[config](gonfig.WithSource(&Source{Path: path, Expression: "service"}))
+	if err == nil || !strings.Contains(err.Error(), "evaluate "+path) || !strings.Contains(err.Error(), "invalid value 0") {
+		t.Fatalf("expected a CUE constraint error, got %v", err)
+	}
+}
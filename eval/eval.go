@@ -0,0 +1,122 @@
+// Package eval provides a gonfig config source that evaluates a CUE,
+// Jsonnet or Starlark file, so complex configs can use real abstraction
+// (functions, imports, schemas) instead of YAML anchors.
+//
+// The evaluated document takes the place of a YAML file: ${VAR}
+// placeholders in its strings are expanded, and env overrides, defaults and
+// validation apply as usual.
+//
+//	cfg, err := gonfig.Load[Config](
+//	    eval.WithFile("config.cue"), // or "config.jsonnet", "config.star"
+//	)
+//
+// CUE is evaluated in process with cuelang.org/go, which is only compiled
+// in with the gonfig_cue build tag:
+//
+//	go build -tags gonfig_cue ./...
+//
+// Jsonnet is evaluated by the jsonnet command (the C++ or Go
+// implementation), which must be on PATH or set with Source.Jsonnet.
+//
+// Starlark is run in process in a sandbox. The program sets a global
+// config dict, or defines main() returning one; env(name, default=None)
+// reads the env vars allowed by Source.Env and nothing else:
+//
+//	def main():
+//	    replicas = 3 if env("APP_ENV") == "prod" else 1
+//	    return {"name": "api", "replicas": replicas}
+//
+//	cfg, err := gonfig.Load[Config](eval.WithStarlark("config.star", "APP_*"))
+//
+// The source implements gonfig.Watcher by watching the directories of the
+// file and its import paths, so Watch and NewLive reload the config when
+// the file or a library it imports changes.
+package eval
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/TypeTerrors/gonfig"
+	"github.com/TypeTerrors/gonfig/internal/fswatch"
+)
+
+// WithFile reads the config from a .cue, .jsonnet, .libsonnet or .star
+// file.
+func WithFile(path string) gonfig.Option {
+	return gonfig.WithSource(NewSource(path))
+}
+
+// WithStarlark reads the config from a Starlark program that may read the
+// env vars named in env (see Source.Env).
+func WithStarlark(path string, env ...string) gonfig.Option {
+	return gonfig.WithSource(&Source{Path: path, Env: env})
+}
+
+// Source evaluates a CUE, Jsonnet or Starlark file, chosen by its
+// extension.
+type Source struct {
+	// Path is the file to evaluate.
+	Path string
+
+	// Expression selects the part of a CUE evaluation to load, e.g.
+	// "services.api". The default is the whole value.
+	Expression string
+	// Tags sets CUE injection tags: Tags["env"] = "prod" for fields
+	// marked @tag(env).
+	Tags map[string]string
+
+	// ImportPaths are Jsonnet library directories (jsonnet -J). They are
+	// watched too.
+	ImportPaths []string
+	// ExtVars are Jsonnet external string variables (std.extVar).
+	ExtVars map[string]string
+	// Jsonnet is the jsonnet command. The default is "jsonnet".
+	Jsonnet string
+
+	// Env lists the env vars a Starlark program may read with env(). A
+	// trailing "*" allows a prefix, e.g. "APP_*". Reading any other
+	// variable fails the evaluation.
+	Env []string
+	// MaxSteps bounds the computation of a Starlark program (and of each
+	// module it loads), so a runaway loop fails instead of blocking the
+	// load. The default is 100 million steps.
+	MaxSteps uint64
+}
+
+// NewSource evaluates the file at path.
+func NewSource(path string) *Source {
+	return &Source{Path: path}
+}
+
+// Fetch implements gonfig.Source.
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	switch filepath.Ext(s.Path) {
+	case ".cue":
+		return s.evalCUE(ctx)
+	case ".jsonnet", ".libsonnet":
+		return s.evalJsonnet(ctx)
+	case ".star":
+		return s.evalStarlark(ctx)
+	}
+	return nil, fmt.Errorf("evaluate %s: not a .cue, .jsonnet or .star file", s.Path)
+}
+
+// Watch implements gonfig.Watcher. It calls changed when a CUE, Jsonnet,
+// Starlark, JSON or YAML file in the directory of Path or in one of the ImportPaths
+// changes.
+func (s *Source) Watch(ctx context.Context, changed func()) error {
+	dirs := append([]string{filepath.Dir(s.Path)}, s.ImportPaths...)
+	return fswatch.Dirs(ctx, dirs, func(name string) bool {
+		switch filepath.Ext(name) {
+		case ".cue", ".jsonnet", ".libsonnet", ".star", ".json", ".yaml", ".yml":
+			return true
+		}
+		// A Kubernetes ConfigMap update (see gonfig.WithConfigFile).
+		return filepath.Base(name) == "..data"
+	}, changed)
+}
+
+// String names the source in errors.
+func (s *Source) String() string { return s.Path }
@@ -0,0 +1,141 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+type config struct {
+	Name   string `yaml:"name"`
+	Server struct {
+		Port int    `yaml:"port"`
+		Env  string `yaml:"env"`
+	} `yaml:"server"`
+}
+
+// fakeJsonnet writes a jsonnet stand-in that logs its arguments to args.txt
+// and prints out, and returns its path.
+func fakeJsonnet(t *testing.T, out string) (bin, argsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	bin = filepath.Join(dir, "jsonnet")
+	argsFile = filepath.Join(dir, "args.txt")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncat <<'EOF'\n" + out + "\nEOF\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, argsFile
+}
+
+func TestSource_Jsonnet(t *testing.T) {
+	bin, argsFile := fakeJsonnet(t, `{"name": "api", "server": {"port": 8080, "env": "${EVAL_TEST_ENV}"}}`)
+	t.Setenv("EVAL_TEST_ENV", "prod")
+
+	src := &Source{
+		Path:        "config.jsonnet",
+		ImportPaths: []string{"lib"},
+		ExtVars:     map[string]string{"region": "eu", "env": "prod"},
+		Jsonnet:     bin,
+	}
+	cfg, err := gonfig.Load[config](gonfig.WithSource(src))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "api" || cfg.Server.Port != 8080 || cfg.Server.Env != "prod" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "-J lib --ext-str env=prod --ext-str region=eu -- config.jsonnet"; strings.TrimSpace(string(args)) != want {
+		t.Fatalf("jsonnet args = %q, want %q", args, want)
+	}
+}
+
+func TestSource_JsonnetError(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "jsonnet")
+	script := "#!/bin/sh\necho 'RUNTIME ERROR: field does not exist: port' >&2\nexit 1\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := gonfig.Load[config](gonfig.WithSource(&Source{Path: "config.jsonnet", Jsonnet: bin}))
+	if err == nil || !strings.Contains(err.Error(), "evaluate config.jsonnet: RUNTIME ERROR: field does not exist: port") {
+		t.Fatalf("expected the jsonnet error, got %v", err)
+	}
+}
+
+func TestSource_UnknownExtension(t *testing.T) {
+	_, err := gonfig.Load[config](WithFile("config.toml"))
+	if err == nil || !strings.Contains(err.Error(), "not a .cue, .jsonnet or .star file") {
+		t.Fatalf("expected an extension error, got %v", err)
+	}
+}
+
+func TestSource_Starlark(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("lib/ports.star", "def port(env):\n    return 443 if env == \"prod\" else 8080\n")
+	path := write("config.star", `load("lib/ports.star", "port")
+
+def main():
+    env_name = env("EVAL_APP_ENV", "dev")
+    return {
+        "name": "api",
+        "server": {"port": port(env_name), "env": env_name},
+    }
+`)
+	t.Setenv("EVAL_APP_ENV", "prod")
+
+	cfg, err := gonfig.Load[config](WithStarlark(path, "EVAL_APP_*"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "api" || cfg.Server.Port != 443 || cfg.Server.Env != "prod" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	_, err = gonfig.Load[config](WithStarlark(path))
+	if err == nil || !strings.Contains(err.Error(), "env: EVAL_APP_ENV is not an allowed env var") {
+		t.Fatalf("expected env access to be denied, got %v", err)
+	}
+
+	for src, want := range map[string]string{
+		"config = {\"name\": \"api\", \"server\": {1: 2}}\n": "server: dict key 1 (int) is not a string",
+		"config = [1]\n":                                            "set config to a dict or define main() returning one",
+		"load(\"../secret.star\", \"x\")\n":                         `load "../secret.star": only files below`,
+		"config = {\"name\": \"api\"}\nfail(\"boom\")\n":            "boom",
+		"l = []\nl.append(l)\nconfig = {\"tags\": l}\n":             "tags[0]: list contains itself",
+		"def main():\n    for i in range(1 << 40):\n        pass\n": "too many steps",
+	} {
+		path := write("bad.star", src)
+		_, err := gonfig.Load[config](gonfig.WithSource(&Source{Path: path, MaxSteps: 100_000}))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: expected %q in error, got %v", src, want, err)
+		}
+	}
+
+	path = write("bytes.star", "config = {\"name\": b\"api\", \"server\": {\"env\": (\"prod\",)[0]}}\n")
+	cfg, err = gonfig.Load[config](WithFile(path))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "api" || cfg.Server.Env != "prod" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
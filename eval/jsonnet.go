@@ -0,0 +1,45 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// evalJsonnet runs the jsonnet command on Path and returns the JSON it
+// prints, which gonfig parses as YAML.
+func (s *Source) evalJsonnet(ctx context.Context) ([]byte, error) {
+	bin := s.Jsonnet
+	if bin == "" {
+		bin = "jsonnet"
+	}
+	var args []string
+	for _, dir := range s.ImportPaths {
+		args = append(args, "-J", dir)
+	}
+	names := make([]string, 0, len(s.ExtVars))
+	for name := range s.ExtVars {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		args = append(args, "--ext-str", name+"="+s.ExtVars[name])
+	}
+	args = append(args, "--", s.Path)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if msg := strings.TrimSpace(stderr.String()); errors.As(err, &exitErr) && msg != "" {
+			return nil, fmt.Errorf("evaluate %s: %s", s.Path, msg)
+		}
+		return nil, fmt.Errorf("evaluate %s: %w", s.Path, err)
+	}
+	return stdout.Bytes(), nil
+}
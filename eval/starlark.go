@@ -0,0 +1,225 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultMaxSteps is the default Source.MaxSteps, far more than any
+// config needs.
+const defaultMaxSteps = 100_000_000
+
+// evalStarlark runs Path as a Starlark program and returns its config dict
+// as YAML. The program either sets a global config or defines main()
+// returning the dict. It can load() other files below its directory and
+// read the env vars allowed by Env with env(name, default=None); it has no
+// other access to the host.
+func (s *Source) evalStarlark(ctx context.Context) ([]byte, error) {
+	ld := &starlarkLoader{
+		ctx:         ctx,
+		dir:         filepath.Dir(s.Path),
+		predeclared: starlark.StringDict{"env": s.envBuiltin()},
+		modules:     make(map[string]*starlarkModule),
+		maxSteps:    s.MaxSteps,
+	}
+	if ld.maxSteps == 0 {
+		ld.maxSteps = defaultMaxSteps
+	}
+	defer ld.close()
+	thread := ld.thread(s.Path)
+	globals, err := ld.exec(thread, s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate %s: %w", s.Path, starlarkError(err))
+	}
+
+	v, ok := globals["config"]
+	if main, isMain := globals["main"].(starlark.Callable); isMain {
+		v, err = starlark.Call(thread, main, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate %s: %w", s.Path, starlarkError(err))
+		}
+		ok = true
+	}
+	if _, isDict := v.(*starlark.Dict); !ok || !isDict {
+		return nil, fmt.Errorf("evaluate %s: set config to a dict or define main() returning one", s.Path)
+	}
+	n, err := starlarkNode(v, "", make(map[starlark.Value]bool))
+	if err != nil {
+		return nil, fmt.Errorf("evaluate %s: %w", s.Path, err)
+	}
+	return yaml.Marshal(n)
+}
+
+// envBuiltin is the env(name, default=None) builtin. It only reads the
+// variables allowed by Env.
+func (s *Source) envBuiltin() *starlark.Builtin {
+	return starlark.NewBuiltin("env", func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var name string
+		var def starlark.Value = starlark.None
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name, "default?", &def); err != nil {
+			return nil, err
+		}
+		if !s.envAllowed(name) {
+			return nil, fmt.Errorf("%s: %s is not an allowed env var", fn.Name(), name)
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return starlark.String(v), nil
+		}
+		return def, nil
+	})
+}
+
+// envAllowed reports whether Env allows the program to read name.
+func (s *Source) envAllowed(name string) bool {
+	for _, pattern := range s.Env {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(name, prefix) || pattern == name {
+			return true
+		}
+	}
+	return false
+}
+
+// starlarkLoader executes a Starlark program and the modules it loads.
+type starlarkLoader struct {
+	ctx         context.Context
+	dir         string
+	predeclared starlark.StringDict
+	// modules caches loaded modules by path; nil marks a module that is
+	// still being loaded.
+	modules map[string]*starlarkModule
+	// maxSteps bounds the computation of each thread (Source.MaxSteps).
+	maxSteps uint64
+	// stops unregister the cancellation of each thread.
+	stops []func() bool
+}
+
+type starlarkModule struct {
+	globals starlark.StringDict
+	err     error
+}
+
+// thread returns a thread for the file at path, cancelled with ctx.
+func (ld *starlarkLoader) thread(path string) *starlark.Thread {
+	thread := &starlark.Thread{Name: path, Load: ld.load}
+	thread.SetMaxExecutionSteps(ld.maxSteps)
+	stop := context.AfterFunc(ld.ctx, func() { thread.Cancel(ld.ctx.Err().Error()) })
+	ld.stops = append(ld.stops, stop)
+	return thread
+}
+
+// close releases the threads.
+func (ld *starlarkLoader) close() {
+	for _, stop := range ld.stops {
+		stop()
+	}
+}
+
+func (ld *starlarkLoader) exec(thread *starlark.Thread, path string) (starlark.StringDict, error) {
+	opts := &syntax.FileOptions{Set: true, While: true, TopLevelControl: true}
+	return starlark.ExecFileOptions(opts, thread, path, nil, ld.predeclared)
+}
+
+// load implements load() for files below the program's directory.
+func (ld *starlarkLoader) load(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+	if !filepath.IsLocal(module) {
+		return nil, fmt.Errorf("load %q: only files below %s can be loaded", module, ld.dir)
+	}
+	path := filepath.Join(ld.dir, module)
+	m, ok := ld.modules[path]
+	if ok && m == nil {
+		return nil, fmt.Errorf("load %q: import cycle", module)
+	}
+	if !ok {
+		ld.modules[path] = nil
+		globals, err := ld.exec(ld.thread(path), path)
+		m = &starlarkModule{globals: globals, err: err}
+		ld.modules[path] = m
+	}
+	return m.globals, m.err
+}
+
+// starlarkError returns the backtrace of a Starlark runtime error, which
+// says where in the program it happened.
+func starlarkError(err error) error {
+	if e, ok := err.(*starlark.EvalError); ok {
+		return fmt.Errorf("%s", strings.TrimSpace(e.Backtrace()))
+	}
+	return err
+}
+
+// starlarkNode converts a Starlark value to YAML. path is the value's
+// position in the config dict, for errors; seen holds the lists and dicts
+// being converted, to reject cycles.
+func starlarkNode(v starlark.Value, path string, seen map[starlark.Value]bool) (*yaml.Node, error) {
+	scalar := func(tag, value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	}
+	switch v.(type) {
+	case *starlark.Dict, *starlark.List:
+		if seen[v] {
+			return nil, fmt.Errorf("%s: %s contains itself", displayPath(path), v.Type())
+		}
+		seen[v] = true
+		defer delete(seen, v)
+	}
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return scalar("!!null", "null"), nil
+	case starlark.Bool:
+		return scalar("!!bool", strconv.FormatBool(bool(v))), nil
+	case starlark.Int:
+		return scalar("!!int", v.String()), nil
+	case starlark.Float:
+		return scalar("!!float", strconv.FormatFloat(float64(v), 'g', -1, 64)), nil
+	case starlark.String:
+		return scalar("!!str", string(v)), nil
+	case starlark.Bytes:
+		return scalar("!!str", string(v)), nil
+	case *starlark.Dict:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("%s: dict key %s (%s) is not a string", displayPath(path), item[0], item[0].Type())
+			}
+			child := string(key)
+			if path != "" {
+				child = path + "." + child
+			}
+			value, err := starlarkNode(item[1], child, seen)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, scalar("!!str", string(key)), value)
+		}
+		return n, nil
+	case *starlark.List, starlark.Tuple:
+		seq := v.(starlark.Indexable)
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i := range seq.Len() {
+			item, err := starlarkNode(seq.Index(i), fmt.Sprintf("%s[%d]", path, i), seen)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, item)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("%s: cannot use a value of type %s in a config", displayPath(path), v.Type())
+}
+
+// displayPath names the root of the config dict "config".
+func displayPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
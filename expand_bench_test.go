@@ -0,0 +1,70 @@
+package gonfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// largeConfig returns a generated config of about size bytes in which one
+// value in four has a placeholder.
+func largeConfig(size int) []byte {
+	var b strings.Builder
+	for i := 0; b.Len() < size; i++ {
+		fmt.Fprintf(&b, "service_%d:\n", i)
+		fmt.Fprintf(&b, "  host: ${SERVICE_%d_HOST:-svc-%d.internal}\n", i, i)
+		fmt.Fprintf(&b, "  port: %d\n", 8000+i%1000)
+		fmt.Fprintf(&b, "  labels: [web, \"tier-%d\", plain text value]\n", i%3)
+		fmt.Fprintf(&b, "  description: a longer plain description without any placeholders at all\n")
+	}
+	return []byte(b.String())
+}
+
+func BenchmarkExpandString(b *testing.B) {
+	inputs := []string{
+		"plain value without placeholders",
+		"${HOST:-localhost}:${PORT:-8080}",
+		"postgres://${DB_USER:-app}:${DB_PASSWORD:-}@${DB_HOST:-${FALLBACK_HOST:-localhost}}/app",
+		"literal $${NOT_EXPANDED} and ${PATH}",
+	}
+	for _, in := range inputs {
+		b.Run(in[:10], func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, _, err := expandString(in, envLookup, expandMode{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkExpandNode(b *testing.B) {
+	raw := largeConfig(2 << 20)
+	b.SetBytes(int64(len(raw)))
+	b.ReportAllocs()
+	for b.Loop() {
+		b.StopTimer()
+		var doc yaml.Node
+		if err := yaml.Unmarshal(raw, &doc); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		if _, err := expandNode(&doc, expandMode{}, envLookup); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	raw := largeConfig(2 << 20)
+	b.SetBytes(int64(len(raw)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := Load[map[string]any](WithBytes(raw)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
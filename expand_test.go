@@ -0,0 +1,538 @@
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoad_ExpandSkipsCommentsAndBlockScalars(t *testing.T) {
+	type scriptConfig struct {
+		Name   string `yaml:"name"`
+		Script string `yaml:"script"`
+	}
+	t.Setenv("TEST_EXPAND_NAME", "svc")
+	raw := "# set ${TEST_EXPAND_UNSET} before deploying\n" +
+		"name: ${TEST_EXPAND_NAME}\n" +
+		"script: |\n" +
+		"  echo \"${HOME}\"\n"
+
+	cfg, err := Load[scriptConfig](WithBytes([]byte(raw)), WithStrict())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Name != "svc" {
+		t.Fatalf("expected name svc, got %q", cfg.Name)
+	}
+	if cfg.Script != "echo \"${HOME}\"\n" {
+		t.Fatalf("expected block scalar to be left verbatim, got %q", cfg.Script)
+	}
+}
+
+func TestLoad_ExpandKeepsScalarTyping(t *testing.T) {
+	type typedConfig struct {
+		Port  int    `yaml:"port"`
+		Label string `yaml:"label"`
+	}
+	t.Setenv("TEST_EXPAND_PORT", "8080")
+	raw := "port: ${TEST_EXPAND_PORT}\nlabel: \"${TEST_EXPAND_PORT}\"\n"
+
+	cfg, err := Load[typedConfig](WithBytes([]byte(raw)))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Port != 8080 || cfg.Label != "8080" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	m, err := Load[map[string]any](WithBytes([]byte(raw)))
+	if err != nil {
+		t.Fatalf("Load map: %v", err)
+	}
+	if m["port"] != 8080 {
+		t.Fatalf("expected plain scalar to resolve to int, got %#v", m["port"])
+	}
+	if m["label"] != "8080" {
+		t.Fatalf("expected quoted scalar to stay a string, got %#v", m["label"])
+	}
+}
+
+func TestLoad_MissingEnvReportsPath(t *testing.T) {
+	raw := "database:\n  password: \"${TEST_EXPAND_MISSING}\"\n"
+
+	_, err := Load[map[string]any](WithBytes([]byte(raw)), WithStrict())
+
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected *MissingEnvError, got %v", err)
+	}
+	v := missing.Vars[0]
+	if v.Path != "database.password" || v.Line != 2 || v.Column != 14 {
+		t.Fatalf("unexpected location: %+v", v)
+	}
+}
+
+func TestExpandString_Escape(t *testing.T) {
+	t.Setenv("TEST_ESCAPE_JOB", "api")
+
+	out, missing := expand(t, `rate(http_requests{job="${TEST_ESCAPE_JOB}"}[$${WINDOW}])`)
+	if len(missing) != 0 {
+		t.Fatalf("expected escaped placeholder not to be reported missing, got %+v", missing)
+	}
+	if want := `rate(http_requests{job="api"}[${WINDOW}])`; out != want {
+		t.Fatalf("expected %q, got %q", want, out)
+	}
+}
+
+func TestLoad_RequiredWithMessage(t *testing.T) {
+	raw := "database:\n  password: ${TEST_REQ_DB_PASSWORD:?set it from the vault path db/creds}\n"
+
+	_, err := Load[map[string]any](WithBytes([]byte(raw)))
+
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected *MissingEnvError outside strict mode, got %v", err)
+	}
+	if got := missing.Vars[0].Message; got != "set it from the vault path db/creds" {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if !strings.Contains(err.Error(), "TEST_REQ_DB_PASSWORD (<bytes>:2:13): set it from the vault path db/creds") {
+		t.Fatalf("expected message in error text, got %v", err)
+	}
+
+	t.Setenv("TEST_REQ_DB_PASSWORD", "s3cret")
+	cfg, err := Load[map[string]any](WithBytes([]byte(raw)))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg["database"].(map[string]any)["password"]; got != "s3cret" {
+		t.Fatalf("expected password s3cret, got %#v", got)
+	}
+}
+
+func TestExpandString_NestedDefaults(t *testing.T) {
+	out, missing := expand(t, "${TEST_NESTED_CACHE:-${TEST_NESTED_REDIS:-localhost}}:6379")
+	if len(missing) != 0 || out != "localhost:6379" {
+		t.Fatalf("expected innermost default, got %q (missing %+v)", out, missing)
+	}
+
+	t.Setenv("TEST_NESTED_REDIS", "redis")
+	out, _ = expand(t, "${TEST_NESTED_CACHE:-${TEST_NESTED_REDIS:-localhost}}:6379")
+	if out != "redis:6379" {
+		t.Fatalf("expected nested variable, got %q", out)
+	}
+
+	t.Setenv("TEST_NESTED_CACHE", "cache")
+	out, _ = expand(t, "${TEST_NESTED_CACHE:-${TEST_NESTED_REDIS:-localhost}}:6379")
+	if out != "cache:6379" {
+		t.Fatalf("expected outer variable, got %q", out)
+	}
+}
+
+func TestExpandString_NestedMissingOffset(t *testing.T) {
+	_, missing := expand(t, "x ${TEST_NESTED_A:-${TEST_NESTED_B}}")
+	if len(missing) != 1 || missing[0].name != "TEST_NESTED_B" || missing[0].offset != 19 {
+		t.Fatalf("unexpected missing refs: %+v", missing)
+	}
+}
+
+func TestExpandString_LeavesMalformedPlaceholders(t *testing.T) {
+	for _, s := range []string{"${", "${}", "cost: $5", "${UNTERMINATED"} {
+		if out, _ := expand(t, s); out != s {
+			t.Fatalf("expected %q to be left alone, got %q", s, out)
+		}
+	}
+}
+
+func TestExpandString_Alternate(t *testing.T) {
+	out, missing := expand(t, "run ${TEST_ALT_DEBUG:+--verbose}")
+	if out != "run " || len(missing) != 0 {
+		t.Fatalf("expected empty alternate when unset, got %q (missing %+v)", out, missing)
+	}
+
+	t.Setenv("TEST_ALT_DEBUG", "1")
+	out, _ = expand(t, "run ${TEST_ALT_DEBUG:+--verbose}")
+	if out != "run --verbose" {
+		t.Fatalf("expected alternate when set, got %q", out)
+	}
+}
+
+func TestLoad_Resolver(t *testing.T) {
+	secrets := map[string]string{"secret/db#password": "s3cret"}
+	vault := func(ctx context.Context, key string) (string, error) {
+		if v, ok := secrets[key]; ok {
+			return v, nil
+		}
+		return "", fmt.Errorf("vault key %s: %w", key, ErrNotFound)
+	}
+	raw := "password: ${vault:secret/db#password}\nuser: ${vault:secret/db#user:-admin}\n"
+
+	cfg, err := Load[map[string]any](WithBytes([]byte(raw)), WithStrict(), WithResolver("vault", vault))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["password"] != "s3cret" || cfg["user"] != "admin" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+}
+
+func TestLoad_ResolverError(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+	failing := func(ctx context.Context, key string) (string, error) {
+		return "", errBackend
+	}
+
+	_, err := Load[map[string]any](
+		WithBytes([]byte("db:\n  password: ${vault:secret/db}\n")),
+		WithResolver("vault", failing),
+	)
+
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) {
+		t.Fatalf("expected *ResolveError, got %v", err)
+	}
+	if resolveErr.Path != "db.password" || resolveErr.Line != 2 || !errors.Is(err, errBackend) {
+		t.Fatalf("unexpected resolve error: %+v", resolveErr)
+	}
+}
+
+// expand runs expandString against the process environment.
+func expand(t *testing.T, s string) (string, []missingRef) {
+	t.Helper()
+
+	out, missing, err := expandString(s, envLookup, expandMode{})
+	if err != nil {
+		t.Fatalf("expandString(%q): %v", s, err)
+	}
+	return out, missing
+}
+
+func TestLoad_FilePlaceholder(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+	raw := "password: ${file:" + secret + "}\ntoken: ${file:/nonexistent/token:-none}\n"
+
+	cfg, err := Load[map[string]any](WithBytes([]byte(raw)), WithStrict())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["password"] != "s3cret" {
+		t.Fatalf("expected trimmed file contents, got %#v", cfg["password"])
+	}
+	if cfg["token"] != "none" {
+		t.Fatalf("expected default for missing file, got %#v", cfg["token"])
+	}
+}
+
+func TestLoad_CredentialPlaceholder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db_password"), []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatalf("write credential: %v", err)
+	}
+	env := map[string]string{"CREDENTIALS_DIRECTORY": dir}
+	lookup := WithEnvLookup(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+	raw := []byte("password: ${cred:db_password}\ntoken: ${cred:api_token:-none}\n")
+
+	cfg, err := Load[map[string]any](WithBytes(raw), lookup, WithStrict())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["password"] != "s3cret" || cfg["token"] != "none" {
+		t.Fatalf("unexpected config: %#v", cfg)
+	}
+
+	delete(env, "CREDENTIALS_DIRECTORY")
+	var missing *MissingEnvError
+	if _, err := Load[map[string]any](WithBytes(raw), lookup, WithStrict()); !errors.As(err, &missing) {
+		t.Fatalf("expected a MissingEnvError without CREDENTIALS_DIRECTORY, got %v", err)
+	}
+
+	env["CREDENTIALS_DIRECTORY"] = dir
+	if _, err := Load[map[string]any](WithBytes([]byte("x: ${cred:../etc/passwd}\n")), lookup); err == nil {
+		t.Fatalf("expected an error for a credential name with a path")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	env := map[string]string{"HOST": "db.internal", "PORT": ""}
+	lookup := ExpandLookup(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+
+	out, err := Expand("postgres://${HOST}:${PORT:-5432}/${DB:-app} $${LITERAL} ${USER}", lookup)
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+	if want := "postgres://db.internal:/app ${LITERAL} "; out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+
+	_, err = Expand("host: ${HOST}\nuser: ${USER}", lookup, ExpandStrict())
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) || len(missing.Vars) != 1 {
+		t.Fatalf("expected *MissingEnvError for USER, got %v", err)
+	}
+	if v := missing.Vars[0]; v.Name != "USER" || v.Line != 2 || v.Column != 7 {
+		t.Fatalf("unexpected missing var: %+v", v)
+	}
+
+	_, err = Expand("${TOKEN:?set TOKEN}", lookup)
+	if !errors.As(err, &missing) || missing.Vars[0].Message != "set TOKEN" {
+		t.Fatalf("expected required error outside strict mode, got %v", err)
+	}
+
+	errBackend := errors.New("backend down")
+	out, err = Expand("${vault:db#user}", ExpandResolver("vault", func(context.Context, string) (string, error) {
+		return "app", nil
+	}))
+	if err != nil || out != "app" {
+		t.Fatalf("resolver: got %q, %v", out, err)
+	}
+	_, err = Expand("${vault:db#user}", ExpandResolver("vault", func(context.Context, string) (string, error) {
+		return "", errBackend
+	}))
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || !errors.Is(err, errBackend) {
+		t.Fatalf("expected *ResolveError, got %v", err)
+	}
+}
+
+func TestLoad_WithBareVariables(t *testing.T) {
+	env := map[string]string{"HOME": "/home/app", "USER": "app"}
+	lookup := WithEnvLookup(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+	raw := "data_dir: $HOME/.cache\nlog: $USER.log\nprice: $5\nliteral: $$HOME\nbraced: ${USER}-${MISSING:-x}\n"
+
+	cfg, err := Load[map[string]any](WithBytes([]byte(raw)), lookup, WithBareVariables())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]any{
+		"data_dir": "/home/app/.cache",
+		"log":      "app.log",
+		"price":    "$5",
+		"literal":  "$HOME",
+		"braced":   "app-x",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %#v, want %#v", cfg, want)
+	}
+
+	cfg, err = Load[map[string]any](WithBytes([]byte("data_dir: $HOME/.cache\n")), lookup)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["data_dir"] != "$HOME/.cache" {
+		t.Fatalf("bare variables expanded without WithBareVariables: %#v", cfg["data_dir"])
+	}
+
+	_, err = Load[map[string]any](WithBytes([]byte("dir: /srv/$APP_NAME\n")), lookup, WithBareVariables(), WithStrict())
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) || missing.Vars[0].Name != "APP_NAME" || missing.Vars[0].Column != 11 {
+		t.Fatalf("expected *MissingEnvError for APP_NAME at column 11, got %v", err)
+	}
+}
+
+func TestLoad_OptionalVars(t *testing.T) {
+	env := map[string]string{"TOKEN": "t0k"}
+	lookup := WithEnvLookup(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+	raw := "token: ${?TOKEN}\nsentry: ${?SENTRY_DSN}\nfeature: ${FEATURE}\nnested: ${?A:-${B:-b}}\n"
+
+	var warnings []Warning
+	cfg, err := Load[map[string]any](WithBytes([]byte(raw)), lookup, WithStrict(), WithOptionalVars("FEATURE"),
+		WithWarnHandler(func(w Warning) { warnings = append(warnings, w) }))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]any{"token": "t0k", "sentry": nil, "feature": nil, "nested": "b"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %#v, want %#v", cfg, want)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("optional vars should not warn, got %v", warnings)
+	}
+
+	_, err = Load[map[string]any](WithBytes([]byte(raw)), lookup, WithStrict())
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) || len(missing.Vars) != 1 || missing.Vars[0].Name != "FEATURE" {
+		t.Fatalf("expected *MissingEnvError for FEATURE only, got %v", err)
+	}
+
+	_, err = Load[map[string]any](WithBytes([]byte("x: ${FEATURE:?needed}\n")), lookup, WithStrict(), WithOptionalVars("FEATURE"))
+	if !errors.As(err, &missing) {
+		t.Fatalf("${VAR:?message} must still fail for an optional var, got %v", err)
+	}
+
+	if out, err := Expand("a${?X}b${Y}", ExpandStrict(), ExpandOptional("Y"), ExpandLookup(func(string) (string, bool) { return "", false })); err != nil || out != "ab" {
+		t.Fatalf("Expand = %q, %v", out, err)
+	}
+}
+
+func TestLoad_WithMissingPolicy(t *testing.T) {
+	lookup := WithEnvLookup(func(name string) (string, bool) {
+		if name == "HOST" {
+			return "db", true
+		}
+		return "", false
+	})
+	raw := "url: \"${HOST}:${PORT}/${DB:-app}\"\nalert: Disk full on $HOSTNAME\nsentry: \"${?SENTRY_DSN}\"\n"
+
+	var warnings []Warning
+	cfg, err := Load[map[string]any](WithBytes([]byte(raw)), lookup, WithBareVariables(),
+		WithMissingPolicy(MissingKeep),
+		WithWarnHandler(func(w Warning) { warnings = append(warnings, w) }))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]any{"url": "db:${PORT}/app", "alert": "Disk full on $HOSTNAME", "sentry": ""}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %#v, want %#v", cfg, want)
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[0].Message, "left as is") {
+		t.Fatalf("expected 2 'left as is' warnings, got %v", warnings)
+	}
+
+	_, err = Load[map[string]any](WithBytes([]byte(raw)), lookup, WithMissingPolicy(MissingError))
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) || missing.Vars[0].Name != "PORT" {
+		t.Fatalf("expected *MissingEnvError for PORT, got %v", err)
+	}
+
+	cfg, err = Load[map[string]any](WithBytes([]byte(raw)), lookup, WithStrict(), WithMissingPolicy(MissingEmpty))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["url"] != "db:/app" {
+		t.Fatalf("url = %#v, want db:/app", cfg["url"])
+	}
+
+	out, err := Expand("${A}-${B:-b}", ExpandMissingPolicy(MissingKeep), ExpandLookup(func(string) (string, bool) { return "", false }))
+	if err != nil || out != "${A}-b" {
+		t.Fatalf("Expand = %q, %v", out, err)
+	}
+}
+
+func TestLoad_WithRecursiveExpansion(t *testing.T) {
+	env := map[string]string{
+		"DB_HOST": "db.internal",
+		"DB_ADDR": "${DB_HOST}:${DB_PORT:-5432}",
+		"DB_URL":  "postgres://${DB_ADDR}/app",
+		"A":       "${B}",
+		"B":       "x-${C}",
+		"C":       "${A}",
+		"BROKEN":  "${NOPE}",
+	}
+	lookup := WithEnvLookup(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+
+	cfg, err := Load[map[string]any](WithBytes([]byte("url: ${DB_URL}\n")), lookup, WithRecursiveExpansion())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["url"] != "postgres://db.internal:5432/app" {
+		t.Fatalf("got %#v", cfg["url"])
+	}
+
+	cfg, err = Load[map[string]any](WithBytes([]byte("url: ${DB_URL}\n")), lookup)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg["url"] != "postgres://${DB_ADDR}/app" {
+		t.Fatalf("env values expanded without WithRecursiveExpansion: %#v", cfg["url"])
+	}
+
+	_, err = Load[map[string]any](WithBytes([]byte("v: ${A}\n")), lookup, WithRecursiveExpansion())
+	var cycle *EnvCycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("expected *EnvCycleError, got %v", err)
+	}
+	if got := strings.Join(cycle.Chain, " -> "); got != "A -> B -> C -> A" {
+		t.Fatalf("chain = %s", got)
+	}
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || resolveErr.Path != "v" || resolveErr.Line != 1 {
+		t.Fatalf("expected the cycle located at v, got %v", err)
+	}
+
+	_, err = Load[map[string]any](WithBytes([]byte("v: ${BROKEN}\n")), lookup, WithRecursiveExpansion(), WithStrict())
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) || missing.Vars[0].Name != "NOPE" || missing.File != "env BROKEN" {
+		t.Fatalf("expected NOPE missing in BROKEN, got %v", err)
+	}
+
+	for i := 0; i <= maxEnvExpansionDepth; i++ {
+		env[fmt.Sprintf("L%d", i)] = fmt.Sprintf("${L%d}", i+1)
+	}
+	_, err = Load[map[string]any](WithBytes([]byte("v: ${L0}\n")), lookup, WithRecursiveExpansion())
+	if !errors.As(err, &cycle) || !cycle.TooDeep {
+		t.Fatalf("expected a too deep error, got %v", err)
+	}
+}
+
+func TestLoad_ResolversRunConcurrently(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		running, max int
+		calls        = make(map[string]int)
+	)
+	release := make(chan struct{})
+	vault := func(_ context.Context, key string) (string, error) {
+		mu.Lock()
+		calls[key]++
+		running++
+		if running > max {
+			max = running
+		}
+		if running == 3 {
+			close(release)
+		}
+		mu.Unlock()
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if key == "missing" {
+			return "", ErrNotFound
+		}
+		return "v-" + key, nil
+	}
+	raw := "a: ${vault:a}\nb: ${vault:b}\nc: ${vault:missing:-fallback}\nd: ${vault:a}\ne: ${UNSET:-${vault:lazy}}\n"
+
+	cfg, err := Load[map[string]any](WithBytes([]byte(raw)), WithResolver("vault", vault))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := map[string]any{"a": "v-a", "b": "v-b", "c": "fallback", "d": "v-a", "e": "v-lazy"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %#v, want %#v", cfg, want)
+	}
+	if max != 3 {
+		t.Fatalf("at most %d resolver calls ran at once, want 3", max)
+	}
+	if calls["a"] != 1 || calls["missing"] != 1 {
+		t.Fatalf("resolver calls = %v, want each key resolved once", calls)
+	}
+}
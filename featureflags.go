@@ -0,0 +1,157 @@
+// featureflags.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flags is a set of named on/off feature flags, usually a section of the
+// config:
+//
+//	type Config struct {
+//	    Features gonfig.Flags `yaml:"features"`
+//	}
+//
+// Each flag is either a plain bool or a mapping with a default and
+// per-environment values, where the environment is the WithProfile name:
+//
+//	features:
+//	  new_checkout: true
+//	  dark_mode:
+//	    enabled: false
+//	    environments:
+//	      staging: true
+//
+// After the file is loaded, an env var named after the flag's path (with
+// the WithEnvOverrides prefix, if any) wins over both, so a flag can be
+// switched off without touching the file: FEATURES_NEW_CHECKOUT=false.
+//
+// A Flags value is never modified once loaded. With NewLive or Watch, every
+// reload produces a new one, so live.Get().Features.Bool("new_checkout")
+// always reflects the latest file.
+type Flags struct {
+	specs map[string]flagSpec
+	on    map[string]bool
+}
+
+// flagSpec is the long form of a flag in YAML.
+type flagSpec struct {
+	Enabled      bool            `yaml:"enabled"`
+	Environments map[string]bool `yaml:"environments"`
+}
+
+// Bool reports whether the flag name is on. Unknown flags are off.
+func (f Flags) Bool(name string) bool {
+	return f.on[name]
+}
+
+// Names returns the names of all flags, sorted.
+func (f Flags) Names() []string {
+	names := make([]string, 0, len(f.on))
+	for name := range f.on {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (f *Flags) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: feature flags must be a mapping", n.Line)
+	}
+	f.specs = make(map[string]flagSpec, len(n.Content)/2)
+	f.on = make(map[string]bool, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		name, val := n.Content[i].Value, resolveAlias(n.Content[i+1])
+		var spec flagSpec
+		if val.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(val.Content); j += 2 {
+				if k := val.Content[j].Value; k != "enabled" && k != "environments" {
+					return fmt.Errorf("line %d: flag %s: unknown key %q", val.Content[j].Line, name, k)
+				}
+			}
+			if err := val.Decode(&spec); err != nil {
+				return fmt.Errorf("flag %s: %w", name, err)
+			}
+		} else if err := val.Decode(&spec.Enabled); err != nil {
+			return fmt.Errorf("flag %s: %w", name, err)
+		}
+		f.specs[name] = spec
+		f.on[name] = spec.Enabled
+	}
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler and emits the resolved value of
+// every flag, so Dump shows what is actually in effect.
+func (f Flags) MarshalYAML() (any, error) {
+	return f.on, nil
+}
+
+// resolveFlags walks v and settles every Flags value for the environment
+// env, then applies env var kill-switches looked up with lookup. Names are
+// derived like env overrides, from prefix and the flag's YAML path. record
+// is called with the path and env var name of every flag set from the env.
+// Flags inside lists and maps are left with their defaults.
+func resolveFlags(v reflect.Value, prefix, path, env string, lookup func(string) (string, bool), record func(path, name string)) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveFlags(v.Elem(), prefix, path, env, lookup, record)
+	case v.Type() == flagsType:
+		return v.Addr().Interface().(*Flags).resolve(prefix, path, env, lookup, record)
+	case v.Kind() != reflect.Struct || isLeafType(v.Type()):
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key, inline, skip := yamlFieldName(f)
+		if skip {
+			continue
+		}
+		fieldPath := path
+		if !inline {
+			fieldPath = joinPath(path, key)
+		}
+		if err := resolveFlags(v.Field(i), prefix, fieldPath, env, lookup, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Flags) resolve(prefix, path, env string, lookup func(string) (string, bool), record func(path, name string)) error {
+	name := envPrefix(prefix)
+	for _, seg := range splitPath(path) {
+		name = joinEnvName(name, seg)
+	}
+	for _, flag := range f.Names() {
+		spec := f.specs[flag]
+		if on, ok := spec.Environments[env]; ok && env != "" {
+			f.on[flag] = on
+		}
+		envName := joinEnvName(name, flag)
+		val, ok := lookup(envName)
+		if !ok {
+			continue
+		}
+		var on bool
+		if err := setFromString(reflect.ValueOf(&on).Elem(), val); err != nil {
+			return fmt.Errorf("feature flag %s: %w", envName, err)
+		}
+		f.on[flag] = on
+		record(joinPath(path, flag), envName)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package gonfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad_FeatureFlags(t *testing.T) {
+	type config struct {
+		AppName  string `yaml:"app_name"`
+		Features Flags  `yaml:"features"`
+	}
+	path := writeConfig(t, `app_name: svc
+features:
+  new_checkout: true
+  dark_mode:
+    enabled: false
+    environments:
+      staging: true
+  beta_api: ${BETA_API:-false}
+`)
+	env := map[string]string{}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	cfg, err := Load[config](WithConfigFile(path), WithEnvLookup(lookup))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.Features.Bool("new_checkout") || cfg.Features.Bool("dark_mode") || cfg.Features.Bool("beta_api") || cfg.Features.Bool("unknown") {
+		t.Fatalf("unexpected flags: %v", cfg.Features.on)
+	}
+	if got := strings.Join(cfg.Features.Names(), ","); got != "beta_api,dark_mode,new_checkout" {
+		t.Fatalf("Names() = %s", got)
+	}
+
+	cfg, err = Load[config](WithConfigFile(path), WithEnvLookup(lookup), WithProfile("staging"))
+	if err != nil || !cfg.Features.Bool("dark_mode") {
+		t.Fatalf("staging should enable dark_mode: %v, %v", cfg.Features.on, err)
+	}
+
+	// Kill-switches win over the file and the environment.
+	env["APP_FEATURES_NEW_CHECKOUT"] = "false"
+	env["APP_FEATURES_DARK_MODE"] = "false"
+	env["BETA_API"] = "true"
+	cfg, report, err := LoadWithReport[config](WithConfigFile(path), WithEnvLookup(lookup),
+		WithProfile("staging"), WithEnvOverrides("APP"))
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg.Features.Bool("new_checkout") || cfg.Features.Bool("dark_mode") || !cfg.Features.Bool("beta_api") {
+		t.Fatalf("unexpected flags: %v", cfg.Features.on)
+	}
+	if o := report.Origins["features.new_checkout"]; o.Kind != FromEnv || o.Vars[0] != "APP_FEATURES_NEW_CHECKOUT" {
+		t.Fatalf("unexpected origin: %+v", o)
+	}
+
+	env["APP_FEATURES_NEW_CHECKOUT"] = "maybe"
+	if _, err := Load[config](WithConfigFile(path), WithEnvLookup(lookup), WithEnvOverrides("APP")); err == nil ||
+		!strings.Contains(err.Error(), "APP_FEATURES_NEW_CHECKOUT") {
+		t.Fatalf("expected a kill-switch error, got %v", err)
+	}
+
+	path = writeConfig(t, "features:\n  dark_mode:\n    enable: true\n")
+	if _, err := Load[config](WithConfigFile(path)); err == nil || !strings.Contains(err.Error(), `unknown key "enable"`) {
+		t.Fatalf("expected an unknown key error, got %v", err)
+	}
+}
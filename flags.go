@@ -0,0 +1,178 @@
+// flags.go
+package gonfig
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// WithFlagSet registers a flag on fs for every config value of T, named
+// after its YAML path (-server.port, -server.log_level), and applies the
+// flags that were set on the command line on top of every other layer,
+// WithOverride included. Flags are registered when WithFlagSet is called,
+// so call it before fs.Parse; names fs already defines are left alone.
+//
+// Flag values are converted like env overrides: strings are taken
+// verbatim, everything else is decoded as YAML, so -server.port=9090 and
+// -hosts='[a, b]' work.
+//
+// Example:
+//
+//	configFlags := gonfig.WithFlagSet[Config](flag.CommandLine)
+//	flag.Parse()
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    configFlags,
+//	)
+func WithFlagSet[T any](fs *flag.FlagSet) Option {
+	var flags []*flagValue
+	for _, f := range configFlags(reflect.TypeOf((*T)(nil)).Elem(), "") {
+		if fs.Lookup(f.path) != nil {
+			continue
+		}
+		fs.Var(f, f.path, "config value "+f.path)
+		flags = append(flags, f)
+	}
+	return withFlags(flags)
+}
+
+// WithPFlagSet is WithFlagSet for github.com/spf13/pflag (and cobra):
+// flags are named --server.port, and boolean flags may be given without a
+// value.
+func WithPFlagSet[T any](fs *pflag.FlagSet) Option {
+	var flags []*flagValue
+	for _, f := range configFlags(reflect.TypeOf((*T)(nil)).Elem(), "") {
+		if fs.Lookup(f.path) != nil {
+			continue
+		}
+		pf := fs.VarPF(f, f.path, "", "config value "+f.path)
+		if f.IsBoolFlag() {
+			pf.NoOptDefVal = "true"
+		}
+		flags = append(flags, f)
+	}
+	return withFlags(flags)
+}
+
+func withFlags(flags []*flagValue) Option {
+	return func(l *loader) {
+		l.flags = append(l.flags, flags...)
+	}
+}
+
+// flagValue is the flag.Value (and pflag.Value) of a single config value.
+type flagValue struct {
+	path  string
+	typ   reflect.Type
+	value string
+	set   bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *flagValue) Set(s string) error {
+	// Check the value now so a typo fails flag parsing with usage.
+	if err := setFromString(reflect.New(f.typ).Elem(), s); err != nil {
+		return err
+	}
+	f.value, f.set = s, true
+	return nil
+}
+
+// IsBoolFlag lets -debug stand for -debug=true.
+func (f *flagValue) IsBoolFlag() bool { return f.typ.Kind() == reflect.Bool }
+
+// Type names the value in pflag usage output.
+func (f *flagValue) Type() string {
+	switch {
+	case f.typ == reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case f.typ.Kind() == reflect.Slice || f.typ.Kind() == reflect.Map:
+		return "yaml"
+	case f.typ.Kind() == reflect.Struct:
+		return "value"
+	}
+	return f.typ.Kind().String()
+}
+
+// configFlags returns a flag for every leaf value below the struct type t.
+// Lists and maps of scalars get a flag taking a YAML value; lists and maps
+// of structs have no flag.
+func configFlags(t reflect.Type, path string) []*flagValue {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var flags []*flagValue
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key, inline, skip := yamlFieldName(f)
+		if skip {
+			continue
+		}
+		fieldPath := path
+		if !inline {
+			fieldPath = joinPath(path, key)
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if _, ok := fieldDecoder(f); ok {
+			flags = append(flags, &flagValue{path: fieldPath, typ: reflect.TypeOf("")})
+			continue
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			if !isLeafType(ft) {
+				flags = append(flags, configFlags(ft, fieldPath)...)
+				continue
+			}
+		case reflect.Slice, reflect.Array, reflect.Map:
+			elem := ft.Elem()
+			for elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+			if !isLeafType(elem) {
+				continue
+			}
+		}
+		if !inline {
+			flags = append(flags, &flagValue{path: fieldPath, typ: ft})
+		}
+	}
+	return flags
+}
+
+// applyFlags sets the config values of every flag that was set on the
+// command line and calls record with their paths and flag names. Flags are
+// named after the fields of v, whose YAML path is root.
+func applyFlags(v reflect.Value, root string, flags []*flagValue, record func(path, name string)) error {
+	for _, f := range flags {
+		if !f.set {
+			continue
+		}
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.value}
+		if err := setPath(v, splitPath(f.path), n); err != nil {
+			return fmt.Errorf("flag %s: %w", f.path, err)
+		}
+		record(joinPath(root, f.path), f.path)
+	}
+	return nil
+}
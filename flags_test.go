@@ -0,0 +1,80 @@
+package gonfig
+
+import (
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+type flagTestConfig struct {
+	Server testServerConfig `yaml:"server"`
+	Debug  bool             `yaml:"debug"`
+	Hosts  []string         `yaml:"hosts"`
+	Cache  int64            `yaml:"cache" gonfig:"bytes"`
+	Peers  []struct {
+		Name string `yaml:"name"`
+	} `yaml:"peers"`
+}
+
+func TestLoad_WithFlagSet(t *testing.T) {
+	t.Setenv("APP_SERVER_PORT", "8081")
+	path := writeConfig(t, "server:\n  port: 8080\n  log_level: info\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("server.log_level", "", "defined by the app")
+	opt := WithFlagSet[flagTestConfig](fs)
+	if fs.Lookup("peers") != nil || fs.Lookup("server.timeout") == nil {
+		t.Fatalf("unexpected flag set")
+	}
+	if err := fs.Parse([]string{"-server.port=9090", "-debug", "-hosts=[a, b]", "-cache=1MiB", "-server.log_level=warn"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	cfg, report, err := LoadWithReport[flagTestConfig](
+		WithConfigFile(path),
+		WithEnvOverrides("APP"),
+		WithOverride("server.port", 9091),
+		opt,
+	)
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg.Server.Port != 9090 || !cfg.Debug || len(cfg.Hosts) != 2 || cfg.Cache != 1<<20 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Server.LogLevel != "info" {
+		t.Fatalf("flag defined by the app must not be applied, got %q", cfg.Server.LogLevel)
+	}
+	if got := report.Origins["server.port"].String(); got != "flag server.port" {
+		t.Fatalf("origin of server.port: got %q", got)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(new(strings.Builder))
+	WithFlagSet[flagTestConfig](fs)
+	if err := fs.Parse([]string{"-server.port=abc"}); err == nil {
+		t.Fatalf("expected an invalid flag value to fail parsing")
+	}
+}
+
+func TestLoad_WithPFlagSet(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opt := WithPFlagSet[flagTestConfig](fs)
+	if err := fs.Parse([]string{"--server.timeout", "5s", "--debug"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := fs.Lookup("server.timeout").Value.Type(); got != "duration" {
+		t.Fatalf("type of server.timeout: got %q", got)
+	}
+
+	cfg, err := Load[flagTestConfig](WithBytes([]byte("server:\n  timeout: 1s\n")), opt)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Timeout != 5*time.Second || !cfg.Debug {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
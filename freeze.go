@@ -0,0 +1,138 @@
+// freeze.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithFreeze guards the loaded config against being changed through shared
+// maps, slices and pointers, e.g. by one package appending to a list that
+// others read:
+//
+//   - the result of Load, LoadInto, Watch and NewLive is a deep copy,
+//     sharing nothing with values set in SetDefaults, AfterLoad or
+//     elsewhere;
+//   - Live.Get returns a deep copy of its own on every call, like
+//     Frozen.Get.
+//
+// Use Freeze to hand a config loaded without it around safely.
+func WithFreeze() Option {
+	return func(l *loader) {
+		l.freeze = true
+	}
+}
+
+// Frozen is a read-only accessor for a config: every Get returns a deep
+// copy, so callers can't change what other callers see.
+//
+// Built with -tags gonfig_debug, Get returns the shared config instead and
+// panics, naming the path, if it was changed since the previous Get. Use
+// that to find the code that mutates a config in tests.
+type Frozen[T any] struct {
+	cfg T
+	// snap is the copy that cfg is checked against in debug builds.
+	snap T
+}
+
+// Freeze returns a Frozen accessor for a deep copy of cfg.
+//
+// Example:
+//
+//	frozen := gonfig.Freeze(cfg)
+//	go worker(frozen) // worker calls frozen.Get()
+func Freeze[T any](cfg T) *Frozen[T] {
+	f := &Frozen[T]{cfg: copyOf(cfg)}
+	if debugFreeze {
+		f.snap = copyOf(cfg)
+	}
+	return f
+}
+
+// Get returns a deep copy of the config.
+func (f *Frozen[T]) Get() T {
+	if !debugFreeze {
+		return copyOf(f.cfg)
+	}
+	if changes := diff(reflect.ValueOf(f.snap), reflect.ValueOf(f.cfg)); len(changes) > 0 {
+		c := changes[0]
+		panic(fmt.Sprintf("gonfig: frozen config was modified: %s %s", c.Path, c.Kind))
+	}
+	return f.cfg
+}
+
+// copyOf returns a deep copy of v.
+func copyOf[T any](v T) T {
+	return deepCopy(reflect.ValueOf(&v).Elem()).Interface().(T)
+}
+
+// deepCopy returns a copy of v that shares no maps, slices or pointers with
+// it. Unexported fields, channels and funcs are copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	return copier{}.copy(v)
+}
+
+// copier maps the pointers it has copied to their copies, so shared and
+// cyclic pointers stay so in the copy.
+type copier map[copiedPointer]reflect.Value
+
+// copiedPointer keys copier by type as well as address: pointers to
+// different zero-size types, or to a struct and its first field, share an
+// address.
+type copiedPointer struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+func (c copier) copy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return out
+		}
+		key := copiedPointer{typ: v.Type(), addr: v.Pointer()}
+		if p, ok := c[key]; ok {
+			return p
+		}
+		p := reflect.New(v.Type().Elem())
+		c[key] = p
+		p.Elem().Set(c.copy(v.Elem()))
+		return p
+	case reflect.Interface:
+		if !v.IsNil() {
+			out.Set(c.copy(v.Elem()))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return out
+		}
+		out.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(c.copy(iter.Key()), c.copy(iter.Value()))
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return out
+		}
+		out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(c.copy(v.Index(i)))
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(c.copy(v.Index(i)))
+		}
+	case reflect.Struct:
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(c.copy(v.Field(i)))
+			}
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
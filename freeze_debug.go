@@ -0,0 +1,8 @@
+// freeze_debug.go
+
+//go:build gonfig_debug
+
+package gonfig
+
+// debugFreeze makes Frozen check for changes instead of copying.
+const debugFreeze = true
@@ -0,0 +1,23 @@
+// freeze_debug_test.go
+
+//go:build gonfig_debug
+
+package gonfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFreeze_DebugDetectsMutation(t *testing.T) {
+	frozen := Freeze(frozenConfig{Origins: []string{"a"}})
+	frozen.Get().Origins[0] = "changed"
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "origins[0]") {
+			t.Fatalf("panic = %q, want it to name origins[0]", msg)
+		}
+	}()
+	frozen.Get()
+}
@@ -0,0 +1,102 @@
+// freeze_test.go
+package gonfig
+
+import (
+	"testing"
+)
+
+var sharedOrigins = []string{"https://example.com"}
+
+type frozenConfig struct {
+	Origins []string          `yaml:"origins"`
+	Limits  map[string]int    `yaml:"limits"`
+	Server  *testServerConfig `yaml:"server"`
+}
+
+func (c *frozenConfig) SetDefaults() {
+	if c.Origins == nil {
+		c.Origins = sharedOrigins
+	}
+}
+
+func TestLoad_WithFreeze(t *testing.T) {
+	cfg, err := Load[frozenConfig](WithBytes([]byte("limits:\n  rps: 10\n")), WithFreeze())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	cfg.Origins[0] = "https://evil.example.com"
+	if sharedOrigins[0] != "https://example.com" {
+		t.Fatalf("frozen config shares its slice with SetDefaults")
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	if debugFreeze {
+		t.Skip("gonfig_debug builds share frozen configs")
+	}
+	cfg := frozenConfig{
+		Origins: []string{"a"},
+		Limits:  map[string]int{"rps": 10},
+		Server:  &testServerConfig{Port: 8080},
+	}
+	frozen := Freeze(cfg)
+	cfg.Origins[0] = "changed"
+
+	got := frozen.Get()
+	if got.Origins[0] != "a" {
+		t.Fatalf("Freeze didn't copy: Origins = %v", got.Origins)
+	}
+	got.Origins[0] = "b"
+	got.Limits["rps"] = 0
+	got.Server.Port = 1
+
+	again := frozen.Get()
+	if again.Origins[0] != "a" || again.Limits["rps"] != 10 || again.Server.Port != 8080 {
+		t.Fatalf("Get returned shared values: %+v, server %+v", again, *again.Server)
+	}
+}
+
+func TestLive_WithFreeze(t *testing.T) {
+	if debugFreeze {
+		t.Skip("gonfig_debug builds share frozen configs")
+	}
+	path := writeConfig(t, "origins: [a, b]\n")
+	live, err := NewLive[frozenConfig](t.Context(), WithConfigFile(path), WithFreeze())
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+	live.Get().Origins[0] = "changed"
+	if got := live.Get().Origins[0]; got != "a" {
+		t.Fatalf("Origins[0] = %q, want a", got)
+	}
+}
+
+type (
+	emptyA     struct{}
+	emptyB     struct{}
+	aliasedPtr struct {
+		Inner *innerConfig
+		Port  *int
+		A     *emptyA
+		B     *emptyB
+	}
+	innerConfig struct {
+		Port int
+	}
+)
+
+func (c *aliasedPtr) SetDefaults() {
+	c.Inner = &innerConfig{Port: 8080}
+	c.Port = &c.Inner.Port // same address as c.Inner, other type
+	c.A, c.B = &emptyA{}, &emptyB{}
+}
+
+func TestLoad_WithFreezeAliasedPointers(t *testing.T) {
+	cfg, err := Load[aliasedPtr](WithBytes([]byte("{}")), WithFreeze())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Inner.Port != 8080 || *cfg.Port != 8080 || cfg.A == nil || cfg.B == nil {
+		t.Fatalf("unexpected copy: %+v", cfg)
+	}
+}
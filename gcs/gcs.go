@@ -0,0 +1,107 @@
+// Package gcs provides a gonfig config source backed by Google Cloud
+// Storage.
+//
+// Credentials come from Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS,
+// gcloud auth, or the GCE/GKE metadata server).
+//
+// Usage:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gcs.WithGCS("gs://my-bucket/services/api/config.yaml"),
+//	)
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/TypeTerrors/gonfig"
+	"github.com/TypeTerrors/gonfig/internal/remote"
+)
+
+// WithGCS reads the config from a Cloud Storage object, e.g.
+// "gs://my-bucket/services/api/config.yaml". Pin a specific object
+// generation with "#<generation>".
+func WithGCS(uri string) gonfig.Option {
+	src, err := NewSource(nil, uri)
+	if err != nil {
+		return gonfig.WithSource(remote.ErrSource(err))
+	}
+	return gonfig.WithSource(src)
+}
+
+// Source fetches a config document from Cloud Storage.
+type Source struct {
+	Bucket     string
+	Object     string
+	Generation int64 // 0 means the live object
+
+	client  *storage.Client
+	clients remote.Client[*storage.Client]
+}
+
+// NewSource parses a gs://bucket/object[#generation] URI. If client is nil,
+// one is created on first Fetch from Application Default Credentials.
+func NewSource(client *storage.Client, uri string) (*Source, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("parse GCS URI %q: %w", uri, err)
+	}
+	object := strings.TrimPrefix(u.Path, "/")
+	if u.Scheme != "gs" || u.Host == "" || object == "" {
+		return nil, fmt.Errorf("invalid GCS URI %q (expected gs://bucket/object)", uri)
+	}
+	s := &Source{Bucket: u.Host, Object: object, client: client}
+	if u.Fragment != "" {
+		gen, err := strconv.ParseInt(u.Fragment, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GCS generation %q in %q", u.Fragment, uri)
+		}
+		s.Generation = gen
+	}
+	return s, nil
+}
+
+// Fetch implements gonfig.Source.
+func (s *Source) Fetch(ctx context.Context) ([]byte, error) {
+	client, err := s.clients.Get(func() (*storage.Client, error) {
+		if s.client != nil {
+			return s.client, nil
+		}
+		c, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create GCS client: %w", err)
+		}
+		return c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	obj := client.Bucket(s.Bucket).Object(s.Object)
+	if s.Generation != 0 {
+		obj = obj.Generation(s.Generation)
+	}
+	r, err := obj.NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, fmt.Errorf("get %s: %w: %w", s, fs.ErrNotExist, err)
+		}
+		return nil, fmt.Errorf("get %s: %w", s, err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// String implements fmt.Stringer so errors name the object.
+func (s *Source) String() string {
+	return "gs://" + s.Bucket + "/" + s.Object
+}
@@ -0,0 +1,22 @@
+package gcs
+
+import "testing"
+
+func TestNewSource(t *testing.T) {
+	s, err := NewSource(nil, "gs://bucket/svc/config.yaml#1700000000")
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	if s.Bucket != "bucket" || s.Object != "svc/config.yaml" || s.Generation != 1700000000 {
+		t.Fatalf("unexpected source: %+v", s)
+	}
+	if s.String() != "gs://bucket/svc/config.yaml" {
+		t.Fatalf("unexpected name %q", s.String())
+	}
+
+	for _, uri := range []string{"s3://bucket/key", "gs://bucket", "gs://bucket/key#abc"} {
+		if _, err := NewSource(nil, uri); err == nil {
+			t.Fatalf("expected error for %q", uri)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// global.go
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrGlobalSet is returned by SetGlobal when the global config of a type
+// has already been set.
+var ErrGlobalSet = errors.New("global config already set")
+
+var (
+	globalsMu sync.RWMutex
+	globals   = map[reflect.Type]any{}
+)
+
+// SetGlobal makes cfg the process-wide config of type T, for code deep in
+// the call stack that can't have it passed in. It can be set once; later
+// calls leave it unchanged and return ErrGlobalSet. Use OverrideGlobal in
+// tests.
+//
+// Example:
+//
+//	// main.go
+//	cfg, err := gonfig.Load[config.Config](gonfig.WithConfigFile("config.yaml"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := gonfig.SetGlobal(cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	// anywhere else
+//	timeout := gonfig.Global[config.Config]().Server.Timeout
+//
+// T is matched exactly: SetGlobal(cfg) and SetGlobal(&cfg) set different
+// globals. Store a *Live[T] to share a config that reloads.
+func SetGlobal[T any](cfg T) error {
+	t := reflect.TypeFor[T]()
+	globalsMu.Lock()
+	defer globalsMu.Unlock()
+	if _, ok := globals[t]; ok {
+		return fmt.Errorf("set global %s: %w", t, ErrGlobalSet)
+	}
+	globals[t] = cfg
+	return nil
+}
+
+// Global returns the config set with SetGlobal. It panics if none was set,
+// since that is a wiring mistake rather than a runtime condition; use
+// LookupGlobal to check first.
+func Global[T any]() T {
+	cfg, ok := LookupGlobal[T]()
+	if !ok {
+		panic(fmt.Sprintf("gonfig: Global[%s] called before SetGlobal", reflect.TypeFor[T]()))
+	}
+	return cfg
+}
+
+// LookupGlobal returns the config set with SetGlobal and whether one was
+// set.
+func LookupGlobal[T any]() (T, bool) {
+	globalsMu.RLock()
+	defer globalsMu.RUnlock()
+	cfg, ok := globals[reflect.TypeFor[T]()].(T)
+	return cfg, ok
+}
+
+// OverrideGlobal replaces the global config of type T for the duration of
+// a test, whether or not it was set, and restores it when the test ends.
+// tb is the test's *testing.T or *testing.B. Tests that override the same
+// type must not run in parallel.
+func OverrideGlobal[T any](tb interface{ Cleanup(func()) }, cfg T) {
+	t := reflect.TypeFor[T]()
+	globalsMu.Lock()
+	prev, had := globals[t]
+	globals[t] = cfg
+	globalsMu.Unlock()
+
+	tb.Cleanup(func() {
+		globalsMu.Lock()
+		defer globalsMu.Unlock()
+		if had {
+			globals[t] = prev
+		} else {
+			delete(globals, t)
+		}
+	})
+}
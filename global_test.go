@@ -0,0 +1,50 @@
+// global_test.go
+package gonfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGlobal(t *testing.T) {
+	type config struct{ Name string }
+
+	if _, ok := LookupGlobal[config](); ok {
+		t.Fatalf("LookupGlobal before SetGlobal reported a config")
+	}
+	t.Run("override", func(t *testing.T) {
+		OverrideGlobal(t, config{Name: "test"})
+		if got := Global[config]().Name; got != "test" {
+			t.Fatalf("Global = %q, want test", got)
+		}
+	})
+	if _, ok := LookupGlobal[config](); ok {
+		t.Fatalf("OverrideGlobal wasn't undone after the test")
+	}
+
+	if err := SetGlobal(config{Name: "first"}); err != nil {
+		t.Fatalf("SetGlobal: %v", err)
+	}
+	if err := SetGlobal(config{Name: "second"}); !errors.Is(err, ErrGlobalSet) {
+		t.Fatalf("second SetGlobal error = %v, want ErrGlobalSet", err)
+	}
+	if got := Global[config]().Name; got != "first" {
+		t.Fatalf("Global = %q, want first", got)
+	}
+	t.Run("override set", func(t *testing.T) {
+		OverrideGlobal(t, config{Name: "test"})
+		if got := Global[config]().Name; got != "test" {
+			t.Fatalf("Global = %q, want test", got)
+		}
+	})
+	if got := Global[config]().Name; got != "first" {
+		t.Fatalf("Global after override = %q, want first", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Global of an unset type didn't panic")
+		}
+	}()
+	Global[*config]()
+}
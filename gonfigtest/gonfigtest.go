@@ -0,0 +1,116 @@
+// Package gonfigtest provides helpers for testing code that loads config
+// with gonfig.
+//
+// Usage:
+//
+//	func TestConfig(t *testing.T) {
+//	    gonfigtest.WithEnv(t, map[string]string{"DB_PASSWORD": "secret"})
+//
+//	    cfg := gonfigtest.LoadFromString[Config](t, `
+//	database:
+//	  password: ${DB_PASSWORD}
+//	`)
+//	    gonfigtest.AssertGolden(t, cfg, "testdata/config.golden.yaml")
+//	}
+//
+// Run "go test -gonfigtest.update" to (re)write golden files.
+package gonfigtest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+var update = flag.Bool("gonfigtest.update", false, "rewrite gonfigtest golden files")
+
+// LoadFromString loads a config of type T from an inline YAML document and
+// fails the test if Load returns an error. opts are applied after the
+// document, so they can add dotenvs, strict mode and so on.
+func LoadFromString[T any](t testing.TB, doc string, opts ...gonfig.Option) T {
+	t.Helper()
+	opts = append([]gonfig.Option{gonfig.WithBytes([]byte(doc))}, opts...)
+	cfg, err := gonfig.Load[T](opts...)
+	if err != nil {
+		t.Fatalf("gonfig.Load: %v", err)
+	}
+	return cfg
+}
+
+// WithEnv sets env vars for the duration of the test and restores them
+// afterwards, like t.Setenv. It can't be used in parallel tests; use
+// gonfig.WithEnvLookup there instead.
+func WithEnv(t testing.TB, vars map[string]string) {
+	t.Helper()
+	for k, v := range vars {
+		t.Setenv(k, v)
+	}
+}
+
+// ConfigFile writes doc to a config.yaml in a temporary directory and
+// returns its path, for code that takes a config path.
+func ConfigFile(t testing.TB, doc string) string {
+	t.Helper()
+	return writeTemp(t, "config.yaml", []byte(doc))
+}
+
+// Dotenv writes vars to a .env file in a temporary directory and returns
+// its path, for use with gonfig.WithDotenv.
+func Dotenv(t testing.TB, vars map[string]string) string {
+	t.Helper()
+	data, err := godotenv.Marshal(vars)
+	if err != nil {
+		t.Fatalf("marshal dotenv: %v", err)
+	}
+	return writeTemp(t, ".env", []byte(data+"\n"))
+}
+
+// AssertGolden compares the resolved config, marshalled as YAML, with the
+// golden file at path and fails the test on a mismatch. With
+// -gonfigtest.update the golden file is written instead.
+func AssertGolden(t testing.TB, cfg any, path string) {
+	t.Helper()
+	got, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("golden file %s does not exist; run go test -gonfigtest.update to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("config does not match %s (run go test -gonfigtest.update to accept)\n--- got:\n%s\n--- want:\n%s", path, got, want)
+	}
+}
+
+func writeTemp(t testing.TB, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
@@ -0,0 +1,43 @@
+package gonfigtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+type config struct {
+	Server struct {
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	} `yaml:"server"`
+}
+
+func TestLoadFromString(t *testing.T) {
+	WithEnv(t, map[string]string{"GONFIGTEST_PORT": "8080"})
+	dotenv := Dotenv(t, map[string]string{"GONFIGTEST_HOST": "db.local"})
+
+	cfg := LoadFromString[config](t, "server:\n  host: ${GONFIGTEST_HOST}\n  port: ${GONFIGTEST_PORT}\n",
+		gonfig.WithDotenv(dotenv),
+	)
+	if cfg.Server.Host != "db.local" || cfg.Server.Port != 8080 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	path := ConfigFile(t, "server:\n  port: 9090\n")
+	cfg, err := gonfig.Load[config](gonfig.WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Fatalf("expected port 9090, got %d", cfg.Server.Port)
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	cfg := LoadFromString[config](t, "server:\n  host: localhost\n  port: 8080\n")
+	AssertGolden(t, cfg, filepath.Join("testdata", "config.golden.yaml"))
+}
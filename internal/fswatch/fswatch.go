@@ -0,0 +1,55 @@
+// Package fswatch watches directories with fsnotify, for gonfig's file
+// sources and the CUE and Jsonnet sources.
+package fswatch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Dirs watches dirs and calls changed for every event on a file for
+// which match returns true. It stops when ctx is done.
+func Dirs(ctx context.Context, dirs []string, match func(name string) bool, changed func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create file watcher: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return fmt.Errorf("watch %s: %w", dir, err)
+		}
+		seen[dir] = true
+	}
+
+	go func() {
+		defer w.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if match(ev.Name) {
+					changed()
+				}
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				// Usually an event queue overflow: changes may have been
+				// dropped, so reload to be safe.
+				changed()
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package kvtree turns the keys under a prefix of a key/value store into a
+// YAML document, for the Consul and etcd sources.
+package kvtree
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pair is a single key and its value.
+type Pair struct {
+	Key   string
+	Value []byte
+}
+
+// Marshal builds a YAML mapping from the pairs under prefix, splitting keys
+// on "/": "app/server/port" under prefix "app/" becomes server.port. Keys
+// outside prefix and folder entries (keys ending in "/") are skipped.
+//
+// Values are left untagged so they are typed like hand-written YAML when the
+// config is decoded, e.g. "8080" decodes into an int field.
+func Marshal(prefix string, pairs []Pair) ([]byte, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	root := map[string]any{}
+	for _, p := range pairs {
+		rel, ok := strings.CutPrefix(p.Key, prefix)
+		if !ok || rel == "" || strings.HasSuffix(rel, "/") {
+			continue
+		}
+		parts := strings.Split(rel, "/")
+		m := root
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]any)
+			if !ok {
+				if _, exists := m[part]; exists {
+					return nil, fmt.Errorf("key %s: %s is both a value and a folder", p.Key, part)
+				}
+				child = map[string]any{}
+				m[part] = child
+			}
+			m = child
+		}
+		leaf := parts[len(parts)-1]
+		if _, exists := m[leaf]; exists {
+			return nil, fmt.Errorf("key %s: %s is both a value and a folder", p.Key, leaf)
+		}
+		m[leaf] = string(p.Value)
+	}
+	return yaml.Marshal(mappingNode(root))
+}
+
+func mappingNode(m map[string]any) *yaml.Node {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range keys {
+		var v *yaml.Node
+		switch val := m[k].(type) {
+		case map[string]any:
+			v = mappingNode(val)
+		case string:
+			v = &yaml.Node{Kind: yaml.ScalarNode, Value: val}
+		}
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: k}, v)
+	}
+	return n
+}
@@ -0,0 +1,43 @@
+// Package remote holds what the S3, GCS, Azure Blob, Consul and etcd
+// sources share.
+package remote
+
+import (
+	"context"
+	"sync"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// ErrSource returns a source whose Fetch fails with err, so an option can
+// report a configuration error (e.g. a malformed URI) from Load.
+func ErrSource(err error) gonfig.Source {
+	return errSource{err}
+}
+
+type errSource struct{ err error }
+
+func (e errSource) Fetch(context.Context) ([]byte, error) { return nil, e.err }
+
+// Client is a client created on first use. Unlike a sync.Once, it only
+// keeps a client that was created successfully, so a source whose
+// credentials weren't available yet retries on the next Fetch.
+type Client[T any] struct {
+	mu     sync.Mutex
+	client T
+	ok     bool
+}
+
+// Get returns the client, calling create if there is none yet.
+func (c *Client[T]) Get(create func() (T, error)) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.ok {
+		client, err := create()
+		if err != nil {
+			return client, err
+		}
+		c.client, c.ok = client, true
+	}
+	return c.client, nil
+}
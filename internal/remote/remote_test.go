@@ -0,0 +1,30 @@
+package remote
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClient_RetriesFailedCreation(t *testing.T) {
+	var c Client[string]
+	calls := 0
+	create := func() (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errors.New("no credentials yet")
+		}
+		return "client", nil
+	}
+
+	if _, err := c.Get(create); err == nil {
+		t.Fatal("expected the first creation to fail")
+	}
+	for range 2 {
+		if got, err := c.Get(create); err != nil || got != "client" {
+			t.Fatalf("Get = %q, %v", got, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected the client to be created once it succeeded, got %d calls", calls)
+	}
+}
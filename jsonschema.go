@@ -0,0 +1,165 @@
+// jsonschema.go
+package gonfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// WithSchema validates the config document against a JSON Schema (written
+// as JSON or YAML) before it is decoded, for example one produced by
+// Schema. Placeholders are expanded and all layers merged first, so the
+// schema sees the values T will get. Every violation is reported in a
+// *SchemaError, not just the first one.
+//
+// Go's decoding is lenient (unknown keys, a missing section) and Validate()
+// only sees the decoded struct, so a schema catches structural mistakes
+// that would otherwise go unnoticed.
+//
+// Example:
+//
+//	//go:embed config.schema.json
+//	var schema []byte
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithSchema(schema),
+//	)
+func WithSchema(schema []byte) Option {
+	return func(l *loader) {
+		l.schema = func() ([]byte, error) { return schema, nil }
+	}
+}
+
+// WithSchemaFile is WithSchema with the schema read from path when the
+// config is loaded.
+func WithSchemaFile(path string) Option {
+	return func(l *loader) {
+		l.schema = func() ([]byte, error) { return os.ReadFile(path) }
+	}
+}
+
+// SchemaError is returned by Load when the config document doesn't match
+// the schema set with WithSchema.
+//
+//	config.yaml does not match schema: /server/port (3:9): minimum: got 0, want 1
+type SchemaError struct {
+	// File is the config source, e.g. "config.yaml".
+	File       string
+	Violations []SchemaViolation
+}
+
+// SchemaViolation is a single schema violation.
+type SchemaViolation struct {
+	// Pointer is the JSON pointer of the offending value, e.g.
+	// "/server/port", or "" for the whole document.
+	Pointer string
+	// Line and Column locate the value in the config file, if known.
+	Line   int
+	Column int
+	// Message says what is wrong, e.g. "missing property 'name'".
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	ptr := v.Pointer
+	if ptr == "" {
+		ptr = "/"
+	}
+	if v.Line > 0 {
+		ptr += fmt.Sprintf(" (%d:%d)", v.Line, v.Column)
+	}
+	return ptr + ": " + v.Message
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("%s does not match schema: %s", e.File, strings.Join(msgs, "; "))
+}
+
+// checkSchema validates doc against the WithSchema schema, if any.
+func (l *loader) checkSchema(doc *yaml.Node) error {
+	if l.schema == nil {
+		return nil
+	}
+	raw, err := l.schema()
+	if err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+	var schemaDoc any
+	if err := yaml.Unmarshal(raw, &schemaDoc); err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+	schemaJSON, err := toJSONValue(schemaDoc)
+	if err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("schema.json", schemaJSON); err != nil {
+		return fmt.Errorf("load schema: %w", err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		return fmt.Errorf("compile schema: %w", err)
+	}
+
+	var v any
+	if doc.Kind != 0 {
+		if err := doc.Decode(&v); err != nil {
+			return &ParseError{File: l.configFile, Err: err}
+		}
+	}
+	instance, err := toJSONValue(v)
+	if err != nil {
+		return &ParseError{File: l.configFile, Err: err}
+	}
+
+	err = sch.Validate(instance)
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+	schemaErr := &SchemaError{File: l.configFile}
+	for _, unit := range verr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		violation := SchemaViolation{Pointer: unit.InstanceLocation, Message: unit.Error.String()}
+		if n := nodeAt(doc, pointerSegs(unit.InstanceLocation)); n != nil && n.Line > 0 {
+			violation.Line, violation.Column = n.Line, n.Column
+		}
+		schemaErr.Violations = append(schemaErr.Violations, violation)
+	}
+	return schemaErr
+}
+
+// toJSONValue converts a decoded YAML value to the plain JSON types the
+// schema validator expects.
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
+
+// pointerSegs splits a JSON pointer into unescaped segments.
+func pointerSegs(ptr string) []string {
+	if ptr == "" {
+		return nil
+	}
+	segs := strings.Split(strings.TrimPrefix(ptr, "/"), "/")
+	for i, s := range segs {
+		segs[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+	}
+	return segs
+}
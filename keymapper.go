@@ -0,0 +1,134 @@
+// keymapper.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithKeyMapper makes config keys match struct fields by mapper(key), so
+// files written in different naming conventions load into the same struct
+// without extra yaml tags. A key that isn't a field's key is renamed to
+// the field whose key maps to the same string, aliases included:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithKeyMapper(func(key string) string {
+//	        return strings.ToLower(key)
+//	    }),
+//	)
+//
+// Keys of map fields are data and are left alone. Two keys in one mapping
+// that map to the same field fail the load with a *ParseError.
+func WithKeyMapper(mapper func(key string) string) Option {
+	return func(l *loader) {
+		l.keyMapper = mapper
+	}
+}
+
+// WithCaseInsensitiveKeys is WithKeyMapper(FoldKey): serverPort,
+// server_port, server-port and SERVER_PORT all load into a field with the
+// key server_port, or an untagged ServerPort field.
+func WithCaseInsensitiveKeys() Option {
+	return WithKeyMapper(FoldKey)
+}
+
+// FoldKey lower-cases key and drops underscores, dashes and spaces, so
+// camelCase, snake_case, kebab-case and SCREAMING_SNAKE_CASE spellings of a
+// key fold to the same string.
+func FoldKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r == ' ' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, key)
+}
+
+// mapKeys walks n alongside the type t it will be decoded into and renames
+// keys to the field key (or alias) they match by mapper.
+func mapKeys(n *yaml.Node, t reflect.Type, mapper func(string) string) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			if err := mapKeys(c, t, mapper); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.AliasNode:
+		return mapKeys(n.Alias, t, mapper)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode || isLeafType(t) {
+			return nil
+		}
+		fields, _ := structFields(t)
+		keys := make(map[string]string)
+		for key, f := range fields {
+			keys[mapper(key)] = key
+			for _, alias := range tagOptions(f, "alias") {
+				keys[mapper(alias)] = alias
+			}
+		}
+		// seen holds the original spelling and line of every key by the
+		// key it was renamed to.
+		type spelling struct {
+			key  string
+			line int
+		}
+		seen := make(map[string]spelling)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				if err := mapKeys(v, t, mapper); err != nil {
+					return err
+				}
+				continue
+			}
+			orig := k.Value
+			if _, ok := fields[k.Value]; !ok {
+				if key, ok := keys[mapper(k.Value)]; ok {
+					k.Value = key
+				}
+			}
+			if prev, ok := seen[k.Value]; ok {
+				return fmt.Errorf("line %d: %s and %s (line %d) are both %s", k.Line, orig, prev.key, prev.line, k.Value)
+			}
+			seen[k.Value] = spelling{key: orig, line: k.Line}
+			if f, ok := fields[k.Value]; ok {
+				if err := mapKeys(v, f.Type, mapper); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if err := mapKeys(n.Content[i+1], t.Elem(), mapper); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, item := range n.Content {
+			if err := mapKeys(item, t.Elem(), mapper); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
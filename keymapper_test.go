@@ -0,0 +1,56 @@
+// keymapper_test.go
+package gonfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoad_WithCaseInsensitiveKeys(t *testing.T) {
+	type tls struct {
+		CertFile string `yaml:"cert_file"`
+	}
+	type config struct {
+		AppName    string            `yaml:"app_name"`
+		ServerPort int               // key "serverport"
+		LogLevel   string            `yaml:"log_level" gonfig:"alias=verbosity"`
+		TLS        tls               `yaml:"tls"`
+		Labels     map[string]string `yaml:"labels"`
+	}
+	base := writeConfig(t, "appName: api\nSERVER_PORT: 8080\nTLS:\n  cert-file: /etc/tls.crt\nlabels:\n  teamName: core\n")
+	profile := strings.TrimSuffix(base, ".yaml") + ".prod.yaml"
+	replaceFile(t, profile, "server_port: 9090\nVERBOSITY: debug\n")
+
+	cfg, err := Load[config](WithConfigFile(base), WithProfile("prod"), WithCaseInsensitiveKeys(), WithKnownFieldsOnly())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "api" || cfg.ServerPort != 9090 || cfg.LogLevel != "debug" || cfg.TLS.CertFile != "/etc/tls.crt" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Labels["teamName"] != "core" {
+		t.Fatalf("map keys must be left alone: %v", cfg.Labels)
+	}
+
+	path := writeConfig(t, "app_name: a\nappName: b\n")
+	_, err = Load[config](WithConfigFile(path), WithCaseInsensitiveKeys())
+	var pe *ParseError
+	if !errors.As(err, &pe) || !strings.Contains(err.Error(), "line 2: appName and app_name (line 1) are both app_name") {
+		t.Fatalf("expected a ParseError for the clashing keys, got %v", err)
+	}
+
+	custom := WithKeyMapper(func(key string) string { return strings.TrimPrefix(strings.ToLower(key), "x-") })
+	cfg, err = Load[config](WithBytes([]byte("X-App_Name: api\n")), custom)
+	if err != nil || cfg.AppName != "api" {
+		t.Fatalf("custom mapper: %+v, %v", cfg, err)
+	}
+}
+
+func TestFoldKey(t *testing.T) {
+	for _, key := range []string{"serverPort", "server_port", "SERVER_PORT", "server-port", "ServerPort"} {
+		if got := FoldKey(key); got != "serverport" {
+			t.Fatalf("FoldKey(%q) = %q", key, got)
+		}
+	}
+}
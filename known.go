@@ -0,0 +1,138 @@
+// known.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unknownKeys walks a YAML node alongside the Go type it will be decoded into
+// and returns a WarnUnknownKey warning for every mapping key that has no
+// matching field. file names the config file a key node came from.
+func unknownKeys(n *yaml.Node, t reflect.Type, path string, file func(*yaml.Node) string) []Warning {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return unknownKeys(n.Content[0], t, path, file)
+	}
+	if n.Kind == yaml.AliasNode {
+		return unknownKeys(n.Alias, t, path, file)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var out []Warning
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode || isLeafType(t) {
+			return nil
+		}
+		fields, open := structFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				out = append(out, unknownKeys(v, t, path, file)...)
+				continue
+			}
+			childPath := joinPath(path, k.Value)
+			f, ok := fields[k.Value]
+			if !ok {
+				if !open {
+					out = append(out, Warning{
+						Kind:    WarnUnknownKey,
+						File:    file(k),
+						Path:    childPath,
+						Line:    k.Line,
+						Column:  k.Column,
+						Message: "unknown key",
+					})
+				}
+				continue
+			}
+			out = append(out, unknownKeys(v, f.Type, childPath, file)...)
+		}
+	case reflect.Interface:
+		if n.Kind != yaml.MappingNode || !hasVariants(t) {
+			return nil
+		}
+		if vt, key, err := variantType(t, n); err == nil {
+			return unknownKeys(withoutKey(n, key), vt, path, file)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			out = append(out, unknownKeys(n.Content[i+1], t.Elem(), joinPath(path, n.Content[i].Value), file)...)
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, item := range n.Content {
+			out = append(out, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), file)...)
+		}
+	}
+	return out
+}
+
+// structFields maps the YAML keys of a struct (including inlined structs) to
+// their fields. open reports whether an inlined map accepts any key.
+func structFields(t reflect.Type) (fields map[string]reflect.StructField, open bool) {
+	fields = make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key, inline, skip := yamlFieldName(f)
+		if skip {
+			continue
+		}
+		if inline {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() {
+			case reflect.Struct:
+				inner, innerOpen := structFields(ft)
+				for k, v := range inner {
+					fields[k] = v
+				}
+				open = open || innerOpen
+			case reflect.Map:
+				open = true
+			}
+			continue
+		}
+		fields[key] = f
+	}
+	return fields, open
+}
+
+// joinPath appends a key to a dotted YAML path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// unknownKeysError formats the result of unknownKeys as a single error,
+// e.g. "unknown config keys: server.prot (line 3, column 3)".
+func unknownKeysError(keys []Warning) error {
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = fmt.Sprintf("%s (line %d, column %d)", k.Path, k.Line, k.Column)
+	}
+	return fmt.Errorf("unknown config keys: %s", strings.Join(s, ", "))
+}
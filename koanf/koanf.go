@@ -0,0 +1,91 @@
+// Package koanf connects gonfig and koanf, for codebases moving from one
+// to the other a package at a time.
+//
+// Serve a gonfig config to code that still reads koanf:
+//
+//	import gonfigkoanf "github.com/TypeTerrors/gonfig/koanf"
+//
+//	view, err := gonfig.LoadView(gonfig.WithConfigFile("config.yaml"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	k := koanf.New(".")
+//	if err := k.Load(gonfigkoanf.NewProvider(view), nil); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Or load a gonfig struct from an existing koanf instance:
+//
+//	cfg, err := gonfig.Load[Config](gonfigkoanf.WithKoanf(k))
+package koanf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/knadh/koanf/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// Provider is a koanf.Provider serving a gonfig View.
+type Provider struct {
+	view gonfig.View
+}
+
+var _ koanf.Provider = (*Provider)(nil)
+
+// NewProvider returns a provider of view's values. Use a View from
+// gonfig.LoadView to pass secrets through as they are; one from
+// gonfig.NewView has them masked.
+func NewProvider(view gonfig.View) *Provider {
+	return &Provider{view: view}
+}
+
+// Read returns the config as a nested map.
+func (p *Provider) Read() (map[string]any, error) {
+	v, ok := p.view.Get("")
+	if !ok {
+		return map[string]any{}, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("gonfig config is a %T, not a mapping", v)
+	}
+	return m, nil
+}
+
+// ReadBytes returns the config as a YAML document, for use with koanf's
+// YAML parser.
+func (p *Provider) ReadBytes() ([]byte, error) {
+	m, err := p.Read()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(m)
+}
+
+// Source is a gonfig.Source reading the config held by a koanf instance.
+type Source struct {
+	k *koanf.Koanf
+}
+
+// NewSource returns a source of k's config as it is at each fetch.
+func NewSource(k *koanf.Koanf) Source {
+	return Source{k: k}
+}
+
+// WithKoanf reads the config from k, like gonfig.WithSource(NewSource(k)).
+// Placeholders in its values are expanded, and env overrides, defaults and
+// validation apply as usual.
+func WithKoanf(k *koanf.Koanf) gonfig.Option {
+	return gonfig.WithSource(NewSource(k))
+}
+
+// Fetch returns k's config as a YAML document.
+func (s Source) Fetch(context.Context) ([]byte, error) {
+	return yaml.Marshal(s.k.Raw())
+}
+
+func (s Source) String() string { return "koanf" }
@@ -0,0 +1,51 @@
+package koanf
+
+import (
+	"testing"
+
+	"github.com/knadh/koanf/v2"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+type config struct {
+	Server struct {
+		Port int    `yaml:"port"`
+		Host string `yaml:"host" default:"localhost"`
+	} `yaml:"server"`
+	Password gonfig.Secret `yaml:"password"`
+}
+
+func TestProvider(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+	view, err := gonfig.LoadView(gonfig.WithBytes([]byte("server:\n  port: 8080\npassword: ${DB_PASSWORD}\n")))
+	if err != nil {
+		t.Fatalf("LoadView: %v", err)
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(NewProvider(view), nil); err != nil {
+		t.Fatalf("koanf Load: %v", err)
+	}
+	if got := k.Int("server.port"); got != 8080 {
+		t.Fatalf("server.port = %d, want 8080", got)
+	}
+	if got := k.String("password"); got != "hunter2" {
+		t.Fatalf("password = %q, want hunter2", got)
+	}
+}
+
+func TestWithKoanf(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+	k := koanf.New(".")
+	k.Set("server.port", 9090)
+	k.Set("password", "${DB_PASSWORD}")
+
+	cfg, err := gonfig.Load[config](WithKoanf(k))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 9090 || cfg.Server.Host != "localhost" || cfg.Password.Value() != "hunter2" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
@@ -0,0 +1,122 @@
+// layers.go
+package gonfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// layer is one config document. Layers are parsed and expanded one by one
+// and then merged in order, later layers winning.
+type layer struct {
+	src Source
+	// name identifies the layer in errors, warnings and origins.
+	name string
+	// optional layers are skipped when they don't exist.
+	optional bool
+	// verify layers are checked against WithSHA256 and WithSignature.
+	verify bool
+}
+
+// layers returns the config layers to load: the config source (one layer
+// per file for WithConfigDir), followed by its profile file when
+// WithProfile is set. LoadDir loads tenants with tenantLayers instead.
+func (l *loader) layers() ([]layer, error) {
+	if l.tenant != "" {
+		return l.tenantLayers(), nil
+	}
+	var layers []layer
+	if dir, ok := l.source.(dirSource); ok {
+		if l.sha256 != "" || l.signature != nil {
+			return nil, fmt.Errorf("verify %s: WithSHA256 and WithSignature need a single config document, not a directory", dir)
+		}
+		files, err := dir.files()
+		if err != nil {
+			return nil, fmt.Errorf("read config dir %s: %w", dir, err)
+		}
+		for _, f := range files {
+			layers = append(layers, layer{src: fileSource(f), name: f})
+		}
+	} else {
+		layers = append(layers, layer{src: l.source, name: l.configFile, verify: true})
+	}
+	if ly, ok := l.profileLayer(); ok {
+		layers = append(layers, ly)
+	}
+	return layers, nil
+}
+
+// profileLayer returns the optional profile layer, if there is one.
+func (l *loader) profileLayer() (layer, bool) {
+	if l.profile == "" {
+		return layer{}, false
+	}
+	src, ok := profileSource(l.source, l.profile)
+	if !ok {
+		return layer{}, false
+	}
+	return layer{src: src, name: sourceName(src), optional: true}, true
+}
+
+// dotenvFile is a dotenv file added with WithDotenv or
+// WithDotenvNoOverride.
+type dotenvFile struct {
+	path string
+	// keepEnv leaves variables that are set in the real environment alone.
+	keepEnv bool
+}
+
+// dotenvLayers returns the dotenv files to load in order. With a profile,
+// every file is followed by its profile variant: .env, .env.prod.
+func (l *loader) dotenvLayers() []dotenvFile {
+	files := make([]dotenvFile, 0, 2*len(l.dotenvs))
+	for _, d := range l.dotenvs {
+		d.keepEnv = d.keepEnv || l.dotenvKeepEnv
+		files = append(files, d)
+		if l.profile != "" {
+			files = append(files, dotenvFile{path: d.path + "." + l.profile, keepEnv: d.keepEnv})
+		}
+	}
+	return files
+}
+
+// dotenvPaths returns the paths of dotenvLayers.
+func (l *loader) dotenvPaths() []string {
+	var paths []string
+	for _, d := range l.dotenvLayers() {
+		paths = append(paths, d.path)
+	}
+	return paths
+}
+
+// profileSource returns the profile variant of a file-backed source, e.g.
+// config.prod.yaml for config.yaml. Other sources have no profile variant.
+func profileSource(src Source, profile string) (Source, bool) {
+	switch s := src.(type) {
+	case fileSource:
+		return fileSource(profilePath(string(s), profile)), true
+	case fsSource:
+		return fsSource{fsys: s.fsys, path: profilePath(s.path, profile)}, true
+	}
+	return nil, false
+}
+
+// profilePath inserts the profile before the extension of path:
+// config.yaml becomes config.prod.yaml and config.yaml.age becomes
+// config.prod.yaml.age (likewise for ".enc").
+func profilePath(path, profile string) string {
+	base, encrypted := cutEncryptedSuffix(path)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + profile + ext + encrypted
+}
+
+// fileOf names the config layer a node of the merged document came from.
+func (l *loader) fileOf(n *yaml.Node) string {
+	if name, ok := l.nodeFile[n]; ok {
+		return name
+	}
+	return l.configFile
+}
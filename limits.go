@@ -0,0 +1,180 @@
+// limits.go
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limits bounds the resources a single config document may use, for
+// loading untrusted YAML such as customer-supplied files. A zero field
+// means no limit. See WithLimits.
+type Limits struct {
+	// MaxBytes is the largest raw document, after decryption.
+	MaxBytes int
+	// MaxDepth is the deepest nesting of mappings and lists; a top-level
+	// mapping of scalars has depth 1.
+	MaxDepth int
+	// MaxAliasExpansion is how many times larger the document may get when
+	// its aliases (*name) are expanded, as a factor of its node count.
+	// The classic "billion laughs" document expands exponentially; a
+	// handful of shared defaults stays well under 2.
+	MaxAliasExpansion float64
+}
+
+// ErrLimitExceeded is wrapped by the errors of documents that exceed the
+// WithLimits limits.
+var ErrLimitExceeded = errors.New("config limit exceeded")
+
+// WithLimits makes Load reject config documents that exceed lim, with a
+// *ParseError wrapping ErrLimitExceeded, before decoding them. Every layer
+// is checked on its own.
+//
+// Limits bound the size of a document, not what it can reach: placeholders
+// in an untrusted document could still read the process's env vars and
+// files (${DB_PASSWORD}, ${file:/etc/shadow}) or call resolvers. Add
+// WithoutExpansion, and don't use WithTemplating, for such documents.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[TenantConfig](
+//	    gonfig.WithBytes(upload),
+//	    gonfig.WithoutExpansion(),
+//	    gonfig.WithLimits(gonfig.Limits{
+//	        MaxBytes:          1 << 20,
+//	        MaxDepth:          32,
+//	        MaxAliasExpansion: 10,
+//	    }),
+//	)
+//	if errors.Is(err, gonfig.ErrLimitExceeded) {
+//	    http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+//	}
+func WithLimits(lim Limits) Option {
+	return func(l *loader) {
+		l.limits = lim
+	}
+}
+
+// WithoutExpansion loads config values as they are written: ${VAR},
+// ${file:...} and other placeholders are left in place, no resolver is
+// called, and when: conditions (WithConditionalSections) compare their
+// operands literally. Use it for untrusted documents, so they can't read
+// the process's env vars, files or secrets into values that are echoed
+// back. Env overrides (WithEnvOverrides) and defaults still apply.
+func WithoutExpansion() Option {
+	return func(l *loader) {
+		l.noExpansion = true
+	}
+}
+
+// WithMaxConfigSize rejects config documents larger than bytes, like
+// Limits.MaxBytes. Files, readers and URLs stop being read as soon as they
+// pass the limit, so an accidentally huge file or a misbehaving server
+// fails fast with an error wrapping ErrLimitExceeded instead of exhausting
+// memory.
+func WithMaxConfigSize(bytes int) Option {
+	return func(l *loader) {
+		l.limits.MaxBytes = bytes
+	}
+}
+
+// limitedFetcher is implemented by sources that can stop reading a document
+// once it is larger than max bytes.
+type limitedFetcher interface {
+	fetchLimited(ctx context.Context, max int64) ([]byte, error)
+}
+
+// readLimited reads r to the end, failing once it has read more than max
+// bytes. A max of zero means no limit.
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	if max <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, tooLarge(max)
+	}
+	return data, nil
+}
+
+// tooLarge is the error for a document over the limit of max bytes whose
+// full size isn't known.
+func tooLarge(max int64) error {
+	return fmt.Errorf("%w: document is over the limit of %d bytes", ErrLimitExceeded, max)
+}
+
+// checkSize checks the size of a raw document against lim.
+func (lim Limits) checkSize(raw []byte) error {
+	if lim.MaxBytes > 0 && len(raw) > lim.MaxBytes {
+		return fmt.Errorf("%w: document is %d bytes, over the limit of %d", ErrLimitExceeded, len(raw), lim.MaxBytes)
+	}
+	return nil
+}
+
+// checkTree checks the nesting depth and alias expansion of a parsed
+// document against lim, without expanding any aliases.
+func (lim Limits) checkTree(doc *yaml.Node) error {
+	if lim.MaxDepth > 0 {
+		if n, depth := deepestNode(doc, 0); depth > lim.MaxDepth {
+			return fmt.Errorf("%w: line %d is nested %d levels deep, over the limit of %d", ErrLimitExceeded, n.Line, depth, lim.MaxDepth)
+		}
+	}
+	if lim.MaxAliasExpansion > 0 {
+		nodes := countNodes(doc)
+		expanded := expandedSize(doc, make(map[*yaml.Node]float64))
+		if expanded > lim.MaxAliasExpansion*float64(nodes) {
+			return fmt.Errorf("%w: aliases expand the document from %d to %.0f nodes, over the limit of %gx", ErrLimitExceeded, nodes, expanded, lim.MaxAliasExpansion)
+		}
+	}
+	return nil
+}
+
+// deepestNode returns the most deeply nested mapping or list under n and
+// its depth. Aliases are not followed.
+func deepestNode(n *yaml.Node, depth int) (*yaml.Node, int) {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		depth++
+	}
+	deepest, max := n, depth
+	for _, c := range n.Content {
+		if d, cd := deepestNode(c, depth); cd > max {
+			deepest, max = d, cd
+		}
+	}
+	return deepest, max
+}
+
+// countNodes returns the number of nodes under n, counting aliases once.
+func countNodes(n *yaml.Node) int {
+	count := 1
+	for _, c := range n.Content {
+		count += countNodes(c)
+	}
+	return count
+}
+
+// expandedSize returns the number of nodes under n with every alias
+// replaced by a copy of its anchor, computed in linear time. Sizes grow
+// exponentially for malicious documents, hence float64.
+func expandedSize(n *yaml.Node, memo map[*yaml.Node]float64) float64 {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		return expandedSize(n.Alias, memo)
+	}
+	if size, ok := memo[n]; ok {
+		return size
+	}
+	memo[n] = 0 // a cyclic alias adds nothing while in progress
+	size := 1.0
+	for _, c := range n.Content {
+		size += expandedSize(c, memo)
+	}
+	memo[n] = size
+	return size
+}
@@ -0,0 +1,76 @@
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoad_WithLimits(t *testing.T) {
+	lim := WithLimits(Limits{MaxBytes: 4096, MaxDepth: 4, MaxAliasExpansion: 10})
+
+	ok := "defaults: &defaults\n  timeout: 5s\n  retries: 3\nprimary:\n  <<: *defaults\nreplica:\n  <<: *defaults\n"
+	if _, err := Load[map[string]any](WithBytes([]byte(ok)), lim); err != nil {
+		t.Fatalf("Load of a normal document: %v", err)
+	}
+
+	laughs := "a: &a [lol, lol, lol, lol, lol, lol, lol, lol, lol]\n" +
+		"b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]\n" +
+		"c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]\n" +
+		"d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]\n" +
+		"e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]\n"
+	tests := []struct {
+		name, doc, want string
+	}{
+		{"aliases", laughs, "aliases expand the document"},
+		{"depth", "a:\n  b:\n    c:\n      d:\n        e: 1\n", "line 5 is nested 5 levels deep, over the limit of 4"},
+		{"size", "key: " + strings.Repeat("x", 5000) + "\n", "over the limit of 4096"},
+	}
+	for _, tt := range tests {
+		_, err := Load[map[string]any](WithBytes([]byte(tt.doc)), lim)
+		var parseErr *ParseError
+		if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &parseErr) {
+			t.Fatalf("%s: expected a *ParseError wrapping ErrLimitExceeded, got %v", tt.name, err)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%s: error %q does not mention %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoad_WithMaxConfigSize(t *testing.T) {
+	path := writeConfig(t, "app_name: "+strings.Repeat("x", 2000)+"\n")
+
+	_, err := Load[testConfig](WithConfigFile(path), WithMaxConfigSize(1024))
+	if !errors.Is(err, ErrLimitExceeded) || !strings.Contains(err.Error(), "is 2011 bytes, over the limit of 1024") {
+		t.Fatalf("expected the file size in an ErrLimitExceeded error, got %v", err)
+	}
+
+	_, err = Load[testConfig](WithReader(strings.NewReader("app_name: "+strings.Repeat("x", 2000))), WithMaxConfigSize(1024))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded for a reader, got %v", err)
+	}
+
+	if _, err := Load[testConfig](WithConfigFile(path), WithMaxConfigSize(4096)); err != nil {
+		t.Fatalf("Load under the limit: %v", err)
+	}
+}
+
+func TestLoad_WithoutExpansion(t *testing.T) {
+	t.Setenv("TEST_UNTRUSTED_SECRET", "hunter2")
+	called := false
+	upload := "app_name: ${TEST_UNTRUSTED_SECRET}\nserver:\n  log_level: ${vault:db}\n"
+
+	cfg, err := Load[testConfig](WithBytes([]byte(upload)), WithoutExpansion(), WithStrict(),
+		WithResolver("vault", func(context.Context, string) (string, error) {
+			called = true
+			return "leaked", nil
+		}))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "${TEST_UNTRUSTED_SECRET}" || cfg.Server.LogLevel != "${vault:db}" || called {
+		t.Fatalf("placeholders were expanded: %+v (resolver called: %v)", cfg, called)
+	}
+}
@@ -0,0 +1,144 @@
+// live.go
+package gonfig
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Live is a hot-reloaded config handle that is safe for concurrent use.
+//
+// Get always returns the most recent successfully loaded config. Reloads
+// swap an atomic pointer, so readers never block and never observe a
+// partially updated value.
+type Live[T any] struct {
+	cur atomic.Pointer[T]
+	// frozen is set alongside cur with WithFreeze.
+	frozen atomic.Pointer[Frozen[T]]
+	// watched reloads the config for Override.
+	watched *watched[T]
+
+	mu        sync.Mutex
+	observers []func(Changes)
+}
+
+// NewLive loads the config like Load and keeps it up to date by watching the
+// config source and dotenvs, exactly like Watch. It fails if the initial load
+// fails; later reload failures keep the previous config and are reported to
+// the handler set by WithReloadErrorHandler.
+//
+// The watcher stops when ctx is cancelled; Get keeps returning the last
+// config.
+//
+// Example:
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithReloadErrorHandler(func(err error) {
+//	        log.Printf("config reload failed: %v", err)
+//	    }),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+//	    cfg := live.Get()
+//	    // ...
+//	})
+func NewLive[T any](ctx context.Context, opts ...Option) (*Live[T], error) {
+	return newLive(ctx, newLoader(opts), load[T])
+}
+
+// newLive starts a Live that loads its config with load.
+func newLive[T any](ctx context.Context, l *loader, load func(*loader) (T, Report, error)) (*Live[T], error) {
+	lv := &Live[T]{}
+	publish := lv.set
+	if l.freeze {
+		publish = func(cfg T) {
+			lv.frozen.Store(Freeze(cfg))
+			lv.set(cfg)
+		}
+	}
+	w, err := startWatch(ctx, l, load, publish, func() {})
+	if err != nil {
+		return nil, err
+	}
+	lv.watched = w
+	return lv, nil
+}
+
+// Get returns the current config. With WithFreeze, it is a copy of its
+// own, like Frozen.Get.
+func (lv *Live[T]) Get() T {
+	if f := lv.frozen.Load(); f != nil {
+		return f.Get()
+	}
+	return *lv.cur.Load()
+}
+
+// Override forces value at path, like WithOverride, and reloads the config
+// with it. It sticks through later reloads. by names who made the change,
+// for WithAuditSink:
+//
+//	err := live.Override("log.level", "debug", "alice (incident 4211)")
+//
+// If the config doesn't load or validate with the override, the override
+// is dropped and the error returned; the config stays as it was. Override
+// fails once the watcher has stopped.
+func (lv *Live[T]) Override(path string, value any, by string) error {
+	return lv.watched.override(path, value, by)
+}
+
+// OnChange registers fn to be called after every reload that changed the
+// config, with what changed. Get already returns the new config when fn
+// runs, so it can react to just the parts it cares about:
+//
+//	live.OnChange(func(changes gonfig.Changes) {
+//	    if changes.Touches("server.port") {
+//	        restartListener(live.Get().Server.Port)
+//	    }
+//	})
+//
+// Secret values are masked in the changes. fn is called from the watcher
+// goroutine, one reload at a time, and should not block.
+func (lv *Live[T]) OnChange(fn func(changes Changes)) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	lv.observers = append(lv.observers, fn)
+}
+
+// OnChangeAt registers fn to be called after every reload that changed
+// something at path or below it, with just those changes. It is OnChange
+// for a single field or section, e.g. to reconnect when a rotated database
+// password comes in (see WithRefreshInterval):
+//
+//	live.OnChangeAt("database.password", func(gonfig.Changes) {
+//	    pool.Reconnect(live.Get().Database)
+//	})
+func (lv *Live[T]) OnChangeAt(path string, fn func(changes Changes)) {
+	lv.OnChange(func(changes Changes) {
+		if at := changes.At(path); len(at) > 0 {
+			fn(at)
+		}
+	})
+}
+
+func (lv *Live[T]) set(cfg T) {
+	prev := lv.cur.Swap(&cfg)
+	lv.mu.Lock()
+	observers := lv.observers
+	lv.mu.Unlock()
+	if prev == nil || len(observers) == 0 {
+		return
+	}
+	changes := diff(reflect.ValueOf(*prev), reflect.ValueOf(cfg))
+	if len(changes) == 0 {
+		return
+	}
+	for _, fn := range observers {
+		fn(changes)
+	}
+}
@@ -0,0 +1,251 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLive_GetReflectsReloadsAndReportsErrors(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		reloadErr error
+	)
+	live, err := NewLive[testConfig](ctx,
+		WithConfigFile(path),
+		WithReloadErrorHandler(func(err error) {
+			mu.Lock()
+			reloadErr = err
+			mu.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+	if got := live.Get().Server.Port; got != 8080 {
+		t.Fatalf("expected initial port 8080, got %d", got)
+	}
+
+	replaceFile(t, path, "server:\n  port: 9090\n")
+	waitFor(t, func() bool { return live.Get().Server.Port == 9090 })
+
+	replaceFile(t, path, "server: [\n")
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return reloadErr != nil
+	})
+	if got := live.Get().Server.Port; got != 9090 {
+		t.Fatalf("expected failed reload to keep port 9090, got %d", got)
+	}
+}
+
+// replaceFile atomically swaps the contents of path, the way most editors
+// and deploy tools do, so watchers never see a truncated file.
+func replaceFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		t.Fatalf("write temp config: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("replace config: %v", err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLive_OnChange(t *testing.T) {
+	type config struct {
+		Server   testServerConfig `yaml:"server"`
+		Password Secret           `yaml:"password"`
+	}
+	path := writeConfig(t, "server:\n  port: 8080\npassword: old\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	live, err := NewLive[config](ctx, WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+
+	var mu sync.Mutex
+	var got []Changes
+	live.OnChange(func(changes Changes) {
+		if live.Get().Server.Port != 9090 {
+			t.Errorf("Get should return the new config in OnChange")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, changes)
+	})
+
+	replaceFile(t, path, "server:\n  port: 9090\n  log_level: debug\npassword: new\n")
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) > 0
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := Changes{
+		{Path: "server.port", Kind: Modified, Before: 8080, After: 9090},
+		{Path: "server.log_level", Kind: Modified, Before: "", After: "debug"},
+		{Path: "password", Kind: Modified, Before: "***", After: "***"},
+	}
+	if !reflect.DeepEqual(got[0], want) {
+		t.Fatalf("got %+v\nwant %+v", got[0], want)
+	}
+	if !got[0].Touches("server") || got[0].Touches("database") {
+		t.Fatalf("unexpected Touches results")
+	}
+	if at := got[0].At("server"); !reflect.DeepEqual(at, want[:2]) {
+		t.Fatalf("At(server) = %+v, want %+v", at, want[:2])
+	}
+}
+
+// recordingMetrics records the loads reported to it.
+type recordingMetrics struct {
+	mu      sync.Mutex
+	fetches []string
+	loads   []string
+}
+
+func (m *recordingMetrics) SourceFetched(source string, _ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetches = append(m.fetches, fmt.Sprintf("%s err=%v", source, err != nil))
+}
+
+func (m *recordingMetrics) Loaded(reload bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loads = append(m.loads, fmt.Sprintf("reload=%v err=%v", reload, err != nil))
+}
+
+func (m *recordingMetrics) snapshot() ([]string, []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.fetches...), append([]string(nil), m.loads...)
+}
+
+func TestLive_WithMetrics(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+	m := &recordingMetrics{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	live, err := NewLive[testConfig](ctx, WithConfigFile(path), WithMetrics(m), WithReloadDebounce(0))
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+
+	replaceFile(t, path, "server:\n  port: [not a port\n")
+	waitFor(t, func() bool { _, loads := m.snapshot(); return len(loads) >= 2 })
+	replaceFile(t, path, "server:\n  port: 9090\n")
+	waitFor(t, func() bool { return live.Get().Server.Port == 9090 })
+
+	fetches, loads := m.snapshot()
+	want := []string{"reload=false err=false", "reload=true err=true", "reload=true err=false"}
+	if len(loads) < len(want) || loads[0] != want[0] || loads[1] != want[1] || loads[len(loads)-1] != want[2] {
+		t.Fatalf("loads = %q, want %q", loads, want)
+	}
+	if len(fetches) != len(loads) || fetches[0] != path+" err=false" {
+		t.Fatalf("fetches = %q, want one per load of %s", fetches, path)
+	}
+}
+
+func TestLive_WithRefreshInterval(t *testing.T) {
+	type config struct {
+		Server   testServerConfig `yaml:"server"`
+		Password Secret           `yaml:"password"`
+	}
+	var mu sync.Mutex
+	password := "old"
+	rotate := func(p string) {
+		mu.Lock()
+		defer mu.Unlock()
+		password = p
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	live, err := NewLive[config](ctx,
+		WithBytes([]byte("server:\n  port: 8080\npassword: ${vault:db}\n")),
+		WithResolver("vault", func(context.Context, string) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return password, nil
+		}),
+		WithRefreshInterval(20*time.Millisecond),
+		WithReloadDebounce(0),
+	)
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+
+	rotated := make(chan Changes, 1)
+	live.OnChangeAt("password", func(changes Changes) { rotated <- changes })
+	live.OnChangeAt("server", func(Changes) { t.Errorf("server didn't change") })
+
+	rotate("new")
+	select {
+	case changes := <-rotated:
+		want := Changes{{Path: "password", Kind: Modified, Before: "***", After: "***"}}
+		if !reflect.DeepEqual(changes, want) {
+			t.Fatalf("got %+v\nwant %+v", changes, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("rotated password was not picked up")
+	}
+	if got := live.Get().Password.Value(); got != "new" {
+		t.Fatalf("password = %q, want new", got)
+	}
+}
+
+func TestLive_WithRefreshIntervalReader(t *testing.T) {
+	var refreshes atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	live, err := NewLive[testConfig](ctx,
+		WithReader(strings.NewReader("app_name: ${counter:x}\nserver:\n  port: 8080\n")),
+		WithResolver("counter", func(context.Context, string) (string, error) {
+			refreshes.Add(1)
+			return "api", nil
+		}),
+		WithRefreshInterval(10*time.Millisecond),
+		WithReloadDebounce(0),
+	)
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+	live.OnChange(func(Changes) { t.Errorf("a refresh of the same reader changed the config") })
+
+	waitFor(t, func() bool { return refreshes.Load() >= 3 })
+	if got := live.Get(); got.AppName != "api" || got.Server.Port != 8080 {
+		t.Fatalf("config after refreshes = %+v", got)
+	}
+}
@@ -52,16 +52,139 @@
 package gonfig
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"reflect"
+	"slices"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
 )
 
 type loader struct {
+	// ctx bounds loading (LoadContext, or the lifetime of a watch) and is
+	// passed to sources and resolvers.
+	ctx context.Context
+
+	// source provides the raw config document; the last config source
+	// option wins. configFile names it in errors.
+	source     Source
 	configFile string
-	dotenvs    []string
-	strict     bool
+
+	dotenvs []dotenvFile
+	strict  bool
+	// keepMissing leaves unset placeholders as-is (MissingKeep).
+	keepMissing bool
+	// optionalVars may stay unset in strict mode (WithOptionalVars).
+	optionalVars map[string]bool
+	// bareVariables also expands $VAR (WithBareVariables).
+	bareVariables bool
+	// recursiveExpansion expands placeholders in env var values
+	// (WithRecursiveExpansion).
+	recursiveExpansion bool
+	// noExpansion leaves placeholders as they are and never calls a
+	// resolver (WithoutExpansion).
+	noExpansion bool
+	// dotenvKeepEnv makes every dotenv file behave like
+	// WithDotenvNoOverride (WithDotenvOverride(false)).
+	dotenvKeepEnv bool
+
+	// profile adds config.<profile>.yaml and .env.<profile> layers.
+	profile string
+	// mergeRules are the WithMergeStrategy rules for combining layers.
+	mergeRules []mergeRule
+	// document picks one document of a multi-document layer (WithDocument,
+	// WithDocumentSelector); nil merges them all.
+	document *docSelector
+	// auditSink records reloads and overrides that changed the config
+	// (WithAuditSink).
+	auditSink AuditSink
+	// freeze deep-copies the loaded config and makes Live hand out copies
+	// (WithFreeze).
+	freeze bool
+	// tenant is the file of the tenant being loaded by LoadDir, layered on
+	// tenantBase if set.
+	tenant, tenantBase string
+	// keyMapper matches config keys to struct fields in other naming
+	// conventions (WithKeyMapper).
+	keyMapper func(key string) string
+	// conditions prunes mappings whose when: condition is false
+	// (WithConditionalSections).
+	conditions bool
+	// templating renders every config layer as a text/template before
+	// parsing it (WithTemplating).
+	templating bool
+	// section is the YAML path of the subtree to load (WithSection).
+	section string
+	// schema returns the JSON Schema set by WithSchema, if any.
+	schema func() ([]byte, error)
+	// nodeFile maps the nodes of the merged document to their layer.
+	nodeFile map[*yaml.Node]string
+
+	// getenv replaces the process environment when set (WithEnvLookup).
+	// Dotenv values then go into dotenvEnv instead of os.Setenv.
+	getenv    func(string) (string, bool)
+	dotenvEnv map[string]string
+	// dotenvOrigin maps every var set by a dotenv file to that file.
+	dotenvOrigin map[string]string
+	// secretsDirs are read into secretEnv on every load (WithSecretsDir).
+	secretsDirs []string
+	secretEnv   map[string]string
+	// dotenvSet holds the vars a dotenv file put into the process
+	// environment, across reloads, so they aren't mistaken for real ones.
+	dotenvSet map[string]bool
+
+	envOverrides bool
+	envPrefix    string
+
+	overrides []override
+	flags     []*flagValue
+
+	knownFieldsOnly bool
+	weakTypes       bool
+	// limits bounds every config document (WithLimits).
+	limits Limits
+	// sourceTimeout and sourceRetries apply to every fetch of a config
+	// source (WithSourceTimeout, WithSourceRetries).
+	sourceTimeout time.Duration
+	sourceRetries int
+
+	onReloadError   func(error)
+	reloadDebounce  time.Duration
+	refreshInterval time.Duration
+	onWarn          func(Warning)
+
+	// metrics is set by WithMetrics. reloading is set once the initial
+	// load of a watch is done, so later loads are reported as reloads.
+	metrics   Metrics
+	reloading bool
+	// tracer is set by WithTracerProvider.
+	tracer trace.Tracer
+	// logger is set by WithLogger.
+	logger *slog.Logger
+
+	resolvers map[string]ResolverFunc
+	// resolved caches the results of prefetchResolvers for one load.
+	resolved map[string]resolvedValue
+
+	ageIdentityFiles []string
+	// encryptionKeyEnv and encryptionKeyFile are set by
+	// WithEncryptionKeyFromEnv and WithEncryptionKeyFile.
+	encryptionKeyEnv  string
+	encryptionKeyFile string
+
+	// sha256 and signature are set by WithSHA256 and WithSignature.
+	sha256    string
+	signature *signature
+
+	validators []func(cfg any) error
 }
 
 // Option configures how Load behaves.
@@ -78,11 +201,18 @@ type loader struct {
 type Option func(*loader)
 
 func defaultLoader() *loader {
-	return &loader{
+	l := &loader{
+		ctx:        context.Background(),
+		source:     fileSource("config.yaml"),
 		configFile: "config.yaml",
+		resolvers:  map[string]ResolverFunc{"file": resolveFile},
 		dotenvs:    nil,
 		strict:     false,
+
+		reloadDebounce: 100 * time.Millisecond,
 	}
+	l.resolvers["cred"] = l.resolveCredential
+	return l
 }
 
 // Load reads a YAML config file, expands ${ENV_VAR} placeholders,
@@ -96,13 +226,25 @@ func defaultLoader() *loader {
 // If strict mode is enabled via WithStrict(), any ${VAR} without a value
 // and without a default will cause Load to return an error.
 //
+// If *T implements SetDefaults(), it is called before the YAML is decoded,
+// so it can set defaults that the file then overrides. Nested structs with
+// a SetDefaults() method get the same treatment.
+//
 // If the target type T implements:
 //
 //	type Config struct { /* fields */ }
 //	func (c Config) Validate() error
 //
 // then Validate() will be called after unmarshalling, and any error will be
-// returned from Load.
+// returned from Load. Nested sections with a Validate() method are
+// validated first; their errors are FieldErrors naming the section and its
+// place in the config file. If T or *T implements ValidateWithReport(r *Report)
+// error, it is called next and can add warnings with r.Warn.
+//
+// Finally, if *T implements AfterLoad(report Report) error, it is called
+// with the load report once everything else has passed. Use it to derive
+// computed fields (parsed URLs, normalized paths); an error is returned
+// from Load as a *ValidationError.
 //
 // Basic example:
 //
@@ -130,47 +272,598 @@ func defaultLoader() *loader {
 //	    fmt.Println(cfg.AppName, cfg.Env)
 //	}
 func Load[T any](opts ...Option) (T, error) {
-	var zero T
+	cfg, _, err := load[T](newLoader(opts))
+	return cfg, err
+}
+
+// LoadWithReport is like Load but also returns a Report of where every
+// value came from: which line of the config file (and which env vars it was
+// expanded from), a default tag, or an env override. The report covers
+// whatever was loaded before an error, too.
+//
+// Example:
+//
+//	cfg, report, err := gonfig.LoadWithReport[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithDotenv(".env.dev"),
+//	    gonfig.WithEnvOverrides("APP"),
+//	)
+//	fmt.Println("server.port from", report.Origins["server.port"])
+//	// server.port from env APP_SERVER_PORT (.env.dev)
+func LoadWithReport[T any](opts ...Option) (T, Report, error) {
+	return load[T](newLoader(opts))
+}
+
+// Check is a dry run of Load for CI gates and preflight hooks: it runs the
+// whole pipeline (expansion, schema, required fields, validators, Validate
+// and AfterLoad) and returns the report and the first error, without the
+// service having to start.
+//
+// Check is strict: placeholders without a value or default are an error,
+// as with WithStrict. It also leaves the process environment alone, so
+// dotenv files are applied as if WithEnvLookup(os.LookupEnv) were set.
+//
+// Example:
+//
+//	report, err := gonfig.Check[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithDotenv(".env.prod"),
+//	)
+//	for _, w := range report.Warnings {
+//	    log.Printf("warning: %s", w)
+//	}
+//	if err != nil {
+//	    log.Fatalf("config check failed: %v", err)
+//	}
+func Check[T any](opts ...Option) (Report, error) {
+	l := newLoader(opts)
+	l.strict = true
+	if l.getenv == nil {
+		l.getenv = os.LookupEnv
+	}
+	_, report, err := load[T](l)
+	return report, err
+}
 
+// LoadContext is like Load but bounds loading by ctx: remote config
+// sources, resolvers (e.g. Vault or AWS lookups) and dotenv loading stop
+// when ctx is cancelled or its deadline passes, and the context's error is
+// returned (possibly wrapped).
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//
+//	cfg, err := gonfig.LoadContext[Config](ctx,
+//	    gonfig.WithConfigURL("https://config.internal/billing.yaml"),
+//	)
+func LoadContext[T any](ctx context.Context, opts ...Option) (T, error) {
+	l := newLoader(opts)
+	l.ctx = ctx
+	cfg, _, err := load[T](l)
+	return cfg, err
+}
+
+// LoadInto is the non-generic form of Load for callers that only know the
+// config type at runtime, such as plugins or reflection-driven frameworks.
+// dst must be a non-nil pointer; it is only modified if loading succeeds.
+//
+// Example:
+//
+//	cfg := reflect.New(pluginConfigType)
+//	if err := gonfig.LoadInto(cfg.Interface(), gonfig.WithConfigFile(path)); err != nil {
+//	    return err
+//	}
+func LoadInto(dst any, opts ...Option) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("load into: dst must be a non-nil pointer, got %T", dst)
+	}
+	cfg := reflect.New(v.Elem().Type()).Elem()
+	if _, err := newLoader(opts).loadValue(cfg); err != nil {
+		return err
+	}
+	v.Elem().Set(cfg)
+	return nil
+}
+
+// newLoader applies opts on top of the defaults.
+func newLoader(opts []Option) *loader {
 	l := defaultLoader()
 	for _, opt := range opts {
 		opt(l)
 	}
+	return l
+}
+
+// load runs the full pipeline for an already configured loader and reports
+// where every value came from. It is shared by Load, LoadWithReport,
+// LoadContext and Watch.
+func load[T any](l *loader) (T, Report, error) {
+	var cfg T
+	report, err := l.loadValue(reflect.ValueOf(&cfg).Elem())
+	if err != nil {
+		var zero T
+		return zero, report, err
+	}
+	return cfg, report, nil
+}
+
+// loadValue runs the full pipeline, decoding into the addressable value
+// cfg. On error, cfg may be partially filled.
+func (l *loader) loadValue(cfg reflect.Value) (report Report, err error) {
+	if l.metrics != nil {
+		defer func() { l.metrics.Loaded(l.reloading, err) }()
+	}
+	endLoad := l.startSpan("gonfig.Load", attribute.Bool("gonfig.reload", l.reloading))
+	defer func() { endLoad(err) }()
+	report = Report{Origins: make(map[string]Origin)}
+	warn := func(w Warning) {
+		report.Warnings = append(report.Warnings, w)
+		l.debug("gonfig: warning", "warning", w.String())
+		if l.onWarn != nil {
+			l.onWarn(w)
+		}
+	}
 
-	// 1. Load dotenvs (best-effort)
-	for _, path := range l.dotenvs {
-		if err := loadDotenv(path); err != nil {
-			// ignore missing files, fail on other errors
-			if !os.IsNotExist(err) {
-				return zero, fmt.Errorf("load dotenv %s: %w", path, err)
+	// 1. Load secret files and dotenvs (best-effort)
+	l.dotenvEnv = make(map[string]string)
+	l.dotenvOrigin = make(map[string]string)
+	l.secretEnv = make(map[string]string)
+	l.resolved = make(map[string]resolvedValue)
+	if err := l.ctx.Err(); err != nil {
+		return report, err
+	}
+	for _, dir := range l.secretsDirs {
+		if err := readSecretsDir(dir, l.secretEnv); err != nil {
+			return report, fmt.Errorf("load secrets %s: %w", dir, err)
+		}
+		l.debug("gonfig: read secrets dir", "dir", dir)
+	}
+	for _, d := range l.dotenvLayers() {
+		if err := l.ctx.Err(); err != nil {
+			return report, err
+		}
+		setenv := func(k, v string) error {
+			if d.keepEnv && l.inRealEnv(k) {
+				return nil
+			}
+			l.dotenvOrigin[k] = d.path
+			if l.getenv != nil {
+				l.dotenvEnv[k] = v
+				return nil
 			}
+			if l.dotenvSet == nil {
+				l.dotenvSet = make(map[string]bool)
+			}
+			l.dotenvSet[k] = true
+			return os.Setenv(k, v)
+		}
+		end := l.startSpan("gonfig.dotenv", attribute.String("gonfig.file", d.path))
+		err := loadDotenv(d.path, l.decrypt, l.lookupEnv, setenv)
+		if os.IsNotExist(err) {
+			end(nil)
+		} else {
+			end(err)
+		}
+		// ignore missing files, fail on other errors
+		var parseErr *DotenvError
+		switch {
+		case err == nil:
+			l.debug("gonfig: loaded dotenv", "file", d.path)
+		case os.IsNotExist(err):
+			l.debug("gonfig: dotenv not found, skipped", "file", d.path)
+		case errors.As(err, &parseErr):
+			return report, err
+		default:
+			return report, fmt.Errorf("load dotenv %s: %w", d.path, err)
 		}
 	}
 
-	// 2. Read YAML file
-	raw, err := os.ReadFile(l.configFile)
+	// 2. Read, parse and expand every config layer, then merge them
+	doc := &yaml.Node{}
+	orig := make(map[*yaml.Node]string)
+	l.nodeFile = make(map[*yaml.Node]string)
+	layers, err := l.layers()
 	if err != nil {
-		return zero, fmt.Errorf("read config file %s: %w", l.configFile, err)
+		return report, err
+	}
+	if err := l.ctx.Err(); err != nil {
+		return report, err
+	}
+	fetched := l.fetchLayers(layers)
+	for i, ly := range layers {
+		layerDoc, err := l.readLayer(ly, i == 0, fetched[i], orig, warn)
+		if err != nil {
+			return report, err
+		}
+		if layerDoc == nil {
+			continue
+		}
+		// Rename keys spelled in another convention (WithKeyMapper)
+		// before merging, so layers in different conventions line up
+		if l.keyMapper != nil {
+			n := layerDoc
+			if l.section != "" {
+				n = sectionNode(layerDoc, l.section)
+			}
+			if err := mapKeys(n, cfg.Type(), l.keyMapper); err != nil {
+				return report, &ParseError{File: ly.name, Err: err}
+			}
+		}
+		doc = merger(l.mergeRules).merge(doc, layerDoc, nil)
 	}
 
-	// 3. Expand env placeholders (${VAR}, ${VAR:-default})
-	expanded, err := expandEnv(string(raw), l.strict)
+	// 3. Decode the merged tree (or the WithSection subtree) into T, on top
+	// of `default:"..."` tags and SetDefaults()
+	root := l.section
+	if root != "" {
+		doc = sectionNode(doc, root)
+	}
+	applyAliases(doc, cfg.Type(), root, l.fileOf, warn)
+	if err := l.checkSchema(doc); err != nil {
+		return report, err
+	}
+	if cfg.Kind() == reflect.Struct {
+		err := applyDefaults(cfg, root, func(path string) {
+			report.Origins[path] = Origin{Kind: FromDefault}
+		})
+		if err != nil {
+			return report, err
+		}
+		setDefaults(cfg)
+	}
+	end := l.startSpan("gonfig.decode")
+	err = l.decode(doc, cfg.Addr().Interface())
+	end(err)
 	if err != nil {
-		return zero, fmt.Errorf("expand env in config: %w", err)
+		return report, &ParseError{File: l.configFile, Err: err}
+	}
+	l.recordFileOrigins(&report, doc, root, orig)
+	if !l.knownFieldsOnly && doc.Kind != 0 {
+		for _, w := range unknownKeys(doc, cfg.Type(), root, l.fileOf) {
+			warn(w)
+		}
 	}
 
-	// 4. Unmarshal YAML into T
-	var cfg T
-	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
-		return zero, fmt.Errorf("unmarshal config yaml: %w", err)
+	// 4. Apply env var overrides and `env:"NAME"` tags on top of the file
+	// values
+	recordEnv := func(path, name string) {
+		l.recordEnvOrigin(&report, path, name)
+	}
+	if l.envOverrides {
+		err = applyEnvOverrides(cfg, l.envPrefix, root, l.lookupEnv, recordEnv)
+	} else {
+		err = applyEnvTags(cfg, root, l.lookupEnv, recordEnv)
+	}
+	if err != nil {
+		return report, fmt.Errorf("apply env overrides: %w", err)
 	}
 
-	// 5. If cfg has Validate() error, call it
-	if v, ok := any(cfg).(interface{ Validate() error }); ok {
+	// Settle feature flags for the profile, then apply their kill-switches
+	if err := resolveFlags(cfg, l.envPrefix, root, l.profile, l.lookupEnv, recordEnv); err != nil {
+		return report, err
+	}
+
+	// 5. Apply WithOverride and WithValues on top of everything else
+	if err := applyOverrides(cfg, root, l.overrides, func(path string) {
+		report.Origins[path] = Origin{Kind: FromOverride}
+	}); err != nil {
+		return report, fmt.Errorf("apply overrides: %w", err)
+	}
+
+	// 6. Apply command-line flags from WithFlagSet last
+	if err := applyFlags(cfg, root, l.flags, func(path, name string) {
+		report.Origins[path] = Origin{Kind: FromFlag, Vars: []string{name}}
+	}); err != nil {
+		return report, fmt.Errorf("apply flags: %w", err)
+	}
+
+	l.logValues(cfg, root, report)
+
+	// 7. Check required fields, then run validators and hooks
+	end = l.startSpan("gonfig.validate")
+	err = l.validate(cfg, root, &report, warn)
+	l.locateFieldErrors(err, doc, root, &report)
+	end(err)
+	if err == nil && l.freeze {
+		cfg.Set(deepCopy(cfg))
+	}
+	return report, err
+}
+
+// validate checks `gonfig:"required"` fields and enum values, and runs the
+// WithValidator functions, Validate, ValidateWithReport and AfterLoad on a
+// loaded cfg.
+// Warnings from ValidateWithReport are passed to warn.
+func (l *loader) validate(cfg reflect.Value, root string, report *Report, warn func(Warning)) error {
+	// Check `gonfig:"required"` fields and enum values
+	if errs := append(checkRequired(cfg, root), checkEnums(cfg, root)...); len(errs) > 0 {
+		return &ValidationError{Err: errors.Join(errs...)}
+	}
+
+	// Run validators added with WithValidator
+	for _, validate := range l.validators {
+		if err := validate(cfg.Interface()); err != nil {
+			rebaseFieldErrors(err, root)
+			return &ValidationError{Err: err}
+		}
+	}
+
+	// Call Validate() error on nested sections, then on cfg
+	if errs := validateNested(cfg, root); len(errs) > 0 {
+		return &ValidationError{Err: errors.Join(errs...)}
+	}
+	if v, ok := cfg.Interface().(validator); ok {
 		if err := v.Validate(); err != nil {
-			return zero, fmt.Errorf("config validation failed: %w", err)
+			return &ValidationError{Err: err}
+		}
+	}
+
+	// If *cfg has ValidateWithReport(*Report) error, call it with the
+	// report so far and pass on the warnings it adds
+	if v, ok := cfg.Addr().Interface().(reportValidator); ok {
+		before := len(report.Warnings)
+		err := v.ValidateWithReport(report)
+		added := slices.Clone(report.Warnings[before:])
+		report.Warnings = report.Warnings[:before]
+		for _, w := range added {
+			warn(report.locate(w, l.configFile))
+		}
+		if err != nil {
+			return &ValidationError{Err: err}
+		}
+	}
+
+	// If *cfg has AfterLoad(Report) error, call it last
+	if a, ok := cfg.Addr().Interface().(afterLoader); ok {
+		if err := a.AfterLoad(*report); err != nil {
+			return &ValidationError{Err: err}
+		}
+	}
+
+	return nil
+}
+
+// reportValidator is implemented by configs with a ValidateWithReport hook.
+// A method on the value type is found through cfg.Addr() as well.
+type reportValidator interface {
+	ValidateWithReport(r *Report) error
+}
+
+// afterLoader is implemented by configs with an AfterLoad hook.
+type afterLoader interface {
+	AfterLoad(report Report) error
+}
+
+// decode decodes the expanded YAML tree into out. With WithKnownFieldsOnly,
+// keys that don't map onto a field of out are reported by YAML path; with
+// WithWeakTypes, quoted numbers and bools are re-typed first.
+func (l *loader) decode(doc *yaml.Node, out any) error {
+	if doc.Kind == 0 {
+		// Empty document: nothing to decode.
+		return nil
+	}
+	if l.knownFieldsOnly {
+		if keys := unknownKeys(doc, reflect.TypeOf(out).Elem(), l.section, l.fileOf); len(keys) > 0 {
+			return unknownKeysError(keys)
+		}
+	}
+	if l.weakTypes {
+		weakenScalars(doc, reflect.TypeOf(out).Elem())
+	}
+	return decodeWithHooks(doc, out)
+}
+
+// reloadError reports a failed background reload to the handler set by
+// WithReloadErrorHandler, if any.
+func (l *loader) reloadError(err error) {
+	if l.onReloadError != nil {
+		l.onReloadError(err)
+	}
+}
+
+// lookupEnv reads an env var from the process environment, or from the
+// dotenv files and the WithEnvLookup function when one is set, falling back
+// to WithSecretsDir files.
+func (l *loader) lookupEnv(name string) (string, bool) {
+	var val string
+	var ok bool
+	if l.getenv == nil {
+		val, ok = os.LookupEnv(name)
+	} else if val, ok = l.dotenvEnv[name]; !ok {
+		val, ok = l.getenv(name)
+	}
+	if !ok {
+		val, ok = l.secretEnv[name]
+	}
+	l.logEnvLookup(name, ok)
+	return val, ok
+}
+
+// expandMode returns the expansion settings of the loader's options.
+func (l *loader) expandMode() expandMode {
+	return expandMode{
+		strict:   l.strict,
+		bare:     l.bareVariables,
+		keep:     l.keepMissing,
+		optional: l.optionalVars,
+	}
+}
+
+// inRealEnv reports whether name is set in the environment by something
+// other than a dotenv file.
+func (l *loader) inRealEnv(name string) bool {
+	if l.getenv != nil {
+		_, ok := l.getenv(name)
+		return ok
+	}
+	_, ok := os.LookupEnv(name)
+	return ok && !l.dotenvSet[name]
+}
+
+// fetchedLayer is the raw document of a layer, or the error fetching it.
+type fetchedLayer struct {
+	raw []byte
+	err error
+}
+
+// fetchLayers fetches all layers concurrently, so slow remote sources
+// don't add up. Results are in layer order and every fetch runs to
+// completion, so merging and error reporting stay deterministic.
+func (l *loader) fetchLayers(layers []layer) []fetchedLayer {
+	out := make([]fetchedLayer, len(layers))
+	var g errgroup.Group
+	for i, ly := range layers {
+		g.Go(func() error {
+			out[i].raw, out[i].err = l.fetchLayer(ly)
+			return nil
+		})
+	}
+	g.Wait()
+	return out
+}
+
+// fetchLayer fetches a single layer, reporting it to WithTracerProvider and
+// WithMetrics. A missing optional layer is not reported.
+func (l *loader) fetchLayer(ly layer) ([]byte, error) {
+	start := time.Now()
+	ctx, end := l.span(l.ctx, "gonfig.fetch", attribute.String("gonfig.source", ly.name))
+	raw, err := l.fetch(ctx, ly.src)
+	if ly.optional && errors.Is(err, fs.ErrNotExist) {
+		end(nil)
+		return nil, err
+	}
+	end(err)
+	if l.metrics != nil {
+		l.metrics.SourceFetched(ly.name, time.Since(start), err)
+	}
+	return raw, err
+}
+
+// readLayer reads, parses and expands a single config layer, recording the
+// original value of every scalar in orig. base is set for the first layer.
+// It returns nil for a missing optional layer.
+func (l *loader) readLayer(ly layer, base bool, f fetchedLayer, orig map[*yaml.Node]string, warn func(Warning)) (*yaml.Node, error) {
+	doc, err := l.parseLayer(ly, f)
+	if doc == nil || err != nil {
+		return nil, err
+	}
+
+	// Upgrade old config layouts (RegisterMigration). Overlays without a
+	// version of their own are left alone: they aren't version 0 files,
+	// just partial ones
+	versionNode := nodeAt(doc, []string{"version"})
+	var from, to int
+	if base || versionNode != nil {
+		from, to, err = migrate(doc)
+		if err != nil {
+			return nil, &ParseError{File: ly.name, Err: err}
 		}
 	}
+	if from != to {
+		w := Warning{
+			Kind:    WarnMigrated,
+			File:    ly.name,
+			Path:    "version",
+			Message: fmt.Sprintf("migrated from version %d to %d; update the file", from, to),
+		}
+		if versionNode != nil {
+			w.Line, w.Column = versionNode.Line, versionNode.Column
+		}
+		warn(w)
+	}
 
-	return cfg, nil
+	// Prune sections whose when: condition is false
+	// (WithConditionalSections); an empty layer is skipped
+	if l.conditions {
+		keep, err := l.applyConditions(doc)
+		if err != nil {
+			return nil, &ParseError{File: ly.name, Err: err}
+		}
+		if !keep {
+			return nil, nil
+		}
+	}
+
+	// Expand env placeholders (${VAR}, ${VAR:-default}) in scalar values
+	walkScalars(doc, func(n *yaml.Node, _ func() string) {
+		orig[n] = n.Value
+		l.nodeFile[n] = ly.name
+	})
+	if l.noExpansion {
+		return doc, nil
+	}
+	end := l.startSpan("gonfig.expand", attribute.String("gonfig.source", ly.name))
+	l.prefetchResolvers(doc)
+	unset, err := expandNode(doc, l.expandMode(), l.lookup)
+	end(err)
+	if err != nil {
+		var (
+			missing  *MissingEnvError
+			resolveE *ResolveError
+		)
+		// A resolve error may wrap a missing env var of an env value
+		// (WithRecursiveExpansion), which keeps its own location.
+		switch {
+		case errors.As(err, &resolveE):
+			resolveE.File = ly.name
+		case errors.As(err, &missing):
+			missing.File = ly.name
+		}
+		return nil, fmt.Errorf("expand env in config: %w", err)
+	}
+	outcome := "expanded to an empty string"
+	if l.keepMissing {
+		outcome = "left as is"
+	}
+	for _, v := range unset {
+		warn(Warning{
+			Kind:    WarnEmptyExpansion,
+			File:    ly.name,
+			Path:    v.Path,
+			Line:    v.Line,
+			Column:  v.Column,
+			Message: v.Name + " is not set; " + outcome,
+		})
+	}
+	return doc, nil
+}
+
+// parseLayer verifies, decrypts, renders and parses a fetched config
+// layer. It returns nil for a missing optional layer.
+func (l *loader) parseLayer(ly layer, f fetchedLayer) (*yaml.Node, error) {
+	raw, err := f.raw, f.err
+	if ly.optional && errors.Is(err, fs.ErrNotExist) {
+		l.debug("gonfig: optional config not found, skipped", "source", ly.name)
+		return nil, nil
+	}
+	if err == nil && ly.verify {
+		err = l.verify(raw)
+	}
+	if err == nil {
+		raw, err = l.decrypt(ly.name, raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", ly.name, err)
+	}
+	l.debug("gonfig: read config", "source", ly.name, "bytes", len(raw))
+
+	if err := l.limits.checkSize(raw); err != nil {
+		return nil, &ParseError{File: ly.name, Err: err}
+	}
+	if l.templating {
+		if raw, err = l.render(ly.name, raw); err != nil {
+			return nil, &ParseError{File: ly.name, Err: err}
+		}
+	}
+	doc, err := l.unmarshalLayer(raw)
+	if err != nil {
+		return nil, &ParseError{File: ly.name, Err: err}
+	}
+	if err := l.limits.checkTree(doc); err != nil {
+		return nil, &ParseError{File: ly.name, Err: err}
+	}
+	return doc, nil
 }
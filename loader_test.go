@@ -0,0 +1,879 @@
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type testServerConfig struct {
+	Port     int           `yaml:"port"`
+	LogLevel string        `yaml:"log_level"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+type testConfig struct {
+	AppName string           `yaml:"app_name"`
+	Server  testServerConfig `yaml:"server"`
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	path := writeConfig(t, "app_name: svc\nserver:\n  port: 8080\n  log_level: info\n")
+	t.Setenv("APP_SERVER_PORT", "9090")
+	t.Setenv("APP_SERVER_LOG_LEVEL", "debug")
+	t.Setenv("APP_SERVER_TIMEOUT", "30s")
+
+	cfg, err := Load[testConfig](WithConfigFile(path), WithEnvOverrides("APP"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Fatalf("expected port 9090, got %d", cfg.Server.Port)
+	}
+	if cfg.Server.LogLevel != "debug" {
+		t.Fatalf("expected log_level debug, got %q", cfg.Server.LogLevel)
+	}
+	if cfg.Server.Timeout != 30*time.Second {
+		t.Fatalf("expected timeout 30s, got %v", cfg.Server.Timeout)
+	}
+	if cfg.AppName != "svc" {
+		t.Fatalf("expected app_name to be untouched, got %q", cfg.AppName)
+	}
+}
+
+func TestLoad_EnvOverridesMap(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+	t.Setenv("APP_SERVER_PORT", "9090")
+
+	cfg, err := Load[map[string]any](WithConfigFile(path), WithEnvOverrides("APP_"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	server := cfg["server"].(map[string]any)
+	if server["port"] != 9090 {
+		t.Fatalf("expected port 9090, got %#v", server["port"])
+	}
+}
+
+func TestLoad_EnvOverridesInvalidValue(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+	t.Setenv("APP_SERVER_PORT", "not-a-number")
+
+	if _, err := Load[testConfig](WithConfigFile(path), WithEnvOverrides("APP")); err == nil {
+		t.Fatalf("expected error for non-numeric override")
+	}
+}
+
+func TestLoad_EnvTags(t *testing.T) {
+	type database struct {
+		Host string `yaml:"host" env:"DB_HOST" default:"localhost"`
+		Port int    `yaml:"port" env:"DB_PORT" default:"5432"`
+		User string `yaml:"user" env:"DB_USER" gonfig:"required"`
+	}
+	type config struct {
+		Database database `yaml:"database"`
+	}
+	path := writeConfig(t, "database:\n  port: 6432\n")
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_USER", "app")
+
+	cfg, report, err := LoadWithReport[config](WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	want := database{Host: "db.internal", Port: 6432, User: "app"}
+	if cfg.Database != want {
+		t.Fatalf("got %+v, want %+v", cfg.Database, want)
+	}
+	if o := report.Origins["database.host"]; o.Kind != FromEnv || o.Vars[0] != "DB_HOST" {
+		t.Fatalf("unexpected origin: %+v", o)
+	}
+
+	// The tag wins over the derived name, and over the file.
+	t.Setenv("DB_PORT", "7432")
+	t.Setenv("APP_DATABASE_PORT", "8432")
+	cfg, err = Load[config](WithConfigFile(path), WithEnvOverrides("APP"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Database.Port != 7432 {
+		t.Fatalf("expected port 7432 from DB_PORT, got %d", cfg.Database.Port)
+	}
+}
+
+func TestLoad_EnvTagFallbacks(t *testing.T) {
+	type config struct {
+		URL string `yaml:"url" env:"NEW_DB_URL, DATABASE_URL"`
+	}
+	path := writeConfig(t, "url: file\n")
+	t.Setenv("DATABASE_URL", "postgres://old")
+
+	cfg, report, err := LoadWithReport[config](WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg.URL != "postgres://old" || report.Origins["url"].Vars[0] != "DATABASE_URL" {
+		t.Fatalf("expected the fallback name, got %q (%+v)", cfg.URL, report.Origins["url"])
+	}
+
+	t.Setenv("NEW_DB_URL", "postgres://new")
+	if cfg, err := Load[config](WithConfigFile(path)); err != nil || cfg.URL != "postgres://new" {
+		t.Fatalf("expected the first name to win, got %q, %v", cfg.URL, err)
+	}
+}
+
+func TestLoad_WithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/config.yaml": {Data: []byte("app_name: ${TEST_FS_APP:-embedded}\n")},
+	}
+
+	cfg, err := Load[testConfig](WithFS(fsys, "conf/config.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "embedded" {
+		t.Fatalf("expected app_name embedded, got %q", cfg.AppName)
+	}
+}
+
+func TestLoad_WithReaderAndBytes(t *testing.T) {
+	t.Setenv("TEST_READER_PORT", "7070")
+	raw := "server:\n  port: ${TEST_READER_PORT}\n"
+
+	cfg, err := Load[testConfig](WithReader(strings.NewReader(raw)), WithStrict())
+	if err != nil {
+		t.Fatalf("Load with reader: %v", err)
+	}
+	if cfg.Server.Port != 7070 {
+		t.Fatalf("expected port 7070 from reader, got %d", cfg.Server.Port)
+	}
+
+	cfg, err = Load[testConfig](WithBytes([]byte(raw)), WithStrict())
+	if err != nil {
+		t.Fatalf("Load with bytes: %v", err)
+	}
+	if cfg.Server.Port != 7070 {
+		t.Fatalf("expected port 7070 from bytes, got %d", cfg.Server.Port)
+	}
+}
+
+func TestLoad_LastConfigSourceWins(t *testing.T) {
+	path := writeConfig(t, "app_name: from-file\n")
+
+	cfg, err := Load[testConfig](WithBytes([]byte("app_name: from-bytes\n")), WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "from-file" {
+		t.Fatalf("expected the later WithConfigFile to win, got %q", cfg.AppName)
+	}
+}
+
+func TestLoad_KnownFieldsOnly(t *testing.T) {
+	path := writeConfig(t, "app_name: svc\nserver:\n  prot: 8080\n")
+
+	if _, err := Load[testConfig](WithConfigFile(path)); err != nil {
+		t.Fatalf("expected unknown keys to be ignored by default, got %v", err)
+	}
+
+	_, err := Load[testConfig](WithConfigFile(path), WithKnownFieldsOnly())
+	if err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+	if !strings.Contains(err.Error(), "server.prot (line 3, column 3)") {
+		t.Fatalf("expected error to name server.prot with its position, got %v", err)
+	}
+}
+
+func TestLoad_KnownFieldsOnlyEmptyConfig(t *testing.T) {
+	if _, err := Load[testConfig](WithBytes(nil), WithKnownFieldsOnly()); err != nil {
+		t.Fatalf("expected empty config to load, got %v", err)
+	}
+}
+
+func TestLoad_StructuredErrors(t *testing.T) {
+	t.Run("missing env", func(t *testing.T) {
+		raw := "app_name: svc\nserver:\n  log_level: ${TEST_MISSING_LEVEL}\n"
+		_, err := Load[testConfig](WithBytes([]byte(raw)), WithStrict())
+
+		var missing *MissingEnvError
+		if !errors.As(err, &missing) {
+			t.Fatalf("expected *MissingEnvError, got %T: %v", err, err)
+		}
+		if len(missing.Vars) != 1 || missing.Vars[0].Name != "TEST_MISSING_LEVEL" || missing.Vars[0].Line != 3 {
+			t.Fatalf("unexpected missing vars: %+v", missing.Vars)
+		}
+		if !strings.Contains(err.Error(), "TEST_MISSING_LEVEL (<bytes>:3:14)") {
+			t.Fatalf("expected error to include file, line and column, got %v", err)
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		_, err := Load[testConfig](WithBytes([]byte("server:\n  port: eighty\n")))
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected *ParseError, got %T: %v", err, err)
+		}
+		if parseErr.File != "<bytes>" {
+			t.Fatalf("expected File <bytes>, got %q", parseErr.File)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := Load[validatedConfig](WithBytes([]byte("name: \"\"\n")))
+
+		var valErr *ValidationError
+		if !errors.As(err, &valErr) {
+			t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+		}
+		if !errors.Is(err, errNameRequired) {
+			t.Fatalf("expected ValidationError to unwrap to the Validate() error")
+		}
+	})
+}
+
+var errNameRequired = errors.New("name is required")
+
+type validatedConfig struct {
+	Name string `yaml:"name"`
+}
+
+func (c validatedConfig) Validate() error {
+	if c.Name == "" {
+		return errNameRequired
+	}
+	return nil
+}
+
+type portConfig struct {
+	Port int `yaml:"port"`
+}
+
+func (c portConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be > 0")
+	}
+	return nil
+}
+
+type nestedValidatedConfig struct {
+	Server  portConfig            `yaml:"server"`
+	Metrics *portConfig           `yaml:"metrics"`
+	Workers map[string]portConfig `yaml:"workers"`
+}
+
+func TestLoad_NestedValidateErrors(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\nmetrics:\n  port: 0\nworkers:\n  mail:\n    port: -1\n")
+	_, err := Load[nestedValidatedConfig](WithConfigFile(path))
+
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "metrics" || fe.File != path || fe.Line != 3 || fe.Column != 1 {
+		t.Fatalf("expected a FieldError at metrics (%s:3:1), got %+v", path, fe)
+	}
+	for _, want := range []string{
+		"metrics (" + path + ":3:1): port must be > 0",
+		"workers.mail (" + path + ":6:3): port must be > 0",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "server") {
+		t.Fatalf("valid section reported: %v", err)
+	}
+
+	// Map entries are reported in key order.
+	path = writeConfig(t, "server:\n  port: 1\nworkers:\n  d: {}\n  b: {}\n  e: {}\n  a: {}\n  c: {}\n")
+	for range 5 {
+		_, err = Load[nestedValidatedConfig](WithConfigFile(path))
+		last := -1
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			i := strings.Index(err.Error(), "workers."+key+" (")
+			if i <= last {
+				t.Fatalf("expected errors in key order, got %v", err)
+			}
+			last = i
+		}
+	}
+
+	// Sections that weren't in the file have a path but no location.
+	_, err = Load[nestedValidatedConfig](WithBytes([]byte("metrics:\n  port: 9\n")))
+	if !errors.As(err, &fe) || fe.Path != "server" || fe.File != "" {
+		t.Fatalf("expected an unlocated FieldError at server, got %v", err)
+	}
+}
+
+func TestLoad_WithEnvLookup(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dotenv := filepath.Join(dir, ".env")
+	if err := os.WriteFile(dotenv, []byte("GONFIG_TEST_LEVEL=debug\n"), 0o644); err != nil {
+		t.Fatalf("write dotenv: %v", err)
+	}
+	env := map[string]string{
+		"GONFIG_TEST_PORT":   "8080",
+		"GONFIG_TEST_LEVEL":  "info",
+		"APP_SERVER_TIMEOUT": "5s",
+	}
+
+	cfg, err := Load[testConfig](
+		WithBytes([]byte("server:\n  port: ${GONFIG_TEST_PORT}\n  log_level: ${GONFIG_TEST_LEVEL}\n")),
+		WithDotenv(dotenv),
+		WithEnvOverrides("APP"),
+		WithEnvLookup(func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8080 || cfg.Server.LogLevel != "debug" || cfg.Server.Timeout != 5*time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if _, ok := os.LookupEnv("GONFIG_TEST_LEVEL"); ok {
+		t.Fatalf("dotenv must not touch the process environment with WithEnvLookup")
+	}
+}
+
+func TestLoad_WithDotenvNoOverride(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dotenv := filepath.Join(dir, ".env")
+	if err := os.WriteFile(dotenv, []byte("GONFIG_TEST_PORT=9999\nGONFIG_TEST_LEVEL=debug\n"), 0o644); err != nil {
+		t.Fatalf("write dotenv: %v", err)
+	}
+	if err := os.WriteFile(dotenv+".prod", []byte("GONFIG_TEST_LEVEL=warn\n"), 0o644); err != nil {
+		t.Fatalf("write dotenv: %v", err)
+	}
+	lookup := WithEnvLookup(func(name string) (string, bool) {
+		return "8080", name == "GONFIG_TEST_PORT"
+	})
+	src := WithBytes([]byte("server:\n  port: ${GONFIG_TEST_PORT}\n  log_level: ${GONFIG_TEST_LEVEL}\n"))
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		port  int
+		level string
+	}{
+		{"override", []Option{WithDotenv(dotenv)}, 9999, "debug"},
+		{"no override", []Option{WithDotenvNoOverride(dotenv)}, 8080, "debug"},
+		{"global", []Option{WithDotenv(dotenv), WithDotenvOverride(false)}, 8080, "debug"},
+		{"profile still wins", []Option{WithDotenvNoOverride(dotenv), WithProfile("prod")}, 8080, "warn"},
+	}
+	for _, tt := range tests {
+		cfg, err := Load[testConfig](append([]Option{src, lookup}, tt.opts...)...)
+		if err != nil {
+			t.Fatalf("%s: Load: %v", tt.name, err)
+		}
+		if cfg.Server.Port != tt.port || cfg.Server.LogLevel != tt.level {
+			t.Fatalf("%s: got port %d, level %q", tt.name, cfg.Server.Port, cfg.Server.LogLevel)
+		}
+	}
+}
+
+func TestLoad_WithProfile(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "app_name: svc\nserver:\n  port: 8080\n  log_level: ${GONFIG_TEST_LEVEL}\n")
+	dir := filepath.Dir(path)
+	prod := filepath.Join(dir, "config.prod.yaml")
+	files := map[string]string{
+		prod:                            "server:\n  port: 443\n",
+		filepath.Join(dir, ".env"):      "GONFIG_TEST_LEVEL=debug\n",
+		filepath.Join(dir, ".env.prod"): "GONFIG_TEST_LEVEL=warn\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	noEnv := func(string) (string, bool) { return "", false }
+
+	cfg, report, err := LoadWithReport[testConfig](
+		WithConfigFile(path),
+		WithDotenv(filepath.Join(dir, ".env")),
+		WithProfile("prod"),
+		WithEnvLookup(noEnv),
+	)
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg.AppName != "svc" || cfg.Server.Port != 443 || cfg.Server.LogLevel != "warn" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if got := report.Origins["server.port"].File; got != prod {
+		t.Fatalf("server.port from %q, want %q", got, prod)
+	}
+	if got := report.Origins["app_name"].File; got != path {
+		t.Fatalf("app_name from %q, want %q", got, path)
+	}
+
+	// A profile without files is not an error.
+	cfg, err = Load[testConfig](WithConfigFile(path), WithProfile("staging"), WithEnvLookup(noEnv))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8080 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoad_WithConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"00-base.yaml":  "app_name: svc\nserver:\n  port: 8080\n  log_level: info\n",
+		"10-server.yml": "server:\n  port: 9090\n",
+		".hidden.yaml":  "app_name: hidden\n",
+		"README.txt":    "not yaml: [\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	cfg, report, err := LoadWithReport[testConfig](WithConfigDir(dir))
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg.AppName != "svc" || cfg.Server.Port != 9090 || cfg.Server.LogLevel != "info" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if got, want := report.Origins["server.port"].File, filepath.Join(dir, "10-server.yml"); got != want {
+		t.Fatalf("server.port from %q, want %q", got, want)
+	}
+
+	if _, err := Load[testConfig](WithConfigDir(filepath.Join(dir, "missing"))); err == nil {
+		t.Fatalf("expected an error for a missing config dir")
+	}
+}
+
+func TestLoad_MultiDocument(t *testing.T) {
+	path := writeConfig(t, "app_name: base\nserver:\n  port: 8080\n  log_level: info\n---\napp_name: api\nserver:\n  port: 9090\n")
+
+	cfg, report, err := LoadWithReport[testConfig](WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg.AppName != "api" || cfg.Server.Port != 9090 || cfg.Server.LogLevel != "info" {
+		t.Fatalf("documents not merged: %+v", cfg)
+	}
+	if got := report.Origins["server.port"].Line; got != 8 {
+		t.Fatalf("server.port from line %d, want 8", got)
+	}
+
+	cfg, err = Load[testConfig](WithConfigFile(path), WithDocument(0))
+	if err != nil || cfg.AppName != "base" || cfg.Server.Port != 8080 {
+		t.Fatalf("WithDocument(0) = %+v, %v", cfg, err)
+	}
+	cfg, err = Load[testConfig](WithConfigFile(path), WithDocumentSelector("app_name", "api"))
+	if err != nil || cfg.Server.Port != 9090 || cfg.Server.LogLevel != "" {
+		t.Fatalf("WithDocumentSelector = %+v, %v", cfg, err)
+	}
+
+	var perr *ParseError
+	if _, err := Load[testConfig](WithConfigFile(path), WithDocument(2)); !errors.As(err, &perr) {
+		t.Fatalf("expected a ParseError for a missing document, got %v", err)
+	}
+	if _, err := Load[testConfig](WithConfigFile(path), WithDocumentSelector("app_name", "web")); !errors.As(err, &perr) {
+		t.Fatalf("expected a ParseError for an unmatched selector, got %v", err)
+	}
+}
+
+func TestLoadContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadContext[testConfig](ctx, WithBytes([]byte("app_name: svc\n"))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	slow := func(ctx context.Context, key string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	_, err := LoadContext[testConfig](ctx,
+		WithBytes([]byte("app_name: ${slow:name}\n")),
+		WithResolver("slow", slow),
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestLoadInto(t *testing.T) {
+	path := writeConfig(t, "app_name: svc\nserver:\n  port: 8080\n")
+
+	var cfg testConfig
+	if err := LoadInto(&cfg, WithConfigFile(path)); err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	if cfg.AppName != "svc" || cfg.Server.Port != 8080 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	// Runtime-built targets work too.
+	dst := reflect.New(reflect.TypeOf(testServerConfig{}))
+	if err := LoadInto(dst.Interface(), WithBytes([]byte("port: 9090\n"))); err != nil {
+		t.Fatalf("LoadInto: %v", err)
+	}
+	if got := dst.Elem().Interface().(testServerConfig).Port; got != 9090 {
+		t.Fatalf("port: got %d", got)
+	}
+
+	// A failed load leaves dst alone.
+	if err := LoadInto(&cfg, WithBytes([]byte("server:\n  port: abc\n"))); err == nil {
+		t.Fatalf("expected a parse error")
+	}
+	if cfg.Server.Port != 8080 {
+		t.Fatalf("dst changed on error: %+v", cfg)
+	}
+
+	if err := LoadInto(cfg, WithConfigFile(path)); err == nil || !strings.Contains(err.Error(), "non-nil pointer") {
+		t.Fatalf("expected a non-pointer error, got %v", err)
+	}
+}
+
+type setDefaultsServer struct {
+	Port    int           `yaml:"port"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (s *setDefaultsServer) SetDefaults() {
+	s.Port = 8080
+	s.Timeout = 5 * time.Second
+}
+
+type setDefaultsConfig struct {
+	AppName string            `yaml:"app_name" default:"from-tag"`
+	Server  setDefaultsServer `yaml:"server"`
+	Admin   setDefaultsServer `yaml:"admin"`
+}
+
+func (c *setDefaultsConfig) SetDefaults() {
+	c.AppName += "+code"
+	c.Admin.Port = 9000
+}
+
+func TestLoad_SetDefaults(t *testing.T) {
+	cfg, err := Load[setDefaultsConfig](WithBytes([]byte("server:\n  port: 443\n")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := setDefaultsConfig{
+		AppName: "from-tag+code",
+		Server:  setDefaultsServer{Port: 443, Timeout: 5 * time.Second},
+		Admin:   setDefaultsServer{Port: 9000, Timeout: 5 * time.Second},
+	}
+	if cfg != want {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoad_DefaultTags(t *testing.T) {
+	type dbConfig struct {
+		Host    string        `yaml:"host" default:"localhost"`
+		Port    int           `yaml:"port" default:"5432"`
+		Timeout time.Duration `yaml:"timeout" default:"5s"`
+	}
+	type config struct {
+		Port     int      `yaml:"port" default:"8080"`
+		Hosts    []string `yaml:"hosts" default:"[a, b]"`
+		Debug    *bool    `yaml:"debug" default:"true"`
+		Database dbConfig `yaml:"database"`
+	}
+
+	cfg, err := Load[config](WithBytes([]byte("port: 9090\ndatabase:\n  host: db\n")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Port != 9090 {
+		t.Fatalf("expected YAML to win over the default, got port %d", cfg.Port)
+	}
+	if len(cfg.Hosts) != 2 || cfg.Hosts[1] != "b" {
+		t.Fatalf("expected default hosts, got %v", cfg.Hosts)
+	}
+	if cfg.Debug == nil || !*cfg.Debug {
+		t.Fatalf("expected default debug=true, got %v", cfg.Debug)
+	}
+	if cfg.Database.Host != "db" || cfg.Database.Port != 5432 || cfg.Database.Timeout != 5*time.Second {
+		t.Fatalf("unexpected database config: %+v", cfg.Database)
+	}
+
+	type bad struct {
+		Port int `yaml:"port" default:"eighty"`
+	}
+	_, err = Load[bad](WithBytes([]byte("{}")))
+	if err == nil || !strings.Contains(err.Error(), "default for port") {
+		t.Fatalf("expected default error naming the field, got %v", err)
+	}
+}
+
+func TestLoad_RequiredTags(t *testing.T) {
+	type dbConfig struct {
+		Host     string `yaml:"host" gonfig:"required"`
+		Password string `yaml:"password" required:"true"`
+	}
+	type config struct {
+		Database dbConfig   `yaml:"database"`
+		Replicas []dbConfig `yaml:"replicas"`
+		Metrics  *struct {
+			Port int `yaml:"port" gonfig:"required"`
+		} `yaml:"metrics"`
+	}
+
+	_, err := Load[config](WithBytes([]byte("database:\n  host: db\nreplicas:\n  - password: x\n")))
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) || !errors.Is(fe, ErrRequired) {
+		t.Fatalf("expected a required *FieldError, got %v", err)
+	}
+	for _, want := range []string{"database.password", "replicas[0].host"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "metrics") {
+		t.Fatalf("fields of an absent optional section must not be required: %v", err)
+	}
+
+	_, err = Load[config](WithBytes([]byte("database:\n  host: db\n  password: secret\n")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// Map entries are reported in key order, for required and enum checks.
+	type shards struct {
+		Shards map[string]dbConfig `yaml:"shards"`
+		Levels map[string]struct {
+			Level string `yaml:"level" enum:"debug,info"`
+		} `yaml:"levels"`
+	}
+	doc := []byte("shards:\n  d: {host: x}\n  b: {host: x}\n  e: {host: x}\n  a: {host: x}\n  c: {host: x}\n" +
+		"levels:\n  z: {level: x}\n  y: {level: x}\n  x: {level: x}\n")
+	_, err = Load[shards](WithBytes(doc))
+	want := err.Error()
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		if !strings.Contains(want, "shards."+key+".password") {
+			t.Fatalf("expected shards.%s.password in error, got %v", key, err)
+		}
+	}
+	if strings.Index(want, "shards.a.") > strings.Index(want, "shards.e.") || strings.Index(want, "levels.x.") > strings.Index(want, "levels.z.") {
+		t.Fatalf("expected errors in key order, got %v", err)
+	}
+	for range 20 {
+		if _, err := Load[shards](WithBytes(doc)); err == nil || err.Error() != want {
+			t.Fatalf("errors changed between loads:\n%v\nwant:\n%s", err, want)
+		}
+	}
+}
+
+func TestLoad_WithWeakTypes(t *testing.T) {
+	type config struct {
+		Port    int           `yaml:"port"`
+		Debug   bool          `yaml:"debug"`
+		Ratio   float64       `yaml:"ratio"`
+		Ports   []uint16      `yaml:"ports"`
+		Timeout time.Duration `yaml:"timeout"`
+		Name    string        `yaml:"name"`
+	}
+	path := writeConfig(t, `port: "${PORT}"
+debug: "1"
+ratio: " 1.5 "
+ports: ["80", "443"]
+timeout: "30s"
+name: "8080"
+`)
+	lookup := func(name string) (string, bool) { return "8080", name == "PORT" }
+
+	if _, err := Load[config](WithConfigFile(path), WithEnvLookup(lookup)); err == nil {
+		t.Fatalf("quoted numbers should not decode without WithWeakTypes")
+	}
+	cfg, err := Load[config](WithConfigFile(path), WithEnvLookup(lookup), WithWeakTypes())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := config{Port: 8080, Debug: true, Ratio: 1.5, Ports: []uint16{80, 443}, Timeout: 30 * time.Second, Name: "8080"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	path = writeConfig(t, "port: \"eighty\"\n")
+	if _, err := Load[config](WithConfigFile(path), WithWeakTypes()); err == nil {
+		t.Fatalf("expected an error for a non-numeric port")
+	}
+}
+
+func TestLoad_ListsFromStrings(t *testing.T) {
+	type config struct {
+		Hosts   []string        `yaml:"hosts"`
+		Ports   []int           `yaml:"ports"`
+		Paths   []string        `yaml:"paths" gonfig:"sep=:"`
+		Tags    []string        `yaml:"tags"`
+		Empty   []string        `yaml:"empty"`
+		Timeout []time.Duration `yaml:"timeouts" default:"1s,2s"`
+		Extra   []string        `yaml:"extra"`
+		Words   []string        `yaml:"words" gonfig:"sep= "`
+		Lines   []string        `yaml:"lines" gonfig:"sep=newline"`
+		Pairs   []string        `yaml:"pairs" gonfig:"sep=comma"`
+	}
+	path := writeConfig(t, `hosts: ${HOSTS}
+ports: "80, 443"
+paths: /usr/bin:/bin
+tags: [a, b]
+empty: ""
+words: one two
+lines: "a, b\nc"
+pairs: "a;b, c"
+`)
+	env := map[string]string{"HOSTS": "a.example.com,b.example.com", "APP_EXTRA": "x,y", "APP_PATHS": "/opt:/srv"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	cfg, err := Load[config](WithConfigFile(path), WithEnvLookup(lookup))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := config{
+		Hosts:   []string{"a.example.com", "b.example.com"},
+		Ports:   []int{80, 443},
+		Paths:   []string{"/usr/bin", "/bin"},
+		Tags:    []string{"a", "b"},
+		Empty:   []string{},
+		Timeout: []time.Duration{time.Second, 2 * time.Second},
+		Words:   []string{"one", "two"},
+		Lines:   []string{"a, b", "c"},
+		Pairs:   []string{"a;b", "c"},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	cfg, err = Load[config](WithConfigFile(path), WithEnvLookup(lookup), WithEnvOverrides("APP"))
+	if err != nil {
+		t.Fatalf("Load with env overrides: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Extra, []string{"x", "y"}) || !reflect.DeepEqual(cfg.Paths, []string{"/opt", "/srv"}) {
+		t.Fatalf("unexpected env overrides: %+v", cfg)
+	}
+
+	path = writeConfig(t, "ports: 80,http\n")
+	if _, err := Load[config](WithConfigFile(path)); err == nil || !strings.Contains(err.Error(), "item 1") {
+		t.Fatalf("expected an error for item 1, got %v", err)
+	}
+}
+
+// flakySource fails the first failures fetches, and blocks until the
+// context is done if hang is set.
+type flakySource struct {
+	failures int
+	hang     bool
+	calls    int
+}
+
+func (s *flakySource) Fetch(ctx context.Context) ([]byte, error) {
+	s.calls++
+	if s.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if s.calls <= s.failures {
+		return nil, errors.New("connection refused")
+	}
+	return []byte("app_name: api\n"), nil
+}
+
+func TestLoad_WithSourceRetries(t *testing.T) {
+	src := &flakySource{failures: 2}
+	cfg, err := Load[testConfig](WithSource(src), WithSourceRetries(2))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "api" || src.calls != 3 {
+		t.Fatalf("got %q after %d calls", cfg.AppName, src.calls)
+	}
+
+	src = &flakySource{failures: 5}
+	if _, err := Load[testConfig](WithSource(src), WithSourceRetries(1)); err == nil || src.calls != 2 {
+		t.Fatalf("expected an error after 2 calls, got %v after %d", err, src.calls)
+	}
+}
+
+func TestLoad_WithSourceTimeout(t *testing.T) {
+	src := &flakySource{hang: true}
+	start := time.Now()
+	_, err := Load[testConfig](WithSource(src), WithSourceTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out after 50ms") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Load took %s", elapsed)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("TEST_CHECK_APP=svc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := writeConfig(t, "app_name: ${TEST_CHECK_APP}\nserver:\n  port: 8080\n")
+
+	report, err := Check[testConfig](WithConfigFile(path), WithDotenv(envPath))
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if report.Origins["app_name"].File == "" {
+		t.Fatalf("expected an origin for app_name, got %+v", report.Origins)
+	}
+	if _, ok := os.LookupEnv("TEST_CHECK_APP"); ok {
+		t.Fatalf("Check must not set dotenv vars in the process environment")
+	}
+
+	// Check is strict.
+	_, err = Check[testConfig](WithConfigFile(path))
+	var missing *MissingEnvError
+	if !errors.As(err, &missing) || missing.Vars[0].Name != "TEST_CHECK_APP" {
+		t.Fatalf("expected MissingEnvError for TEST_CHECK_APP, got %v", err)
+	}
+
+	// Validation runs too.
+	_, err = Check[testConfig](WithConfigFile(path), WithDotenv(envPath), WithValidator(func(any) error {
+		return errors.New("port 8080 is reserved")
+	}))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// logging.go
+package gonfig
+
+import (
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// WithLogger logs how the config is resolved to logger, at debug level:
+// which config and dotenv files were read or skipped, every env var looked
+// up and whether it was set, warnings, and finally every value with where
+// it came from (file and line, default, env var, override or flag). It is
+// the first thing to turn on when a value isn't what you expect.
+//
+// Env var values are never logged, and config values are masked like Dump
+// masks them: Secret values and fields tagged `gonfig:"secret"` show as
+// "***".
+//
+// Example:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithLogger(logger),
+//	)
+func WithLogger(logger *slog.Logger) Option {
+	return func(l *loader) {
+		l.logger = logger
+	}
+}
+
+// debug logs msg at debug level, if WithLogger is set.
+func (l *loader) debug(msg string, args ...any) {
+	if l.logger != nil {
+		l.logger.DebugContext(l.ctx, msg, args...)
+	}
+}
+
+// logEnvLookup logs a lookup of the env var name, without its value.
+func (l *loader) logEnvLookup(name string, ok bool) {
+	if l.logger == nil {
+		return
+	}
+	args := []any{"name", name, "set", ok}
+	if file, fromDotenv := l.dotenvOrigin[name]; fromDotenv && ok {
+		args = append(args, "dotenv", file)
+	} else if _, fromSecrets := l.secretEnv[name]; fromSecrets && ok {
+		args = append(args, "secrets_dir", true)
+	}
+	l.debug("gonfig: env lookup", args...)
+}
+
+// logValues logs every value in report.Origins with its origin. Values are
+// read from cfg, which was loaded at root, with secrets masked.
+func (l *loader) logValues(cfg reflect.Value, root string, report Report) {
+	if l.logger == nil || !l.logger.Enabled(l.ctx, slog.LevelDebug) {
+		return
+	}
+	doc, err := (&dumper{}).encode(cfg.Interface())
+	if err != nil {
+		l.debug("gonfig: can't log values", "error", err)
+		return
+	}
+	view := View{node: doc}
+	paths := make([]string, 0, len(report.Origins))
+	for p := range report.Origins {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		rel := p
+		if root != "" {
+			rel = strings.TrimPrefix(strings.TrimPrefix(p, root), ".")
+		}
+		l.debug("gonfig: value", "path", p, "value", view.GetString(rel), "origin", report.Origins[p].String())
+	}
+}
@@ -0,0 +1,58 @@
+package gonfig
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_WithLogger(t *testing.T) {
+	type config struct {
+		Server   testServerConfig `yaml:"server"`
+		Region   string           `yaml:"region" default:"eu-west-1"`
+		Password Secret           `yaml:"password"`
+		Token    string           `yaml:"token" gonfig:"secret"`
+	}
+	path := writeConfig(t, "server:\n  port: 8080\n  log_level: ${LOG_LEVEL}\npassword: ${DB_PASSWORD}\ntoken: tok-123\n")
+	dotenv := filepath.Join(filepath.Dir(path), ".env")
+	if err := os.WriteFile(dotenv, []byte("DB_PASSWORD=hunter2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := Load[config](
+		WithConfigFile(path),
+		WithDotenv(dotenv),
+		WithEnvLookup(func(string) (string, bool) { return "", false }),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`msg="gonfig: loaded dotenv" file=` + dotenv,
+		`msg="gonfig: read config" source=` + path,
+		`msg="gonfig: env lookup" name=LOG_LEVEL set=false`,
+		`msg="gonfig: env lookup" name=DB_PASSWORD set=true dotenv=` + dotenv,
+		`msg="gonfig: value" path=region value=eu-west-1 origin=default`,
+		`msg="gonfig: value" path=server.port value=8080 origin=` + path + `:2:9`,
+		`msg="gonfig: value" path=password value=*** origin="` + path + `:4:11 via DB_PASSWORD (` + dotenv + `)"`,
+		`msg="gonfig: value" path=token value=***`,
+		`msg="gonfig: warning"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("log is missing %q:\n%s", want, out)
+		}
+	}
+	for _, secret := range []string{"hunter2", "tok-123"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("log leaks %q:\n%s", secret, out)
+		}
+	}
+}
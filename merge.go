@@ -0,0 +1,191 @@
+// merge.go
+package gonfig
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeStrategy decides how a value from a later config layer (a profile
+// file or a later file in a config dir) is combined with the value an
+// earlier layer set at the same path.
+type MergeStrategy struct {
+	kind mergeKind
+	key  string
+}
+
+type mergeKind int
+
+const (
+	mergeDeep mergeKind = iota
+	mergeReplace
+	mergeAppend
+	mergeByKey
+)
+
+var (
+	// MergeDeep is the default: mappings are merged key by key, anything
+	// else (including lists) is replaced.
+	MergeDeep = MergeStrategy{kind: mergeDeep}
+	// MergeReplace replaces the earlier value as a whole, mappings
+	// included.
+	MergeReplace = MergeStrategy{kind: mergeReplace}
+	// MergeAppend appends the items of a later list to the earlier list.
+	MergeAppend = MergeStrategy{kind: mergeAppend}
+)
+
+// MergeByKey merges lists of mappings item by item, matching items on the
+// value of key: items with the same key are deep-merged, new items are
+// appended. Items without the key are always appended.
+func MergeByKey(key string) MergeStrategy {
+	return MergeStrategy{kind: mergeByKey, key: key}
+}
+
+// WithMergeStrategy sets how later config layers combine with earlier ones
+// at the YAML paths matching pattern. Patterns use dot segments like Redact:
+// "*" matches any single key or list index, so "services.*.ports" covers
+// the ports of every service. When several patterns match, the last one
+// added wins.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigDir("conf.d"),
+//	    gonfig.WithMergeStrategy("servers", gonfig.MergeByKey("name")),
+//	    gonfig.WithMergeStrategy("allowed_origins", gonfig.MergeAppend),
+//	)
+func WithMergeStrategy(pattern string, s MergeStrategy) Option {
+	return func(l *loader) {
+		l.mergeRules = append(l.mergeRules, mergeRule{pattern: strings.Split(pattern, "."), strategy: s})
+	}
+}
+
+type mergeRule struct {
+	pattern  []string
+	strategy MergeStrategy
+}
+
+// merger merges config layers according to the WithMergeStrategy rules.
+type merger []mergeRule
+
+func (m merger) strategy(segs []string) MergeStrategy {
+	for i := len(m) - 1; i >= 0; i-- {
+		if matchSegs(m[i].pattern, segs) {
+			return m[i].strategy
+		}
+	}
+	return MergeDeep
+}
+
+// merge merges the node src on top of dst and returns the result. Nodes
+// shared through YAML anchors are copied rather than modified.
+func (m merger) merge(dst, src *yaml.Node, segs []string) *yaml.Node {
+	if dst.Kind == 0 {
+		return src
+	}
+	if src.Kind == 0 {
+		return dst
+	}
+	if dst.Kind == yaml.DocumentNode && src.Kind == yaml.DocumentNode {
+		if len(src.Content) == 0 {
+			return dst
+		}
+		if len(dst.Content) == 0 {
+			return src
+		}
+		out := *dst
+		out.Content = []*yaml.Node{m.merge(dst.Content[0], src.Content[0], segs)}
+		return &out
+	}
+
+	d, s := resolveAlias(dst), resolveAlias(src)
+	strategy := m.strategy(segs)
+	switch {
+	case strategy.kind == mergeReplace:
+		return src
+	case d.Kind == yaml.MappingNode && s.Kind == yaml.MappingNode:
+		return m.mergeMappings(d, s, segs)
+	case d.Kind == yaml.SequenceNode && s.Kind == yaml.SequenceNode && strategy.kind == mergeAppend:
+		out := *d
+		out.Content = append(append([]*yaml.Node(nil), d.Content...), s.Content...)
+		return &out
+	case d.Kind == yaml.SequenceNode && s.Kind == yaml.SequenceNode && strategy.kind == mergeByKey:
+		return m.mergeByKey(d, s, strategy.key, segs)
+	}
+	return src
+}
+
+func (m merger) mergeMappings(d, s *yaml.Node, segs []string) *yaml.Node {
+	out := *d
+	out.Content = append([]*yaml.Node(nil), d.Content...)
+	for i := 0; i+1 < len(s.Content); i += 2 {
+		k, v := s.Content[i], s.Content[i+1]
+		if j := mappingIndex(&out, k.Value); j >= 0 && k.Tag != "!!merge" {
+			out.Content[j+1] = m.merge(out.Content[j+1], v, appendSeg(segs, k.Value))
+			continue
+		}
+		out.Content = append(out.Content, k, v)
+	}
+	return &out
+}
+
+func (m merger) mergeByKey(d, s *yaml.Node, key string, segs []string) *yaml.Node {
+	out := *d
+	out.Content = append([]*yaml.Node(nil), d.Content...)
+	for _, item := range s.Content {
+		id, ok := itemKey(item, key)
+		if !ok {
+			out.Content = append(out.Content, item)
+			continue
+		}
+		matched := false
+		for i, existing := range out.Content {
+			if existingID, ok := itemKey(existing, key); ok && existingID == id {
+				out.Content[i] = m.merge(existing, item, appendSeg(segs, strconv.Itoa(i)))
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			out.Content = append(out.Content, item)
+		}
+	}
+	return &out
+}
+
+// itemKey returns the scalar value of key in the list item n.
+func itemKey(n *yaml.Node, key string) (string, bool) {
+	n = resolveAlias(n)
+	if n.Kind != yaml.MappingNode {
+		return "", false
+	}
+	i := mappingIndex(n, key)
+	if i < 0 {
+		return "", false
+	}
+	v := resolveAlias(n.Content[i+1])
+	if v.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return v.Value, true
+}
+
+// resolveAlias follows alias nodes to the node they refer to.
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// mappingIndex returns the index of key's key node in the mapping n, or -1.
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key && n.Content[i].Tag != "!!merge" {
+			return i
+		}
+	}
+	return -1
+}
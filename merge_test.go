@@ -0,0 +1,83 @@
+package gonfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad_MergeStrategies(t *testing.T) {
+	type server struct {
+		Name string `yaml:"name"`
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+	}
+	type config struct {
+		Servers []server          `yaml:"servers"`
+		Origins []string          `yaml:"origins"`
+		Tags    []string          `yaml:"tags"`
+		Labels  map[string]string `yaml:"labels"`
+		Limits  map[string]int    `yaml:"limits"`
+	}
+
+	path := writeConfig(t, `
+servers:
+  - {name: a, host: a.local, port: 80}
+  - {name: b, host: b.local, port: 80}
+origins: [x]
+tags: [t1]
+labels: {team: core, tier: "1"}
+limits: {rps: 10, burst: 20}
+`)
+	prod := filepath.Join(filepath.Dir(path), "config.prod.yaml")
+	if err := os.WriteFile(prod, []byte(`
+servers:
+  - {name: b, port: 443}
+  - {name: c, host: c.local}
+origins: [y]
+tags: [t2]
+labels: {tier: "2"}
+limits: {rps: 50}
+`), 0o644); err != nil {
+		t.Fatalf("write profile: %v", err)
+	}
+
+	cfg, report, err := LoadWithReport[config](
+		WithConfigFile(path),
+		WithProfile("prod"),
+		WithMergeStrategy("servers", MergeByKey("name")),
+		WithMergeStrategy("origins", MergeAppend),
+		WithMergeStrategy("limits", MergeReplace),
+	)
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+
+	wantServers := []server{
+		{Name: "a", Host: "a.local", Port: 80},
+		{Name: "b", Host: "b.local", Port: 443},
+		{Name: "c", Host: "c.local"},
+	}
+	if !reflect.DeepEqual(cfg.Servers, wantServers) {
+		t.Fatalf("servers: got %+v, want %+v", cfg.Servers, wantServers)
+	}
+	if !reflect.DeepEqual(cfg.Origins, []string{"x", "y"}) {
+		t.Fatalf("origins: got %v", cfg.Origins)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"t2"}) {
+		t.Fatalf("tags: got %v", cfg.Tags)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string]string{"team": "core", "tier": "2"}) {
+		t.Fatalf("labels: got %v", cfg.Labels)
+	}
+	if !reflect.DeepEqual(cfg.Limits, map[string]int{"rps": 50}) {
+		t.Fatalf("limits: got %v", cfg.Limits)
+	}
+	if got := report.Origins["servers[1].port"].File; got != prod {
+		t.Fatalf("servers[1].port from %q, want %q", got, prod)
+	}
+	if got := report.Origins["servers[1].host"].File; got != path {
+		t.Fatalf("servers[1].host from %q, want %q", got, path)
+	}
+}
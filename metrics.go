@@ -0,0 +1,40 @@
+// metrics.go
+package gonfig
+
+import "time"
+
+// Metrics receives measurements of config loading, for export to a
+// monitoring system. Package github.com/TypeTerrors/gonfig/prometheus
+// implements it for Prometheus; see WithMetrics.
+//
+// Methods should not block. SourceFetched may be called concurrently, as
+// layers are fetched in parallel.
+type Metrics interface {
+	// SourceFetched is called after every fetch of a config layer, with
+	// the layer's name (e.g. "config.yaml" or "s3://bucket/config.yaml"),
+	// how long the fetch took and its error, if any. Missing optional
+	// layers, such as an absent profile file, are not reported.
+	SourceFetched(source string, took time.Duration, err error)
+	// Loaded is called after every load, with its error, if any. reload
+	// is true for the background reloads of Watch and NewLive, and false
+	// for their initial load and for Load and its variants.
+	Loaded(reload bool, err error)
+}
+
+// WithMetrics reports measurements of loading and reloading the config to
+// m, so fleets running on stale or failing config can be alerted on.
+//
+// Example:
+//
+//	collector := gonfigprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithMetrics(collector),
+//	)
+func WithMetrics(m Metrics) Option {
+	return func(l *loader) {
+		l.metrics = m
+	}
+}
@@ -0,0 +1,119 @@
+// migrate.go
+package gonfig
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MigrationFunc upgrades a config document from one version to the next by
+// editing it in place, e.g. renaming or moving keys. doc is the whole file
+// with placeholders not yet expanded.
+type MigrationFunc func(doc map[string]any) error
+
+type migration struct {
+	to int
+	fn MigrationFunc
+}
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = map[int]migration{}
+)
+
+// RegisterMigration registers fn to upgrade config files from version from
+// to version to, so files written for an older layout keep loading after
+// the layout changes. The version is the top-level `version:` key; files
+// without one count as version 0, except overlays (profile files and the
+// files after the first in a directory), which are only migrated when they
+// set a version.
+//
+// When a file is loaded, migrations are chained from its version for as
+// long as one is registered, then version is set to the last version
+// reached. Every migrated file produces a WarnMigrated warning so users
+// know to update it. Migrations are global: register them during init.
+// RegisterMigration panics if to isn't greater than from.
+//
+// Example:
+//
+//	func init() {
+//	    // v2 moved listen_port to server.port.
+//	    gonfig.RegisterMigration(1, 2, func(doc map[string]any) error {
+//	        if port, ok := doc["listen_port"]; ok {
+//	            delete(doc, "listen_port")
+//	            server, _ := doc["server"].(map[string]any)
+//	            if server == nil {
+//	                server = map[string]any{}
+//	            }
+//	            server["port"] = port
+//	            doc["server"] = server
+//	        }
+//	        return nil
+//	    })
+//	}
+func RegisterMigration(from, to int, fn MigrationFunc) {
+	if to <= from {
+		panic(fmt.Sprintf("gonfig: migration from version %d to %d must go forward", from, to))
+	}
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	migrations[from] = migration{to: to, fn: fn}
+}
+
+// migrate upgrades doc to the latest registered version. It returns the
+// versions it migrated between, and from == to if nothing was done.
+// Migrated documents are re-encoded, so their nodes lose their positions.
+func migrate(doc *yaml.Node) (from, to int, err error) {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+	if len(migrations) == 0 || doc.Kind == 0 {
+		return 0, 0, nil
+	}
+
+	from = docVersion(doc)
+	if _, ok := migrations[from]; !ok {
+		return from, from, nil
+	}
+	var m map[string]any
+	if err := doc.Decode(&m); err != nil {
+		return 0, 0, err
+	}
+	if m == nil {
+		m = make(map[string]any)
+	}
+	to = from
+	for {
+		next, ok := migrations[to]
+		if !ok {
+			break
+		}
+		if err := next.fn(m); err != nil {
+			return 0, 0, fmt.Errorf("migrate from version %d to %d: %w", to, next.to, err)
+		}
+		to = next.to
+	}
+	m["version"] = to
+
+	var out yaml.Node
+	if err := out.Encode(m); err != nil {
+		return 0, 0, err
+	}
+	*doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&out}}
+	return from, to, nil
+}
+
+// docVersion returns the top-level version of doc, or 0 if it has none.
+func docVersion(doc *yaml.Node) int {
+	n := nodeAt(doc, []string{"version"})
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return 0
+	}
+	v, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return 0
+	}
+	return v
+}
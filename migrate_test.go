@@ -0,0 +1,79 @@
+package gonfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Migrations(t *testing.T) {
+	RegisterMigration(0, 2, func(doc map[string]any) error {
+		if port, ok := doc["listen_port"]; ok {
+			delete(doc, "listen_port")
+			doc["server"] = map[string]any{"port": port}
+		}
+		return nil
+	})
+	RegisterMigration(2, 3, func(doc map[string]any) error {
+		if name, ok := doc["name"]; ok {
+			delete(doc, "name")
+			doc["app_name"] = name
+		}
+		return nil
+	})
+	t.Cleanup(func() {
+		migrationsMu.Lock()
+		defer migrationsMu.Unlock()
+		migrations = map[int]migration{}
+	})
+
+	type config struct {
+		Version int              `yaml:"version"`
+		AppName string           `yaml:"app_name"`
+		Server  testServerConfig `yaml:"server"`
+	}
+	t.Setenv("GONFIG_TEST_PORT", "8080")
+
+	path := writeConfig(t, "name: svc\nlisten_port: ${GONFIG_TEST_PORT}\n")
+	cfg, report, err := LoadWithReport[config](WithConfigFile(path), WithKnownFieldsOnly())
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg != (config{Version: 3, AppName: "svc", Server: testServerConfig{Port: 8080}}) {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Kind != WarnMigrated ||
+		!strings.Contains(report.Warnings[0].Message, "from version 0 to 3") {
+		t.Fatalf("unexpected warnings: %v", report.Warnings)
+	}
+
+	// Current files are left alone.
+	path = writeConfig(t, "version: 3\napp_name: svc\n")
+	_, report, err = LoadWithReport[config](WithConfigFile(path))
+	if err != nil || len(report.Warnings) != 0 {
+		t.Fatalf("current file: warnings=%v err=%v", report.Warnings, err)
+	}
+	if got := report.Origins["app_name"].Line; got != 2 {
+		t.Fatalf("current files must keep positions, got line %d", got)
+	}
+
+	// Overlays without a version are partial files, not version 0 ones.
+	profile := strings.TrimSuffix(path, ".yaml") + ".prod.yaml"
+	replaceFile(t, profile, "server:\n  port: 9090\n")
+	cfg, report, err = LoadWithReport[config](WithConfigFile(path), WithProfile("prod"))
+	if err != nil || len(report.Warnings) != 0 {
+		t.Fatalf("overlay: warnings=%v err=%v", report.Warnings, err)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Fatalf("overlay: unexpected config: %+v", cfg)
+	}
+	if o := report.Origins["server.port"]; o.File != profile || o.Line != 2 {
+		t.Fatalf("overlays must keep positions, got %+v", o)
+	}
+
+	RegisterMigration(3, 4, func(map[string]any) error { return errors.New("boom") })
+	var pe *ParseError
+	if _, err := Load[config](WithConfigFile(path)); !errors.As(err, &pe) || !strings.Contains(err.Error(), "version 3 to 4: boom") {
+		t.Fatalf("expected a migration ParseError, got %v", err)
+	}
+}
@@ -1,6 +1,12 @@
 // options.go
 package gonfig
 
+import (
+	"io"
+	"io/fs"
+	"time"
+)
+
 // WithConfigFile sets the path to the YAML config file.
 //
 // The default is "config.yaml" in the working directory.
@@ -11,9 +17,7 @@ package gonfig
 //	    gonfig.WithConfigFile("config/config.yaml"),
 //	)
 func WithConfigFile(path string) Option {
-	return func(l *loader) {
-		l.configFile = path
-	}
+	return WithSource(fileSource(path))
 }
 
 // WithDotenv adds a .env file to be loaded before parsing the YAML config.
@@ -22,7 +26,8 @@ func WithConfigFile(path string) Option {
 // environment variables.
 //
 // Missing .env files are ignored, so it is safe to pass a file that only
-// exists on your machine.
+// exists on your machine. Its values override variables that are already
+// set; see WithDotenvNoOverride.
 //
 // Example:
 //
@@ -32,7 +37,112 @@ func WithConfigFile(path string) Option {
 //	)
 func WithDotenv(path string) Option {
 	return func(l *loader) {
-		l.dotenvs = append(l.dotenvs, path)
+		l.dotenvs = append(l.dotenvs, dotenvFile{path: path})
+	}
+}
+
+// WithDotenvNoOverride adds a .env file like WithDotenv, except that
+// variables already set in the real environment keep their value, like
+// godotenv.Load. Use it where the process environment is authoritative,
+// e.g. in CI, so a stale .env can't shadow it.
+//
+// Later dotenv files (including profile variants) still override earlier
+// ones.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithDotenvNoOverride(".env"),
+//	)
+func WithDotenvNoOverride(path string) Option {
+	return func(l *loader) {
+		l.dotenvs = append(l.dotenvs, dotenvFile{path: path, keepEnv: true})
+	}
+}
+
+// WithDotenvOverride sets whether dotenv files override variables that are
+// set in the real environment. It defaults to true; false makes every
+// WithDotenv file behave like WithDotenvNoOverride.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithDotenv(".env"),
+//	    gonfig.WithDotenvOverride(os.Getenv("CI") == ""),
+//	)
+func WithDotenvOverride(override bool) Option {
+	return func(l *loader) {
+		l.dotenvKeepEnv = !override
+	}
+}
+
+// WithProfile layers environment-specific files on top of the base config.
+// With profile "prod":
+//
+//   - config.yaml is loaded first, then config.prod.yaml is deep-merged on
+//     top of it: mappings are merged key by key, and any other value in the
+//     profile file replaces the base value (see WithMergeStrategy).
+//   - every dotenv file is followed by its profile variant, so .env is
+//     loaded before .env.prod and the profile's values win.
+//
+// Missing profile files are ignored. Env overrides (WithEnvOverrides) still
+// take precedence over both files. Only file-backed sources (WithConfigFile
+// and WithFS) have profile files, and an empty name adds no layers.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithDotenv(".env"),
+//	    gonfig.WithProfile(os.Getenv("APP_ENV")),
+//	)
+func WithProfile(name string) Option {
+	return func(l *loader) {
+		l.profile = name
+	}
+}
+
+// WithSection loads only the subtree at a YAML path, e.g. "database" or
+// "services.billing", into T, for tools that care about one section of a
+// shared config file. Paths in errors, warnings and the Report stay full
+// paths ("database.host"), env override names are unchanged
+// (APP_DATABASE_HOST), and WithOverride takes full paths too. A missing
+// section loads like an empty config.
+//
+// Example:
+//
+//	db, err := gonfig.Load[DatabaseConfig](
+//	    gonfig.WithConfigFile("/etc/app/config.yaml"),
+//	    gonfig.WithSection("database"),
+//	)
+func WithSection(path string) Option {
+	return func(l *loader) {
+		l.section = path
+	}
+}
+
+// WithEnvLookup makes gonfig read env vars through fn instead of the
+// process environment, both for ${VAR} placeholders and for
+// WithEnvOverrides. Dotenv files are layered on top of fn without touching
+// the process environment.
+//
+// This keeps config tests hermetic, so they can run in parallel without
+// t.Setenv.
+//
+// Example:
+//
+//	env := map[string]string{"DB_PASSWORD": "test"}
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("testdata/config.yaml"),
+//	    gonfig.WithEnvLookup(func(name string) (string, bool) {
+//	        v, ok := env[name]
+//	        return v, ok
+//	    }),
+//	)
+func WithEnvLookup(fn func(name string) (string, bool)) Option {
+	return func(l *loader) {
+		l.getenv = fn
 	}
 }
 
@@ -44,6 +154,9 @@ func WithDotenv(path string) Option {
 //
 // Non-strict mode (the default) replaces missing ${VAR} with an empty string.
 //
+// Genuinely optional vars can be exempted one by one: write the placeholder
+// as ${?VAR}, or list the names with WithOptionalVars.
+//
 // Example:
 //
 //	// config.yaml:
@@ -56,7 +169,440 @@ func WithDotenv(path string) Option {
 //	    gonfig.WithStrict(), // fails if DB_PASSWORD is not set
 //	)
 func WithStrict() Option {
+	return WithMissingPolicy(MissingError)
+}
+
+// MissingPolicy says what happens to a ${VAR} placeholder whose variable is
+// unset and that has no default.
+type MissingPolicy int
+
+const (
+	// MissingEmpty expands the placeholder to "" and reports a warning.
+	// This is the default.
+	MissingEmpty MissingPolicy = iota
+	// MissingKeep leaves the placeholder as-is, e.g. for output that goes
+	// through another templating stage, and reports a warning.
+	MissingKeep
+	// MissingError fails the load, like WithStrict.
+	MissingError
+)
+
+// WithMissingPolicy sets what Load does with placeholders whose variable is
+// unset and that have no default. ${VAR:?message} always fails, and
+// ${?VAR} and WithOptionalVars always expand to "".
+//
+// WithStrict is WithMissingPolicy(MissingError); the last of the two wins.
+//
+// Example:
+//
+//	// config.yaml:
+//	//   alert_template: "Disk full on ${HOSTNAME}"   # filled in by the alerter
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithMissingPolicy(gonfig.MissingKeep),
+//	)
+func WithMissingPolicy(p MissingPolicy) Option {
+	return func(l *loader) {
+		l.strict = p == MissingError
+		l.keepMissing = p == MissingKeep
+	}
+}
+
+// WithOptionalVars exempts the named env vars from WithStrict: when unset,
+// their placeholders expand to "" without an error or a warning, like the
+// ${?VAR} syntax does for a single placeholder. ${VAR:?message} still fails.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithStrict(),
+//	    gonfig.WithOptionalVars("SENTRY_DSN", "FEATURE_TOKEN"),
+//	)
+func WithOptionalVars(names ...string) Option {
+	return func(l *loader) {
+		if l.optionalVars == nil {
+			l.optionalVars = make(map[string]bool)
+		}
+		for _, name := range names {
+			l.optionalVars[name] = true
+		}
+	}
+}
+
+// WithBareVariables also expands POSIX-style $VAR placeholders, as
+// envsubst does, in addition to ${VAR}. The name runs up to the first
+// character that isn't a letter, digit or underscore, so "$HOME/data" and
+// "$USER.log" work; "$$VAR" is a literal "$VAR". A bare $VAR has no
+// default syntax, and is treated like ${VAR} in strict mode.
+//
+// Off by default, because "$" followed by a letter is common in passwords
+// and regular expressions.
+//
+// Example:
+//
+//	// config.yaml:
+//	//   data_dir: $HOME/.cache/app
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithBareVariables(),
+//	)
+func WithBareVariables() Option {
+	return func(l *loader) {
+		l.bareVariables = true
+	}
+}
+
+// WithRecursiveExpansion expands placeholders inside env var values too, so
+// composite vars built from other vars work:
+//
+//	// env: DB_HOST=db.internal DB_URL=postgres://${DB_HOST}:5432/app
+//	// config.yaml:
+//	//   database:
+//	//     url: ${DB_URL}   # postgres://db.internal:5432/app
+//
+// Expansion follows references up to 10 levels deep. A cycle such as
+// A=${B} and B=${A} fails with a *EnvCycleError naming the chain, wrapped
+// in a *ResolveError pointing at the config value. Values returned by
+// resolvers (${scheme:key}) are never expanded further.
+func WithRecursiveExpansion() Option {
+	return func(l *loader) {
+		l.recursiveExpansion = true
+	}
+}
+
+// WithEnvOverrides lets environment variables override any config value
+// after the YAML has been unmarshalled.
+//
+// The variable name is the prefix followed by the YAML path of the field,
+// upper-cased and joined with underscores. With prefix "APP", the key
+// server.port is overridden by APP_SERVER_PORT. Pass an empty prefix to use
+// the bare path (SERVER_PORT).
+//
+// String fields take the value verbatim; other fields are parsed as YAML
+// scalars, so "9090", "true" and "30s" work for int, bool and
+// time.Duration fields.
+//
+// Example:
+//
+//	// APP_SERVER_PORT=9090 overrides server.port from config.yaml
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithEnvOverrides("APP"),
+//	)
+func WithEnvOverrides(prefix string) Option {
+	return func(l *loader) {
+		l.envOverrides = true
+		l.envPrefix = prefix
+	}
+}
+
+// WithConfigDir reads the config from every *.yaml and *.yml file in dir
+// instead of a single file, as packaging tools do with conf.d directories.
+// Files are deep-merged in lexical order of their names, so later files
+// win: mappings are merged key by key and any other value replaces the
+// earlier one, unless WithMergeStrategy says otherwise. Hidden files are
+// ignored, and an empty directory is an empty config.
+//
+// Example:
+//
+//	// conf.d/00-base.yaml, conf.d/10-db.yaml, conf.d/99-local.yaml
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigDir("conf.d"),
+//	)
+func WithConfigDir(dir string) Option {
+	return WithSource(dirSource(dir))
+}
+
+// WithFS reads the config file from fsys instead of the OS filesystem.
+//
+// This is mainly useful for configs embedded into the binary with go:embed.
+// The path follows io/fs rules: slash-separated and relative to the root of
+// fsys. Dotenv files are still read from disk.
+//
+// Example:
+//
+//	//go:embed config.yaml
+//	var configFS embed.FS
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithFS(configFS, "config.yaml"),
+//	)
+func WithFS(fsys fs.FS, path string) Option {
+	return WithSource(fsSource{fsys: fsys, path: path})
+}
+
+// WithReader reads the config from r instead of a file.
+//
+// The reader is drained the first time the config is loaded and its bytes
+// are kept, so Watch, NewLive and WithRefreshInterval reload the same
+// document. This is useful for config received
+// over the wire or generated in memory; expansion and validation work
+// exactly as they do for files.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithReader(resp.Body),
+//	    gonfig.WithStrict(),
+//	)
+func WithReader(r io.Reader) Option {
+	return WithSource(&readerSource{r: r})
+}
+
+// WithBytes uses data as the raw config instead of reading a file.
+//
+// Handy in tests, where the config can live next to the assertions.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithBytes([]byte("server:\n  port: 8080\n")),
+//	)
+func WithBytes(data []byte) Option {
+	return WithSource(bytesSource(data))
+}
+
+// WithKnownFieldsOnly makes Load fail when the YAML contains keys that don't
+// map onto a field of the target struct.
+//
+// By default unknown keys are silently ignored, so a typo like "prot:"
+// instead of "port:" leaves the field at its zero value. With this option the
+// error names every offending key by its YAML path and position, e.g.
+// "unknown config keys: server.prot (line 3, column 3)".
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithKnownFieldsOnly(),
+//	)
+func WithKnownFieldsOnly() Option {
+	return func(l *loader) {
+		l.knownFieldsOnly = true
+	}
+}
+
+// WithWeakTypes lets quoted strings decode into bool, integer and float
+// fields, so `port: "${PORT}"` or `ratio: "1.5"` work as if they were
+// unquoted. Bools accept what strconv.ParseBool does ("1", "t", "TRUE", ...).
+//
+// Without it, a quoted value is always a string and decoding it into a
+// number fails. Strings that don't parse still fail, and fields with a
+// decoder or their own unmarshal method are left to it.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithWeakTypes(),
+//	)
+func WithWeakTypes() Option {
+	return func(l *loader) {
+		l.weakTypes = true
+	}
+}
+
+// WithSourceTimeout bounds every fetch of a config source, so a hanging
+// remote source fails startup after d instead of blocking it. Sources must
+// honour the context passed to Fetch, as all built-in ones do. Combined
+// with WithSourceRetries, d bounds each attempt. The default is no
+// timeout beyond the LoadContext context.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    consul.WithKey("services/api/config.yaml"),
+//	    gonfig.WithSourceTimeout(5*time.Second),
+//	    gonfig.WithSourceRetries(2),
+//	)
+func WithSourceTimeout(d time.Duration) Option {
+	return func(l *loader) {
+		l.sourceTimeout = d
+	}
+}
+
+// WithSourceRetries retries failed fetches of any config source up to n
+// more times, with exponential backoff starting at 200ms. Missing and
+// oversized documents are not retried. WithConfigURL sources retry on
+// their own with WithURLRetries; these retries come on top.
+func WithSourceRetries(n int) Option {
+	return func(l *loader) {
+		l.sourceRetries = n
+	}
+}
+
+// WithReloadErrorHandler sets a function that is called whenever a background
+// reload started by Watch or NewLive fails.
+//
+// Failed reloads never replace the current config; the handler is the place
+// to log them or bump an error metric. It is called from the watcher
+// goroutine and should not block.
+//
+// Example:
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithReloadErrorHandler(func(err error) {
+//	        log.Printf("config reload failed: %v", err)
+//	    }),
+//	)
+func WithReloadErrorHandler(fn func(error)) Option {
+	return func(l *loader) {
+		l.onReloadError = fn
+	}
+}
+
+// WithReloadDebounce sets how long Watch and NewLive wait for changes to
+// settle before reloading. Every change within d of the previous one
+// restarts the wait, so an editor writing a file in several chunks, or a
+// deploy touching several files, causes a single reload of the finished
+// result. It defaults to 100ms; 0 reloads on every change.
+//
+// Example:
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithReloadDebounce(500*time.Millisecond),
+//	)
+func WithReloadDebounce(d time.Duration) Option {
+	return func(l *loader) {
+		l.reloadDebounce = d
+	}
+}
+
+// WithRefreshInterval makes Watch and NewLive also reload every d, whether or
+// not a watched file changed. Each reload calls the resolvers again, so
+// rotated secrets (${vault:...}, ${ssm:...}) propagate without a restart;
+// use Live.OnChangeAt to react to the fields that changed. Reloads that
+// change nothing don't notify anyone.
+//
+// With an interval, sources that can't be watched (WithReader, WithBytes,
+// WithFS) work with Watch and NewLive too; they are simply re-read every d,
+// which for WithReader and WithBytes only calls the resolvers again: the
+// reader is read once and its bytes reused.
+// A ResolverCache in front of a resolver should have a TTL shorter than d,
+// or reloads keep getting the cached value.
+//
+// Example:
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithResolver("vault", vaultResolver),
+//	    gonfig.WithRefreshInterval(5*time.Minute),
+//	)
+//	live.OnChangeAt("database.password", func(gonfig.Changes) {
+//	    pool.Reconnect(live.Get().Database)
+//	})
+func WithRefreshInterval(d time.Duration) Option {
+	return func(l *loader) {
+		l.refreshInterval = d
+	}
+}
+
+// WithWarnHandler calls fn for every non-fatal problem found while loading,
+// such as unknown keys or ${VAR}s that expanded to "" outside strict mode,
+// as soon as it is found. The same warnings are collected in the Report
+// returned by LoadWithReport.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithWarnHandler(func(w gonfig.Warning) {
+//	        log.Printf("config warning: %s", w)
+//	    }),
+//	)
+func WithWarnHandler(fn func(Warning)) Option {
+	return func(l *loader) {
+		l.onWarn = fn
+	}
+}
+
+// WithResolver registers fn to resolve placeholders of the form
+// ${scheme:key}, e.g. ${vault:secret/db#password} or ${ssm:/app/prod/token}.
+//
+// The text after "scheme:" is passed to fn as the key. The usual operators
+// still apply: ${vault:secret/db#password:-dev} falls back to "dev" when fn
+// returns ErrNotFound. Names whose prefix is not a registered scheme are
+// looked up in the environment as before.
+//
+// The "file" scheme is built in (${file:/run/secrets/db_password} reads the
+// trimmed file contents); registering "file" replaces it.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithResolver("vault", func(ctx context.Context, key string) (string, error) {
+//	        return vaultClient.Read(ctx, key)
+//	    }),
+//	)
+func WithResolver(scheme string, fn ResolverFunc) Option {
+	return func(l *loader) {
+		if l.resolvers == nil {
+			l.resolvers = make(map[string]ResolverFunc)
+		}
+		l.resolvers[scheme] = fn
+	}
+}
+
+// WithAgeIdentity adds an age identity file (as written by age-keygen) used
+// to decrypt encrypted config and dotenv files.
+//
+// Any config file or dotenv whose name ends in ".age" (config.yaml.age,
+// .env.prod.age) is decrypted in memory before it is parsed; both binary and
+// ASCII-armored files are accepted. The option can be repeated to try
+// several identities.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config/config.yaml.age"),
+//	    gonfig.WithDotenv(".env.prod.age"),
+//	    gonfig.WithAgeIdentity("/etc/myapp/age.key"),
+//	)
+func WithAgeIdentity(path string) Option {
+	return func(l *loader) {
+		l.ageIdentityFiles = append(l.ageIdentityFiles, path)
+	}
+}
+
+// WithConfigURL fetches the config over HTTP(S) instead of reading a file.
+//
+// Use URLOptions to add auth headers, bound each attempt with a timeout,
+// retry transient failures, and keep a local fallback copy for when the
+// config service is unreachable. Repeated loads through Watch or NewLive
+// send If-None-Match / If-Modified-Since and reuse the cached body on 304.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigURL("https://config.internal/my-service.yaml",
+//	        gonfig.WithURLHeader("Authorization", "Bearer "+os.Getenv("CONFIG_TOKEN")),
+//	        gonfig.WithURLTimeout(5*time.Second),
+//	        gonfig.WithURLRetries(3),
+//	        gonfig.WithURLFallbackFile("/var/cache/my-service/config.yaml"),
+//	    ),
+//	)
+func WithConfigURL(url string, opts ...URLOption) Option {
+	return WithSource(newURLSource(url, opts))
+}
+
+// WithValidator runs fn on the decoded config after required fields are
+// checked and before the config's own Validate() method. A non-nil error
+// fails Load with a *ValidationError wrapping it; return *FieldErrors (joined
+// with errors.Join) to point at the offending fields. With WithSection,
+// FieldError paths are relative to the section and are prefixed with its
+// path.
+//
+// fn receives the config value, e.g. a Config rather than a *Config. The
+// gonfig/validate subpackage uses this hook for go-playground/validator
+// tags.
+func WithValidator(fn func(cfg any) error) Option {
 	return func(l *loader) {
-		l.strict = true
+		l.validators = append(l.validators, fn)
 	}
 }
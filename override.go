@@ -0,0 +1,255 @@
+// override.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyEnvOverrides walks the decoded config and replaces every value whose
+// derived env var name is set according to env. path is the YAML path of v
+// ("" for the whole config). record, if non-nil, is called with the YAML
+// path and env var name of every overridden value.
+//
+// Names are built from the prefix and the YAML path of the field, upper-cased
+// and joined with underscores: with prefix "APP", server.log_level maps to
+// APP_SERVER_LOG_LEVEL. A field with an `env:"NAME"` tag uses that name
+// instead, or the first one set of `env:"NAME,OLD_NAME"`.
+func applyEnvOverrides(v reflect.Value, prefix, path string, env func(string) (string, bool), record func(path, name string)) error {
+	o := envOverrider{env: env, record: record, derive: true}
+	name := envPrefix(prefix)
+	for _, seg := range splitPath(path) {
+		name = joinEnvName(name, seg)
+	}
+	return o.value(v, name, path)
+}
+
+// applyEnvTags is applyEnvOverrides for fields with an `env:"NAME"` tag
+// only, used when WithEnvOverrides is off.
+func applyEnvTags(v reflect.Value, path string, env func(string) (string, bool), record func(path, name string)) error {
+	return envOverrider{env: env, record: record}.value(v, "", path)
+}
+
+func envPrefix(prefix string) string {
+	return strings.ToUpper(strings.TrimRight(prefix, "_"))
+}
+
+// envOverrider holds the state of a single applyEnvOverrides call. Without
+// derive, only fields with an env tag are looked up.
+type envOverrider struct {
+	env    func(string) (string, bool)
+	record func(path, name string)
+	derive bool
+}
+
+// child returns the env var name for key below name, or "" without derive.
+func (o envOverrider) child(name, key string) string {
+	if !o.derive {
+		return ""
+	}
+	return joinEnvName(name, key)
+}
+
+func (o envOverrider) value(v reflect.Value, name, path string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			// Nothing was configured for this section; leave it alone.
+			return nil
+		}
+		return o.value(v.Elem(), name, path)
+	case reflect.Struct:
+		if isLeafType(v.Type()) {
+			return o.leaf(v, name, path, setFromString)
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			key, inline, skip := yamlFieldName(f)
+			if skip {
+				continue
+			}
+			fieldName, fieldPath := name, path
+			if !inline {
+				fieldName, fieldPath = o.child(name, key), joinPath(path, key)
+			}
+			if tag := f.Tag.Get("env"); tag != "" {
+				set := setFromString
+				if fn, ok := fieldDecoder(f); ok {
+					set = func(v reflect.Value, s string) error { return setDecoded(v, fn, s) }
+				}
+				if err := o.leaf(v.Field(i), o.firstSet(tag), fieldPath, set); err != nil {
+					return err
+				}
+				continue
+			}
+			if fn, ok := fieldDecoder(f); ok {
+				set := func(v reflect.Value, s string) error { return setDecoded(v, fn, s) }
+				if err := o.leaf(v.Field(i), fieldName, fieldPath, set); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := o.value(v.Field(i), fieldName, fieldPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.IsNil() {
+			return o.leaf(v, name, path, setFromString)
+		}
+		for _, k := range v.MapKeys() {
+			// Map elements are not addressable: copy, override, store back.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(k))
+			elemName, elemPath := o.child(name, k.String()), joinPath(path, k.String())
+			if elem.Kind() == reflect.Interface && !elem.IsNil() {
+				inner := reflect.New(elem.Elem().Type()).Elem()
+				inner.Set(elem.Elem())
+				if err := o.value(inner, elemName, elemPath); err != nil {
+					return err
+				}
+				elem.Set(inner)
+			} else if err := o.value(elem, elemName, elemPath); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, elem)
+		}
+		return nil
+	default:
+		return o.leaf(v, name, path, setFromString)
+	}
+}
+
+// firstSet returns the first of the comma-separated names in an env tag
+// that is set, or "" if none is, so `env:"NEW_DB_URL,DATABASE_URL"` falls
+// back to the old name.
+func (o envOverrider) firstSet(tag string) string {
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := o.env(name); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// leaf sets v from the env var name if it is present. With setFromString,
+// strings are taken verbatim and everything else is decoded as a YAML
+// scalar, so "9090", "true" and "30s" land in int, bool and time.Duration
+// fields.
+func (o envOverrider) leaf(v reflect.Value, name, path string, set func(reflect.Value, string) error) error {
+	if name == "" || !v.CanSet() {
+		return nil
+	}
+	val, ok := o.env(name)
+	if !ok {
+		return nil
+	}
+	if err := set(v, val); err != nil {
+		return fmt.Errorf("env override %s: %w", name, err)
+	}
+	if o.record != nil {
+		o.record(path, name)
+	}
+	return nil
+}
+
+// setFromString sets v from a string written by hand in an env var or a
+// struct tag. Strings are taken verbatim, lists may be written as "a,b,c",
+// and everything else is decoded as YAML.
+func setFromString(v reflect.Value, val string) error {
+	if fn, ok := decoderFor(v.Type()); ok {
+		return setDecoded(v, fn, val)
+	}
+	if v.Kind() == reflect.String {
+		v.SetString(val)
+		return nil
+	}
+	if t, ok := listType(v.Type()); ok && !strings.HasPrefix(strings.TrimSpace(val), "[") {
+		return setDecoded(v, listDecoder(t, ","), val)
+	}
+	ptr := reflect.New(v.Type())
+	if err := yaml.Unmarshal([]byte(val), ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+// isLeafType reports whether a struct type should be treated as a single
+// value rather than walked field by field (e.g. time.Time or a type with a
+// registered decoder).
+func isLeafType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	if _, ok := decoderFor(t); ok {
+		return true
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlFieldName returns the YAML key yaml.v3 uses for f, whether the field is
+// inlined (`yaml:",inline"` or `gonfig:"squash"`), and whether it is
+// skipped entirely.
+func yamlFieldName(f reflect.StructField) (key string, inline, skip bool) {
+	tag := f.Tag.Get("yaml")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, p := range parts[1:] {
+		if p == "inline" {
+			inline = true
+		}
+	}
+	inline = inline || hasTagOption(f, "squash")
+	key = parts[0]
+	if key == "" {
+		key = strings.ToLower(f.Name)
+	}
+	return key, inline, false
+}
+
+// hasTagOption reports whether opt is one of the comma-separated options in
+// f's gonfig tag, e.g. `gonfig:"required,secret"`.
+func hasTagOption(f reflect.StructField, opt string) bool {
+	for _, o := range strings.Split(f.Tag.Get("gonfig"), ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// joinEnvName appends a YAML key to an env var name, upper-casing it and
+// replacing anything that isn't a letter or digit with an underscore.
+func joinEnvName(prefix, key string) string {
+	seg := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, key)
+	if prefix == "" {
+		return seg
+	}
+	return prefix + "_" + seg
+}
@@ -0,0 +1,139 @@
+// placeholders.go
+package gonfig
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// PlaceholderInfo describes a ${VAR} placeholder found by Placeholders.
+type PlaceholderInfo struct {
+	// Name is the variable name, e.g. "DB_PASSWORD". Resolver placeholders
+	// keep their scheme: "vault:secret/db#password", "file:/run/secrets/x".
+	Name string
+	// File is the config layer the placeholder is in.
+	File string
+	// Path is the YAML path of the value holding the placeholder, e.g.
+	// "database.password".
+	Path string
+	// Line and Column are the 1-based position of the placeholder's "$".
+	Line   int
+	Column int
+	// HasDefault is set for ${VAR:-default} and ${VAR:+alternate}, which
+	// don't need VAR to be set.
+	HasDefault bool
+	// Optional is set for ${?VAR} and the vars named by WithOptionalVars,
+	// which may stay unset even in strict mode.
+	Optional bool
+	// Required is set for ${VAR:?message}, which fails the load when VAR is
+	// unset even outside strict mode.
+	Required bool
+	// Nested is set for placeholders inside another one's default or
+	// alternate, e.g. REDIS_HOST in ${CACHE_HOST:-${REDIS_HOST}}. They are
+	// only used when the outer variable is unset (or set, for :+).
+	Nested bool
+}
+
+// Placeholders lists every ${VAR} placeholder in the config documents
+// selected by opts (WithConfigFile, WithBytes, WithConfigDir, WithProfile,
+// ...), in document order, without resolving or loading anything else. It
+// is meant for deploy tooling that checks the environment before rolling
+// out:
+//
+//	refs, err := gonfig.Placeholders(gonfig.WithConfigFile("config.yaml"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, r := range refs {
+//	    if _, ok := os.LookupEnv(r.Name); !ok && !r.HasDefault && !r.Optional && !r.Nested {
+//	        log.Printf("%s:%d: %s is not set", r.File, r.Line, r.Name)
+//	    }
+//	}
+//
+// Like expansion, it skips literal (|) and folded (>) block scalars and
+// escaped $${VAR}s; with WithBareVariables it also reports $VAR.
+// Encrypted and verified documents are decrypted and verified first.
+func Placeholders(opts ...Option) ([]PlaceholderInfo, error) {
+	l := newLoader(opts)
+	layers, err := l.layers()
+	if err != nil {
+		return nil, err
+	}
+	var out []PlaceholderInfo
+	for i, f := range l.fetchLayers(layers) {
+		doc, err := l.parseLayer(layers[i], f)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		walkScalars(doc, func(n *yaml.Node, path func() string) {
+			if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+				return
+			}
+			scanPlaceholders(n.Value, 0, l.bareVariables, false, func(p PlaceholderInfo, offset int) {
+				p.File = layers[i].name
+				p.Path = path()
+				p.Line, p.Column = position(n, offset)
+				p.Optional = p.Optional || l.optionalVars[p.Name] && !p.Required
+				out = append(out, p)
+			})
+		})
+	}
+	return out, nil
+}
+
+// scanPlaceholders calls fn for every placeholder in s, which starts at
+// byte offset base of the scalar value, including those nested in defaults
+// and alternates. It follows the syntax of expander.expandAt.
+func scanPlaceholders(s string, base int, bare, nested bool, fn func(p PlaceholderInfo, offset int)) {
+	x := &expander{mode: expandMode{bare: bare}}
+	i := 0
+	for {
+		j := x.nextPlaceholder(s[i:])
+		if j == -1 {
+			return
+		}
+		start := i + j
+		escaped := start > i && s[start-1] == '$'
+
+		if s[start+1] != '{' {
+			end := start + 1
+			for end < len(s) && isNameChar(s[end], end == start+1) {
+				end++
+			}
+			if !escaped {
+				fn(PlaceholderInfo{Name: s[start+1 : end], Nested: nested}, base+start)
+			}
+			i = end
+			continue
+		}
+
+		end := matchBrace(s, start+2)
+		if end == -1 {
+			return
+		}
+		i = end + 1
+		inner := s[start+2 : end]
+		if escaped || inner == "" {
+			continue
+		}
+		argBase := base + start + 2
+		optional := inner[0] == '?' && len(inner) > 1
+		if optional {
+			inner = inner[1:]
+			argBase++
+		}
+		name, op, arg := splitPlaceholder(inner)
+		fn(PlaceholderInfo{
+			Name:       name,
+			HasDefault: op == ":-" || op == ":+",
+			Optional:   optional && op != ":?",
+			Required:   op == ":?",
+			Nested:     nested,
+		}, base+start)
+		if arg != "" {
+			scanPlaceholders(arg, argBase+len(name)+len(op), bare, true, fn)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// placeholders_test.go
+package gonfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	path := writeConfig(t, `app_name: ${APP_NAME}
+server:
+  port: "${PORT:-8080}"
+  log_level: ${LOG_LEVEL:?set a log level}
+hosts:
+  - ${CACHE_HOST:-${REDIS_HOST:-localhost}}:$PORT
+  - $${NOT_A_VAR}
+password: ${vault:secret/db#password}
+script: |
+  echo ${IGNORED}
+`)
+
+	got, err := Placeholders(WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Placeholders: %v", err)
+	}
+	want := []PlaceholderInfo{
+		{Name: "APP_NAME", File: path, Path: "app_name", Line: 1, Column: 11},
+		{Name: "PORT", File: path, Path: "server.port", Line: 3, Column: 10, HasDefault: true},
+		{Name: "LOG_LEVEL", File: path, Path: "server.log_level", Line: 4, Column: 14, Required: true},
+		{Name: "CACHE_HOST", File: path, Path: "hosts[0]", Line: 6, Column: 5, HasDefault: true},
+		{Name: "REDIS_HOST", File: path, Path: "hosts[0]", Line: 6, Column: 19, HasDefault: true, Nested: true},
+		{Name: "vault:secret/db#password", File: path, Path: "password", Line: 8, Column: 11},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+
+	got, err = Placeholders(WithConfigFile(path), WithBareVariables())
+	if err != nil {
+		t.Fatalf("Placeholders: %v", err)
+	}
+	if len(got) != 7 || got[5].Name != "PORT" || got[5].Column != 45 {
+		t.Fatalf("expected bare $PORT at 6:45, got %+v", got)
+	}
+}
+
+func TestPlaceholders_Optional(t *testing.T) {
+	got, err := Placeholders(WithBytes([]byte("a: ${?A:-${B}}\nc: ${C}\n")), WithOptionalVars("C"))
+	if err != nil {
+		t.Fatalf("Placeholders: %v", err)
+	}
+	want := []PlaceholderInfo{
+		{Name: "A", Path: "a", Line: 1, Column: 4, HasDefault: true, Optional: true},
+		{Name: "B", Path: "a", Line: 1, Column: 10, Nested: true},
+		{Name: "C", Path: "c", Line: 2, Column: 4, Optional: true},
+	}
+	for i := range got {
+		got[i].File = ""
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestPlaceholders_Bytes(t *testing.T) {
+	got, err := Placeholders(WithBytes([]byte("a: ${A}\n")))
+	if err != nil {
+		t.Fatalf("Placeholders: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "A" || got[0].Path != "a" {
+		t.Fatalf("got %+v", got)
+	}
+}
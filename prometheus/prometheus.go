@@ -0,0 +1,120 @@
+// Package prometheus exports gonfig load and reload metrics to Prometheus.
+//
+// A Collector implements both gonfig.Metrics and prometheus.Collector:
+// pass it to gonfig.WithMetrics and register it with a Prometheus
+// registry.
+//
+// Usage:
+//
+//	import (
+//	    "github.com/prometheus/client_golang/prometheus"
+//
+//	    "github.com/TypeTerrors/gonfig"
+//	    gonfigprom "github.com/TypeTerrors/gonfig/prometheus"
+//	)
+//
+//	collector := gonfigprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithMetrics(collector),
+//	)
+//
+// It exports:
+//
+//	gonfig_reloads_total{result="success"|"failure"}
+//	gonfig_last_reload_success                   1 if the last load or reload succeeded, else 0
+//	gonfig_last_reload_success_timestamp_seconds time of the last successful load or reload
+//	gonfig_source_fetch_duration_seconds{source, result="success"|"failure"}
+//
+// so a fleet running on stale or failing config can be alerted on with
+// rules like gonfig_last_reload_success == 0.
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// Collector collects gonfig metrics. Use one Collector per config; to
+// export several from one process, register each with its own labels, e.g.
+// through prometheus.WrapRegistererWith.
+type Collector struct {
+	reloads     *prometheus.CounterVec
+	lastSuccess prometheus.Gauge
+	lastLoaded  prometheus.Gauge
+	fetch       *prometheus.HistogramVec
+}
+
+var (
+	_ gonfig.Metrics       = (*Collector)(nil)
+	_ prometheus.Collector = (*Collector)(nil)
+)
+
+// NewCollector returns a Collector with all metrics at zero.
+func NewCollector() *Collector {
+	return &Collector{
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gonfig_reloads_total",
+			Help: "Background config reloads by Watch or NewLive, by result.",
+		}, []string{"result"}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gonfig_last_reload_success",
+			Help: "Whether the last config load or reload succeeded (1) or failed (0).",
+		}),
+		lastLoaded: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "gonfig_last_reload_success_timestamp_seconds",
+			Help: "Unix time of the last successful config load or reload.",
+		}),
+		fetch: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gonfig_source_fetch_duration_seconds",
+			Help:    "Time taken to fetch a config source, by source and result.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"source", "result"}),
+	}
+}
+
+// SourceFetched implements gonfig.Metrics.
+func (c *Collector) SourceFetched(source string, took time.Duration, err error) {
+	c.fetch.WithLabelValues(source, result(err)).Observe(took.Seconds())
+}
+
+// Loaded implements gonfig.Metrics.
+func (c *Collector) Loaded(reload bool, err error) {
+	if reload {
+		c.reloads.WithLabelValues(result(err)).Inc()
+	}
+	if err != nil {
+		c.lastSuccess.Set(0)
+		return
+	}
+	c.lastSuccess.Set(1)
+	c.lastLoaded.SetToCurrentTime()
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.reloads.Describe(ch)
+	c.lastSuccess.Describe(ch)
+	c.lastLoaded.Describe(ch)
+	c.fetch.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.reloads.Collect(ch)
+	c.lastSuccess.Collect(ch)
+	c.lastLoaded.Collect(ch)
+	c.fetch.Collect(ch)
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
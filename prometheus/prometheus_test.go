@@ -0,0 +1,69 @@
+package prometheus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+func TestCollector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("port: 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCollector()
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	type config struct {
+		Port int `yaml:"port"`
+	}
+	if _, err := gonfig.Load[config](gonfig.WithConfigFile(path), gonfig.WithMetrics(c)); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := testutil.ToFloat64(c.lastSuccess); got != 1 {
+		t.Fatalf("last success = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(c.lastLoaded); got == 0 {
+		t.Fatalf("last success timestamp not set")
+	}
+	if got := testutil.CollectAndCount(c.fetch); got != 1 {
+		t.Fatalf("fetch series = %d, want 1", got)
+	}
+
+	_, err := gonfig.Load[config](gonfig.WithConfigFile(filepath.Join(t.TempDir(), "missing.yaml")), gonfig.WithMetrics(c))
+	if err == nil {
+		t.Fatalf("Load of a missing file succeeded")
+	}
+	if got := testutil.ToFloat64(c.lastSuccess); got != 0 {
+		t.Fatalf("last success after failure = %v, want 0", got)
+	}
+	if got := testutil.CollectAndCount(c.fetch); got != 2 {
+		t.Fatalf("fetch series = %d, want 2 (success and failure)", got)
+	}
+	if got := testutil.CollectAndCount(c.reloads); got != 0 {
+		t.Fatalf("initial loads counted as reloads")
+	}
+
+	c.Loaded(true, nil)
+	c.Loaded(true, errors.New("bad config"))
+	c.Loaded(true, errors.New("bad config"))
+	if got := testutil.ToFloat64(c.reloads.WithLabelValues("success")); got != 1 {
+		t.Fatalf("successful reloads = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(c.reloads.WithLabelValues("failure")); got != 2 {
+		t.Fatalf("failed reloads = %v, want 2", got)
+	}
+
+	if _, err := reg.Gather(); err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+// report.go
+package gonfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Report describes how a config was loaded. It is returned by
+// LoadWithReport.
+type Report struct {
+	// Origins maps the YAML path of every value, e.g. "server.port" or
+	// "servers[0].host", to where the value came from. Later layers win:
+	// a value set by an env override is reported as such even if the file
+	// set it too.
+	Origins map[string]Origin
+
+	// Warnings are problems that didn't fail the load, in the order they
+	// were found.
+	Warnings []Warning
+}
+
+// WarningKind classifies a Warning.
+type WarningKind int
+
+const (
+	// WarnUnknownKey is a config key with no matching struct field.
+	// WithKnownFieldsOnly turns these into errors.
+	WarnUnknownKey WarningKind = iota + 1
+	// WarnEmptyExpansion is a ${VAR} without a default whose var is not
+	// set, expanded to "" outside strict mode (or left as-is with
+	// MissingKeep).
+	WarnEmptyExpansion
+	// WarnDeprecated is a key renamed with `gonfig:"alias=..."` that is
+	// still in use, or a key tagged `gonfig:"deprecated=..."`.
+	WarnDeprecated
+	// WarnMigrated is a config file with an old version that was upgraded
+	// by migrations registered with RegisterMigration.
+	WarnMigrated
+	// WarnValidation is a warning added by a ValidateWithReport hook, e.g.
+	// a deprecated combination of settings.
+	WarnValidation
+)
+
+// Warning is a non-fatal problem found while loading a config.
+type Warning struct {
+	Kind WarningKind
+	// File, Path, Line and Column locate the problem in the config source.
+	File   string
+	Path   string
+	Line   int
+	Column int
+	// Message describes the problem, e.g. "unknown key".
+	Message string
+}
+
+// String formats w like "config.yaml:3:3: server.prot: unknown key".
+func (w Warning) String() string {
+	loc := w.File
+	if w.Line > 0 {
+		loc = fmt.Sprintf("%s:%d:%d", w.File, w.Line, w.Column)
+	}
+	if w.Path == "" {
+		return fmt.Sprintf("%s: %s", loc, w.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, w.Path, w.Message)
+}
+
+// Warn adds a WarnValidation warning about the value at path. It is meant
+// for ValidateWithReport hooks, which can warn about settings that are
+// allowed but unwise without failing the load:
+//
+//	func (c *Config) ValidateWithReport(r *gonfig.Report) error {
+//	    if c.TLS.Enabled && c.TLS.MinVersion == "1.0" {
+//	        r.Warn("tls.min_version", "TLS 1.0 is deprecated and will be rejected in v3")
+//	    }
+//	    return nil
+//	}
+//
+// The warning is located at the value's place in the config file, if it
+// came from there.
+func (r *Report) Warn(path, message string) {
+	r.Warnings = append(r.Warnings, Warning{Kind: WarnValidation, Path: path, Message: message})
+}
+
+// locate fills in the kind of a warning added by ValidateWithReport, and
+// its file and position from the origin of its path. Warnings about values
+// that didn't come from a file are put in file.
+func (r *Report) locate(w Warning, file string) Warning {
+	if w.Kind == 0 {
+		w.Kind = WarnValidation
+	}
+	if w.File != "" {
+		return w
+	}
+	if o, ok := r.Origins[w.Path]; ok && o.Kind == FromFile {
+		w.File, w.Line, w.Column = o.File, o.Line, o.Column
+	} else {
+		w.File = file
+	}
+	return w
+}
+
+// OriginKind is the layer a config value came from.
+type OriginKind int
+
+const (
+	// FromDefault is a `default:"..."` struct tag.
+	FromDefault OriginKind = iota + 1
+	// FromFile is the config source, possibly with placeholders expanded.
+	FromFile
+	// FromEnv is an env var applied by WithEnvOverrides.
+	FromEnv
+	// FromOverride is a value set with WithOverride or WithValues.
+	FromOverride
+	// FromFlag is a command-line flag registered by WithFlagSet.
+	FromFlag
+)
+
+// Origin is where a single config value came from.
+type Origin struct {
+	Kind OriginKind
+	// File, Line and Column locate the value in the config source
+	// (FromFile).
+	File   string
+	Line   int
+	Column int
+	// Vars are the env vars the value was expanded from (FromFile), the
+	// env var that overrode it (FromEnv) or the flag that set it
+	// (FromFlag). Only vars that were set are listed; ${scheme:key} names
+	// of resolvers are always listed.
+	Vars []string
+	// Dotenv maps the entries of Vars that were set by a dotenv file to
+	// that file.
+	Dotenv map[string]string
+}
+
+// String formats o for humans, e.g.
+//
+//	config.yaml:12:3 via DB_HOST (.env.dev)
+//	env APP_SERVER_PORT
+//	default
+func (o Origin) String() string {
+	var s string
+	switch o.Kind {
+	case FromDefault:
+		return "default"
+	case FromOverride:
+		return "override"
+	case FromFile:
+		s = fmt.Sprintf("%s:%d:%d", o.File, o.Line, o.Column)
+		if len(o.Vars) > 0 {
+			s += " via "
+		}
+	case FromEnv:
+		s = "env "
+	case FromFlag:
+		s = "flag "
+	default:
+		return "unknown"
+	}
+	vars := make([]string, len(o.Vars))
+	for i, v := range o.Vars {
+		vars[i] = v
+		if file, ok := o.Dotenv[v]; ok {
+			vars[i] += " (" + file + ")"
+		}
+	}
+	return s + strings.Join(vars, ", ")
+}
+
+// recordFileOrigins adds a FromFile origin for every scalar value in the
+// expanded doc. orig holds the scalar values before expansion, to find the
+// placeholders each value was expanded from.
+func (l *loader) recordFileOrigins(r *Report, n *yaml.Node, path string, orig map[*yaml.Node]string) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			l.recordFileOrigins(r, c, path, orig)
+		}
+	case yaml.AliasNode:
+		l.recordFileOrigins(r, n.Alias, path, orig)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				l.recordFileOrigins(r, v, path, orig)
+				continue
+			}
+			l.recordFileOrigins(r, v, joinPath(path, k.Value), orig)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			l.recordFileOrigins(r, c, path+"["+strconv.Itoa(i)+"]", orig)
+		}
+	case yaml.ScalarNode:
+		o := Origin{Kind: FromFile, File: l.fileOf(n), Line: n.Line, Column: n.Column}
+		for _, name := range placeholderNames(orig[n]) {
+			if strings.Contains(name, ":") {
+				o.Vars = append(o.Vars, name)
+			} else if _, ok := l.lookupEnv(name); ok {
+				o.Vars = append(o.Vars, name)
+			}
+		}
+		o.Dotenv = l.dotenvFiles(o.Vars)
+		r.Origins[path] = o
+	}
+}
+
+// recordEnvOrigin adds a FromEnv origin for an env override.
+func (l *loader) recordEnvOrigin(r *Report, path, name string) {
+	r.Origins[path] = Origin{Kind: FromEnv, Vars: []string{name}, Dotenv: l.dotenvFiles([]string{name})}
+}
+
+// dotenvFiles returns the dotenv file each of vars was set by, or nil.
+func (l *loader) dotenvFiles(vars []string) map[string]string {
+	var files map[string]string
+	for _, v := range vars {
+		if file, ok := l.dotenvOrigin[v]; ok {
+			if files == nil {
+				files = make(map[string]string)
+			}
+			files[v] = file
+		}
+	}
+	return files
+}
+
+// placeholderNames returns the names of the top-level ${...} placeholders
+// in s, skipping $${...} escapes.
+func placeholderNames(s string) []string {
+	var names []string
+	for i := 0; ; {
+		j := strings.Index(s[i:], "${")
+		if j == -1 {
+			return names
+		}
+		start := i + j
+		end := matchBrace(s, start+2)
+		if end == -1 {
+			return names
+		}
+		i = end + 1
+		if start > 0 && s[start-1] == '$' {
+			continue
+		}
+		if name, _, _ := splitPlaceholder(s[start+2 : end]); name != "" {
+			names = append(names, name)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadWithReport(t *testing.T) {
+	type config struct {
+		AppName string           `yaml:"app_name"`
+		Region  string           `yaml:"region" default:"eu-west-1"`
+		Server  testServerConfig `yaml:"server"`
+		Hosts   []string         `yaml:"hosts"`
+	}
+	dotenv := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(dotenv, []byte("GONFIG_TEST_LEVEL=debug\n"), 0o644); err != nil {
+		t.Fatalf("write dotenv: %v", err)
+	}
+	t.Setenv("GONFIG_TEST_LEVEL", "")
+	t.Setenv("APP_SERVER_PORT", "9090")
+
+	path := writeConfig(t, "app_name: ${GONFIG_TEST_NAME:-svc}\nserver:\n  port: 8080\n  log_level: ${GONFIG_TEST_LEVEL}\nhosts: [a, b]\n")
+	_, report, err := LoadWithReport[config](WithConfigFile(path), WithDotenv(dotenv), WithEnvOverrides("APP"))
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+
+	for p, want := range map[string]string{
+		"region":           "default",
+		"app_name":         path + ":1:11",
+		"server.log_level": path + ":4:14 via GONFIG_TEST_LEVEL (" + dotenv + ")",
+		"server.port":      "env APP_SERVER_PORT",
+		"hosts[1]":         path + ":5:12",
+	} {
+		if got := report.Origins[p].String(); got != want {
+			t.Fatalf("origin of %s: got %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestLoad_Warnings(t *testing.T) {
+	var streamed []Warning
+	_, report, err := LoadWithReport[testConfig](
+		WithBytes([]byte("server:\n  prot: 8080\n  log_level: ${GONFIG_TEST_UNSET_VAR}\n")),
+		WithWarnHandler(func(w Warning) { streamed = append(streamed, w) }),
+	)
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if len(report.Warnings) != 2 || len(streamed) != 2 {
+		t.Fatalf("expected 2 warnings, got %v (streamed %v)", report.Warnings, streamed)
+	}
+	for _, want := range []string{
+		"<bytes>:3:14: server.log_level: GONFIG_TEST_UNSET_VAR is not set; expanded to an empty string",
+		"<bytes>:2:3: server.prot: unknown key",
+	} {
+		found := false
+		for _, w := range report.Warnings {
+			found = found || w.String() == want
+		}
+		if !found {
+			t.Fatalf("expected warning %q, got %v", want, report.Warnings)
+		}
+	}
+}
+
+func TestLoad_DeprecatedAliases(t *testing.T) {
+	type server struct {
+		Port     int    `yaml:"port" gonfig:"alias=listen_port,deprecated=use server.port"`
+		LogLevel string `yaml:"log_level" gonfig:"alias=verbosity"`
+		Debug    bool   `yaml:"debug" gonfig:"deprecated=set log_level to debug instead"`
+	}
+	type config struct {
+		Servers []server `yaml:"servers"`
+	}
+
+	path := writeConfig(t, "servers:\n  - listen_port: 8080\n    verbosity: info\n    log_level: warn\n    debug: true\n  - port: 9090\n")
+	cfg, report, err := LoadWithReport[config](WithConfigFile(path), WithKnownFieldsOnly())
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if got := cfg.Servers[0]; got.Port != 8080 || got.LogLevel != "warn" || !got.Debug {
+		t.Fatalf("unexpected server: %+v", got)
+	}
+	if got := report.Origins["servers[0].port"].String(); got != path+":2:18" {
+		t.Fatalf("origin of servers[0].port: got %q", got)
+	}
+
+	want := []string{
+		path + ":2:5: servers[0].listen_port: deprecated: use server.port",
+		path + ":3:5: servers[0].verbosity: deprecated, use servers[0].log_level (ignored, log_level is set)",
+		path + ":5:5: servers[0].debug: deprecated: set log_level to debug instead",
+	}
+	if len(report.Warnings) != len(want) {
+		t.Fatalf("got %d warnings, want %d: %v", len(report.Warnings), len(want), report.Warnings)
+	}
+	for i, w := range report.Warnings {
+		if w.Kind != WarnDeprecated || w.String() != want[i] {
+			t.Fatalf("warning %d: got %q (kind %v), want %q", i, w, w.Kind, want[i])
+		}
+	}
+}
+
+type afterLoadConfig struct {
+	BaseURL string   `yaml:"base_url"`
+	Parsed  *url.URL `yaml:"-"`
+	Source  string   `yaml:"-"`
+}
+
+func (c *afterLoadConfig) AfterLoad(report Report) error {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return fmt.Errorf("base_url (%s): %w", report.Origins["base_url"], err)
+	}
+	c.Parsed = u
+	c.Source = report.Origins["base_url"].String()
+	return nil
+}
+
+func TestLoad_AfterLoad(t *testing.T) {
+	path := writeConfig(t, "base_url: https://api.example.com/v1\n")
+	cfg, err := Load[afterLoadConfig](WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Parsed == nil || cfg.Parsed.Host != "api.example.com" || cfg.Source != path+":1:11" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	path = writeConfig(t, "base_url: \"://bad\"\n")
+	_, err = Load[afterLoadConfig](WithConfigFile(path))
+	var ve *ValidationError
+	if !errors.As(err, &ve) || !strings.Contains(err.Error(), path+":1:11") {
+		t.Fatalf("expected a ValidationError naming the origin, got %v", err)
+	}
+}
+
+type reportValidatorConfig struct {
+	TLS struct {
+		Enabled    bool   `yaml:"enabled"`
+		MinVersion string `yaml:"min_version"`
+	} `yaml:"tls"`
+	Workers int `yaml:"workers"`
+}
+
+func (c *reportValidatorConfig) ValidateWithReport(r *Report) error {
+	if c.TLS.Enabled && c.TLS.MinVersion == "1.0" {
+		r.Warn("tls.min_version", "TLS 1.0 is deprecated")
+	}
+	if c.Workers == 0 {
+		r.Warn("workers", "will be required in v3")
+	}
+	if c.Workers < 0 {
+		return errors.New("workers: must not be negative")
+	}
+	return nil
+}
+
+func TestLoad_ValidateWithReport(t *testing.T) {
+	path := writeConfig(t, "tls:\n  enabled: true\n  min_version: \"1.0\"\n")
+	var streamed []Warning
+	_, report, err := LoadWithReport[reportValidatorConfig](WithConfigFile(path),
+		WithWarnHandler(func(w Warning) { streamed = append(streamed, w) }))
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	want := []string{
+		path + ":3:16: tls.min_version: TLS 1.0 is deprecated",
+		path + ": workers: will be required in v3",
+	}
+	if len(report.Warnings) != 2 || len(streamed) != 2 {
+		t.Fatalf("warnings = %v, streamed %v", report.Warnings, streamed)
+	}
+	for i, w := range report.Warnings {
+		if w.Kind != WarnValidation || w.String() != want[i] {
+			t.Fatalf("warning %d = %v (%q), want %q", i, w.Kind, w.String(), want[i])
+		}
+	}
+
+	_, err = Load[reportValidatorConfig](WithBytes([]byte("workers: -1\n")))
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+}
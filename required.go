@@ -0,0 +1,94 @@
+// required.go
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ErrRequired is wrapped by the FieldError for a required field that is
+// still the zero value after loading.
+var ErrRequired = errors.New("required field is not set")
+
+// checkRequired returns a FieldError wrapping ErrRequired for every field
+// tagged `gonfig:"required"` or `required:"true"` that holds its zero value.
+//
+// Optional sections are respected: fields behind a nil pointer, or inside
+// empty slices and maps, are not checked.
+func checkRequired(v reflect.Value, path string) []error {
+	return checkFields(v, path, func(f reflect.StructField, fv reflect.Value, path string) error {
+		if isRequired(f) && fv.IsZero() {
+			return &FieldError{Path: path, Err: ErrRequired}
+		}
+		return nil
+	})
+}
+
+// checkFields calls check for every exported struct field reachable from v,
+// with the field's YAML path, and returns the errors. Fields check fails
+// for aren't descended into. Nil pointers, empty slices and maps are
+// skipped, like for checkRequired, and map entries are walked in key order.
+func checkFields(v reflect.Value, path string, check func(f reflect.StructField, fv reflect.Value, path string) error) []error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return checkFields(v.Elem(), path, check)
+	case reflect.Slice, reflect.Array:
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, checkFields(v.Index(i), fmt.Sprintf("%s[%d]", path, i), check)...)
+		}
+		return errs
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		var errs []error
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			errs = append(errs, checkFields(v.MapIndex(k), joinPath(path, k.String()), check)...)
+		}
+		return errs
+	case reflect.Struct:
+		if isLeafType(v.Type()) {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key, inline, skip := yamlFieldName(f)
+		if skip {
+			continue
+		}
+		fieldPath := path
+		if !inline {
+			fieldPath = joinPath(path, key)
+		}
+		fv := v.Field(i)
+		if err := check(f, fv, fieldPath); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, checkFields(fv, fieldPath, check)...)
+	}
+	return errs
+}
+
+// isRequired reports whether f is tagged `gonfig:"required"` or
+// `required:"true"`.
+func isRequired(f reflect.StructField) bool {
+	return f.Tag.Get("required") == "true" || hasTagOption(f, "required")
+}
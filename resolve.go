@@ -0,0 +1,212 @@
+// resolve.go
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
+)
+
+// ResolverFunc resolves the key of a ${scheme:key} placeholder to a value.
+//
+// Return an error wrapping ErrNotFound when the key doesn't exist; the
+// placeholder is then treated like an unset env var, so defaults
+// (${vault:secret/db#password:-dev}) and strict mode apply. Any other error
+// aborts Load.
+//
+// The placeholders of a document are resolved concurrently, so a
+// ResolverFunc must be safe for concurrent use.
+type ResolverFunc func(ctx context.Context, key string) (string, error)
+
+// ErrNotFound is returned (possibly wrapped) by a ResolverFunc when the
+// requested key does not exist.
+var ErrNotFound = errors.New("not found")
+
+// lookup resolves a placeholder name. Names of the form scheme:key go to the
+// resolver registered for scheme; everything else is an env var.
+func (l *loader) lookup(name string) (string, bool, error) {
+	if r, ok := l.resolved[name]; ok {
+		return r.val, r.ok, nil
+	}
+	if i := strings.IndexByte(name, ':'); i > 0 {
+		if fn, ok := l.resolvers[name[:i]]; ok {
+			end := l.startSpan("gonfig.resolve",
+				attribute.String("gonfig.resolver", name[:i]),
+				attribute.String("gonfig.key", name[i+1:]),
+			)
+			val, err := fn(l.ctx, name[i+1:])
+			if errors.Is(err, ErrNotFound) {
+				end(nil)
+				return "", false, nil
+			}
+			end(err)
+			if err != nil {
+				return "", false, err
+			}
+			return val, true, nil
+		}
+	}
+	val, ok := l.lookupEnv(name)
+	if ok && l.recursiveExpansion {
+		var err error
+		if val, err = l.expandEnvValue(val, []string{name}); err != nil {
+			return "", false, err
+		}
+	}
+	return val, ok, nil
+}
+
+// maxConcurrentResolves bounds the resolver calls prefetchResolvers makes
+// at once.
+const maxConcurrentResolves = 8
+
+// resolvedValue is a prefetched resolver result.
+type resolvedValue struct {
+	val string
+	ok  bool
+}
+
+// prefetchResolvers calls the resolvers of the ${scheme:key} placeholders
+// in doc concurrently, ahead of expansion, and caches their results for
+// lookup, so a config referencing many secrets doesn't wait for them one
+// by one. Placeholders inside defaults are left to expansion, which only
+// resolves them if needed. Failures aren't cached: expansion calls the
+// resolver again and reports the error at the placeholder.
+func (l *loader) prefetchResolvers(doc *yaml.Node) {
+	var names []string
+	seen := make(map[string]bool)
+	collect := func(name string) (string, bool, error) {
+		if i := strings.IndexByte(name, ':'); i > 0 && l.resolvers[name[:i]] != nil && !seen[name] {
+			if _, ok := l.resolved[name]; !ok {
+				names = append(names, name)
+			}
+			seen[name] = true
+		}
+		// Report everything as set so defaults aren't scanned.
+		return "", true, nil
+	}
+	walkScalars(doc, func(n *yaml.Node, _ func() string) {
+		if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			expandString(n.Value, collect, expandMode{bare: l.bareVariables})
+		}
+	})
+	if len(names) < 2 {
+		// Nothing to gain over resolving during expansion.
+		return
+	}
+
+	type result struct {
+		resolvedValue
+		err error
+	}
+	results := make([]result, len(names))
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentResolves)
+	for i, name := range names {
+		scheme, key, _ := strings.Cut(name, ":")
+		g.Go(func() error {
+			ctx, end := l.span(l.ctx, "gonfig.resolve",
+				attribute.String("gonfig.resolver", scheme),
+				attribute.String("gonfig.key", key),
+			)
+			val, err := l.resolvers[scheme](ctx, key)
+			if errors.Is(err, ErrNotFound) {
+				end(nil)
+				return nil
+			}
+			end(err)
+			results[i] = result{resolvedValue{val: val, ok: err == nil}, err}
+			return nil
+		})
+	}
+	g.Wait()
+	for i, name := range names {
+		if results[i].err == nil {
+			l.resolved[name] = results[i].resolvedValue
+		}
+	}
+}
+
+// maxEnvExpansionDepth bounds how deeply WithRecursiveExpansion follows env
+// vars that refer to other env vars.
+const maxEnvExpansionDepth = 10
+
+// expandEnvValue expands the placeholders in val, the value of the last env
+// var in chain, recursively. Resolver placeholders in val are resolved but
+// their values are not expanded further.
+func (l *loader) expandEnvValue(val string, chain []string) (string, error) {
+	if len(chain) > maxEnvExpansionDepth {
+		return "", &EnvCycleError{Chain: chain, TooDeep: true}
+	}
+	out, missing, err := expandString(val, func(name string) (string, bool, error) {
+		for i, prev := range chain {
+			if prev == name {
+				return "", false, &EnvCycleError{Chain: append(chain[i:len(chain):len(chain)], name)}
+			}
+		}
+		if i := strings.IndexByte(name, ':'); i > 0 {
+			if _, ok := l.resolvers[name[:i]]; ok {
+				return l.lookup(name)
+			}
+		}
+		v, ok := l.lookupEnv(name)
+		if !ok {
+			return "", false, nil
+		}
+		v, err := l.expandEnvValue(v, append(chain[:len(chain):len(chain)], name))
+		return v, err == nil, err
+	}, l.expandMode())
+	if err != nil {
+		// Report the innermost failure, not one per level.
+		return "", err.(*lookupError).err
+	}
+	for _, m := range missing {
+		if m.isOptional(l.optionalVars) {
+			continue
+		}
+		if l.strict || m.required {
+			return "", &MissingEnvError{
+				File: "env " + chain[len(chain)-1],
+				Vars: []MissingVar{m.locate(&yaml.Node{Value: val, Line: 1, Column: 1}, "")},
+			}
+		}
+	}
+	return out, nil
+}
+
+// resolveFile implements the built-in ${file:/path} placeholder: the value is
+// the file's contents with surrounding whitespace trimmed. This is how Docker
+// and Kubernetes secrets are usually mounted. A missing file counts as unset.
+func resolveFile(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// resolveCredential implements the built-in ${cred:name} placeholder for
+// systemd credentials (LoadCredential= and friends): the value is the file
+// name in $CREDENTIALS_DIRECTORY, read like ${file:...}. Without
+// $CREDENTIALS_DIRECTORY, or without the credential, it counts as unset.
+func (l *loader) resolveCredential(ctx context.Context, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid credential name %q", name)
+	}
+	dir, ok := l.lookupEnv("CREDENTIALS_DIRECTORY")
+	if !ok || dir == "" {
+		return "", fmt.Errorf("%s: CREDENTIALS_DIRECTORY is not set: %w", name, ErrNotFound)
+	}
+	return resolveFile(ctx, filepath.Join(dir, name))
+}
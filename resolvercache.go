@@ -0,0 +1,104 @@
+// resolvercache.go
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ResolverCache wraps a ResolverFunc so each key hits the backend once per
+// TTL, however many placeholders refer to it and however often the config
+// is reloaded (Watch, NewLive). Keys that don't exist are cached too; other
+// errors aren't, so a failed lookup is retried on the next load.
+//
+//	vault := gonfig.NewResolverCache(vaultResolver, 5*time.Minute)
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithResolver("vault", vault.Resolve),
+//	)
+//
+// Concurrent lookups of the same key share one backend call. The call
+// doesn't stop when the lookup that started it is cancelled; each caller
+// only gives up waiting for it when its own ctx is done.
+type ResolverCache struct {
+	fn  ResolverFunc
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a cached resolver result. ready is closed once val and err
+// are set.
+type cacheEntry struct {
+	ready   chan struct{}
+	val     string
+	err     error
+	expires time.Time
+}
+
+// NewResolverCache returns a cache in front of fn. Results are kept for ttl;
+// a ttl of zero or less keeps them until Refresh.
+func NewResolverCache(fn ResolverFunc, ttl time.Duration) *ResolverCache {
+	return &ResolverCache{
+		fn:      fn,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Resolve is the cached ResolverFunc; pass it to WithResolver.
+func (c *ResolverCache) Resolve(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok {
+		select {
+		case <-e.ready:
+			if c.ttl > 0 && !c.now().Before(e.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+	if !ok {
+		e = &cacheEntry{ready: make(chan struct{})}
+		c.entries[key] = e
+		c.mu.Unlock()
+		go c.fill(context.WithoutCancel(ctx), key, e)
+	} else {
+		c.mu.Unlock()
+	}
+
+	select {
+	case <-e.ready:
+		return e.val, e.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// fill calls the resolver for key and publishes the result in e.
+func (c *ResolverCache) fill(ctx context.Context, key string, e *cacheEntry) {
+	e.val, e.err = c.fn(ctx, key)
+	e.expires = c.now().Add(c.ttl)
+	close(e.ready)
+	if e.err != nil && !errors.Is(e.err, ErrNotFound) {
+		c.mu.Lock()
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Refresh drops every cached value, so the next lookup of each key goes to
+// the backend. Call it after rotating secrets, before reloading the config.
+func (c *ResolverCache) Refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
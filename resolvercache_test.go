@@ -0,0 +1,146 @@
+// resolvercache_test.go
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResolverCache(t *testing.T) {
+	var calls atomic.Int32
+	fail := errors.New("backend down")
+	var failing atomic.Bool
+	cache := NewResolverCache(func(_ context.Context, key string) (string, error) {
+		calls.Add(1)
+		switch {
+		case failing.Load():
+			return "", fail
+		case key == "missing":
+			return "", ErrNotFound
+		}
+		return "value-of-" + key, nil
+	}, time.Minute)
+	now := time.Unix(1000, 0)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 3 {
+		v, err := cache.Resolve(ctx, "db")
+		if err != nil || v != "value-of-db" {
+			t.Fatalf("Resolve(db) = %q, %v", v, err)
+		}
+		if _, err := cache.Resolve(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Resolve(missing) error = %v, want ErrNotFound", err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("backend called %d times, want 2", n)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Resolve(ctx, "db")
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("backend called %d times after TTL, want 3", n)
+	}
+
+	cache.Refresh()
+	failing.Store(true)
+	for range 2 {
+		if _, err := cache.Resolve(ctx, "db"); !errors.Is(err, fail) {
+			t.Fatalf("Resolve(db) error = %v, want %v", err, fail)
+		}
+	}
+	if n := calls.Load(); n != 5 {
+		t.Fatalf("backend called %d times, want errors not to be cached (5)", n)
+	}
+}
+
+func TestResolverCache_SharesConcurrentLookups(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache := NewResolverCache(func(_ context.Context, key string) (string, error) {
+		calls.Add(1)
+		<-release
+		return "v", nil
+	}, 0)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if v, err := cache.Resolve(context.Background(), "k"); err != nil || v != "v" {
+				t.Errorf("Resolve = %q, %v", v, err)
+			}
+		})
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("backend called %d times, want 1", n)
+	}
+}
+
+func TestResolverCache_FirstCallerCancelled(t *testing.T) {
+	release := make(chan struct{})
+	cache := NewResolverCache(func(ctx context.Context, key string) (string, error) {
+		select {
+		case <-release:
+			return "v", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cache.Resolve(ctx, "k")
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan string, 1)
+	go func() {
+		v, err := cache.Resolve(context.Background(), "k")
+		if err != nil {
+			t.Errorf("second Resolve: %v", err)
+		}
+		second <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("first Resolve = %v, want context.Canceled", err)
+	}
+	close(release)
+	if v := <-second; v != "v" {
+		t.Fatalf("second Resolve = %q, want v", v)
+	}
+}
+
+func TestLoad_WithResolverCache(t *testing.T) {
+	var calls atomic.Int32
+	cache := NewResolverCache(func(_ context.Context, key string) (string, error) {
+		calls.Add(1)
+		return "secret-" + key, nil
+	}, time.Hour)
+	path := writeConfig(t, "app_name: ${vault:name}\nserver:\n  log_level: ${vault:name}\n")
+
+	for range 3 {
+		cfg, err := Load[testConfig](WithConfigFile(path), WithResolver("vault", cache.Resolve))
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.AppName != "secret-name" {
+			t.Fatalf("AppName = %q", cfg.AppName)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("backend called %d times across loads, want 1", n)
+	}
+}
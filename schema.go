@@ -0,0 +1,315 @@
+// schema.go
+package gonfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema returns a JSON Schema (draft 2020-12) describing the config files
+// T can be loaded from, for editor autocomplete and CI checks of config
+// files. Every property is titled with its Go field name. Fields tagged
+// `gonfig:"required"` (or validate:"required") are required, default tags
+// become defaults, enum tags, RegisterEnum types, validate:"oneof=..." and
+// min/max rules become enum and range constraints. Deprecated aliases are
+// listed as deprecated properties, and keys that don't map to a field are
+// rejected.
+//
+// Values that aren't strings also accept a string containing a ${...}
+// placeholder, so files using env vars still validate.
+//
+// Example:
+//
+//	schema, err := gonfig.Schema[Config]()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	os.WriteFile("config.schema.json", schema, 0o644)
+func Schema[T any]() ([]byte, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	s := (&schemaBuilder{seen: make(map[reflect.Type]bool)}).typ(t)
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	if t.Name() != "" {
+		s["title"] = t.Name()
+	}
+	s["$defs"] = map[string]any{
+		"placeholder": map[string]any{"type": "string", "pattern": `\$\{[^}]+\}`},
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+type schemaBuilder struct {
+	// seen guards against recursive types.
+	seen map[reflect.Type]bool
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	urlType      = reflect.TypeOf(url.URL{})
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+	secretType   = reflect.TypeOf(Secret(""))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+	flagsType    = reflect.TypeOf(Flags{})
+)
+
+// typ returns the schema of a value of type t.
+func (b *schemaBuilder) typ(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case durationType:
+		return scalar("string", map[string]any{"pattern": `^([0-9.]+(ns|us|µs|ms|s|m|h))+$`})
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case urlType:
+		return map[string]any{"type": "string", "format": "uri"}
+	case ipNetType, secretType:
+		return map[string]any{"type": "string"}
+	case byteSizeType:
+		return scalar("", map[string]any{"type": []string{"integer", "string"}})
+	case flagsType:
+		return map[string]any{"type": "object", "additionalProperties": map[string]any{"anyOf": []any{
+			scalar("boolean", nil),
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"enabled":      scalar("boolean", nil),
+					"environments": map[string]any{"type": "object", "additionalProperties": scalar("boolean", nil)},
+				},
+				"additionalProperties": false,
+			},
+		}}}
+	}
+	if _, ok := decoderFor(t); ok {
+		return map[string]any{"type": "string"}
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		return map[string]any{"type": "string"}
+	}
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) {
+		// Decoded by custom code: anything goes.
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return scalar("boolean", nil)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return scalar("integer", nil)
+	case reflect.Float32, reflect.Float64:
+		return scalar("number", nil)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+		if _, ok := listType(t); ok {
+			// Also written as "a,b,c".
+			return map[string]any{"type": []string{"array", "string"}, "items": b.typ(t.Elem())}
+		}
+		return map[string]any{"type": "array", "items": b.typ(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.typ(t.Elem())}
+	case reflect.Struct:
+		if b.seen[t] {
+			return map[string]any{"type": "object"}
+		}
+		b.seen[t] = true
+		defer delete(b.seen, t)
+		props := make(map[string]any)
+		var required []string
+		open := b.fields(t, props, &required)
+		s := map[string]any{"type": "object", "properties": props}
+		if !open {
+			s["additionalProperties"] = false
+		}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	}
+	return map[string]any{}
+}
+
+// fields adds the properties of struct t (including inlined structs) to
+// props and reports whether an inlined map accepts any other key.
+func (b *schemaBuilder) fields(t reflect.Type, props map[string]any, required *[]string) (open bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key, inline, skip := yamlFieldName(f)
+		if skip {
+			continue
+		}
+		if inline {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() {
+			case reflect.Struct:
+				open = b.fields(ft, props, required) || open
+			case reflect.Map:
+				open = true
+			}
+			continue
+		}
+
+		s := b.field(f)
+		props[key] = s
+		if isRequired(f) || hasValidateRule(f, "required") {
+			*required = append(*required, key)
+		}
+		for _, alias := range tagOptions(f, "alias") {
+			old := make(map[string]any, len(s)+1)
+			for k, v := range s {
+				old[k] = v
+			}
+			old["deprecated"] = true
+			props[alias] = old
+		}
+	}
+	return open
+}
+
+// field returns the schema of a single struct field, including what its
+// tags say.
+func (b *schemaBuilder) field(f reflect.StructField) map[string]any {
+	var s map[string]any
+	_, list := listType(f.Type)
+	if _, ok := fieldDecoder(f); ok && !list {
+		s = scalar("", map[string]any{"type": []string{"integer", "string"}})
+	} else {
+		s = b.typ(f.Type)
+	}
+	s["title"] = f.Name
+	if def, ok := f.Tag.Lookup("default"); ok {
+		var v any = def
+		if f.Type.Kind() != reflect.String {
+			var parsed any
+			if err := yaml.Unmarshal([]byte(def), &parsed); err == nil {
+				v = parsed
+			}
+		}
+		s["default"] = v
+	}
+	if len(tagOptions(f, "deprecated")) > 0 {
+		s["deprecated"] = true
+	}
+
+	// Constraints apply to the value itself, not to the placeholder branch.
+	target := s
+	if branches, ok := s["anyOf"].([]any); ok {
+		target = branches[0].(map[string]any)
+	}
+	if allowed, ok := fieldEnum(f); ok {
+		items := target
+		if it, ok := target["items"].(map[string]any); ok {
+			items = it
+		}
+		if branches, ok := items["anyOf"].([]any); ok {
+			items = branches[0].(map[string]any)
+		}
+		var enum []any
+		for _, v := range allowed {
+			enum = append(enum, enumValue(items["type"], v))
+		}
+		items["enum"] = enum
+	}
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "oneof":
+			var enum []any
+			for _, v := range strings.Fields(arg) {
+				enum = append(enum, enumValue(target["type"], strings.Trim(v, "'")))
+			}
+			target["enum"] = enum
+		case "min", "max", "gte", "lte":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			target[rangeKeyword(target["type"], name)] = n
+		}
+	}
+	return s
+}
+
+// scalar returns the schema of a non-string scalar of JSON type typ that
+// may also be written as a ${...} placeholder. extra is merged into the
+// value branch.
+func scalar(typ string, extra map[string]any) map[string]any {
+	value := map[string]any{}
+	if typ != "" {
+		value["type"] = typ
+	}
+	for k, v := range extra {
+		value[k] = v
+	}
+	return map[string]any{"anyOf": []any{value, map[string]any{"$ref": "#/$defs/placeholder"}}}
+}
+
+// enumValue converts a oneof value to the JSON type of the field.
+func enumValue(typ any, v string) any {
+	switch typ {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// rangeKeyword maps a validate min/max rule to the JSON Schema keyword for
+// the field's type: a value range for numbers, a length for strings and a
+// size for arrays and objects.
+func rangeKeyword(typ any, rule string) string {
+	upper := rule == "max" || rule == "lte"
+	switch typ {
+	case "string":
+		if upper {
+			return "maxLength"
+		}
+		return "minLength"
+	case "array":
+		if upper {
+			return "maxItems"
+		}
+		return "minItems"
+	case "object":
+		if upper {
+			return "maxProperties"
+		}
+		return "minProperties"
+	}
+	if upper {
+		return "maximum"
+	}
+	return "minimum"
+}
+
+// hasValidateRule reports whether rule is one of the rules in f's validate
+// tag.
+func hasValidateRule(f reflect.StructField, rule string) bool {
+	for _, r := range strings.Split(f.Tag.Get("validate"), ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchema(t *testing.T) {
+	type server struct {
+		Port    int           `yaml:"port" default:"8080" validate:"min=1,max=65535" gonfig:"alias=listen_port"`
+		Env     string        `yaml:"env" validate:"required,oneof=dev prod"`
+		Timeout time.Duration `yaml:"timeout"`
+		Level   string        `yaml:"level" enum:"debug,info"`
+	}
+	type schemaConfig struct {
+		Name    string            `yaml:"name" gonfig:"required"`
+		Server  server            `yaml:"server"`
+		Hosts   []string          `yaml:"hosts"`
+		Labels  map[string]string `yaml:"labels"`
+		Token   Secret            `yaml:"token"`
+		Cache   int64             `yaml:"cache" gonfig:"bytes"`
+		Ignored string            `yaml:"-"`
+	}
+
+	raw, err := Schema[schemaConfig]()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	var s map[string]any
+	if err := json.Unmarshal(raw, &s); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, raw)
+	}
+
+	get := func(path ...string) any {
+		var v any = s
+		for _, p := range path {
+			m, ok := v.(map[string]any)
+			if !ok {
+				t.Fatalf("no %v in schema:\n%s", path, raw)
+			}
+			v = m[p]
+		}
+		return v
+	}
+
+	checks := []struct {
+		path []string
+		want any
+	}{
+		{[]string{"title"}, "schemaConfig"},
+		{[]string{"additionalProperties"}, false},
+		{[]string{"required"}, []any{"name"}},
+		{[]string{"properties", "name", "type"}, "string"},
+		{[]string{"properties", "hosts", "items", "type"}, "string"},
+		{[]string{"properties", "labels", "additionalProperties", "type"}, "string"},
+		{[]string{"properties", "token", "type"}, "string"},
+		{[]string{"properties", "server", "required"}, []any{"env"}},
+		{[]string{"properties", "server", "properties", "env", "enum"}, []any{"dev", "prod"}},
+		{[]string{"properties", "server", "properties", "level", "enum"}, []any{"debug", "info"}},
+		{[]string{"properties", "server", "properties", "port", "title"}, "Port"},
+		{[]string{"properties", "server", "properties", "port", "default"}, float64(8080)},
+		{[]string{"properties", "server", "properties", "listen_port", "deprecated"}, true},
+		{[]string{"properties", "ignored"}, nil},
+	}
+	for _, c := range checks {
+		if got := get(c.path...); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%v: got %#v, want %#v", c.path, got, c.want)
+		}
+	}
+
+	port := get("properties", "server", "properties", "port", "anyOf").([]any)
+	if len(port) != 2 || !reflect.DeepEqual(port[0], map[string]any{"type": "integer", "minimum": float64(1), "maximum": float64(65535)}) {
+		t.Fatalf("unexpected port schema: %#v", port)
+	}
+	if !reflect.DeepEqual(port[1], map[string]any{"$ref": "#/$defs/placeholder"}) {
+		t.Fatalf("port must accept placeholders: %#v", port)
+	}
+}
+
+func TestLoad_WithSchema(t *testing.T) {
+	type config struct {
+		Name   string           `yaml:"name" gonfig:"required"`
+		Server testServerConfig `yaml:"server"`
+	}
+	schema, err := Schema[config]()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	t.Setenv("GONFIG_TEST_PORT", "8080")
+
+	path := writeConfig(t, "name: svc\nserver:\n  port: ${GONFIG_TEST_PORT}\n  timeout: 5s\n")
+	if _, err := Load[config](WithConfigFile(path), WithSchema(schema)); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	path = writeConfig(t, "server:\n  port: [1]\n  timeout: soon\n  extra: x\n")
+	_, err = Load[config](WithConfigFile(path), WithSchema(schema))
+	var se *SchemaError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected a SchemaError, got %v", err)
+	}
+	got := make(map[string]bool)
+	for _, v := range se.Violations {
+		got[v.Pointer] = true
+	}
+	for _, ptr := range []string{"", "/server", "/server/port", "/server/timeout"} {
+		if !got[ptr] {
+			t.Fatalf("no violation at %q in %v", ptr, se)
+		}
+	}
+	if !strings.Contains(err.Error(), "/server/port (2:9)") {
+		t.Fatalf("expected a position in %q", err)
+	}
+
+	schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := os.WriteFile(schemaFile, []byte("type: object\nrequired: [name]\n"), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	if _, err := Load[config](WithBytes([]byte("server: {}\n")), WithSchemaFile(schemaFile)); !errors.As(err, &se) {
+		t.Fatalf("expected a SchemaError from a YAML schema file, got %v", err)
+	}
+}
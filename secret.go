@@ -0,0 +1,35 @@
+// secret.go
+package gonfig
+
+import "encoding/json"
+
+// Secret is a string config value that hides itself when printed or
+// marshalled, so logging a config doesn't leak credentials:
+//
+//	type DatabaseConfig struct {
+//	    Password gonfig.Secret `yaml:"password"`
+//	}
+//
+//	log.Printf("%+v", cfg.Database)            // {Password:***}
+//	db.Connect(cfg.Database.Password.Value()) // the real password
+//
+// It decodes like a plain string, including from placeholders and env
+// overrides.
+type Secret string
+
+const redacted = "***"
+
+// Value returns the secret itself.
+func (s Secret) Value() string { return string(s) }
+
+// String implements fmt.Stringer and always returns "***".
+func (s Secret) String() string { return redacted }
+
+// GoString implements fmt.GoStringer, so %#v is redacted too.
+func (s Secret) GoString() string { return `gonfig.Secret("` + redacted + `")` }
+
+// MarshalJSON implements json.Marshaler and always emits "***".
+func (s Secret) MarshalJSON() ([]byte, error) { return json.Marshal(redacted) }
+
+// MarshalYAML implements yaml.Marshaler and always emits "***".
+func (s Secret) MarshalYAML() (any, error) { return redacted, nil }
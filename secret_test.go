@@ -0,0 +1,46 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSecret(t *testing.T) {
+	type dbConfig struct {
+		User     string `yaml:"user" json:"user"`
+		Password Secret `yaml:"password" json:"password"`
+	}
+	t.Setenv("GONFIG_TEST_DB_PASSWORD", "hunter2")
+
+	cfg, err := Load[dbConfig](WithBytes([]byte("user: app\npassword: ${GONFIG_TEST_DB_PASSWORD}\n")))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Password.Value() != "hunter2" {
+		t.Fatalf("expected the real value from Value(), got %q", cfg.Password.Value())
+	}
+
+	jsonOut, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	yamlOut, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	for _, out := range []string{
+		fmt.Sprint(cfg.Password),
+		fmt.Sprintf("%+v", cfg),
+		fmt.Sprintf("%#v", cfg),
+		string(jsonOut),
+		string(yamlOut),
+	} {
+		if strings.Contains(out, "hunter2") || !strings.Contains(out, "***") {
+			t.Fatalf("secret leaked or not redacted: %s", out)
+		}
+	}
+}
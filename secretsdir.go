@@ -0,0 +1,57 @@
+// secretsdir.go
+package gonfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithSecretsDir makes every file in dir available to ${VAR} placeholders
+// under its file name, the way Docker Swarm and Kubernetes mount secrets:
+// with /run/secrets/db_password, `password: ${db_password}` reads the file.
+//
+// Env vars (including dotenv files) take precedence over secret files, so
+// a secret can still be overridden locally. Trailing newlines are trimmed,
+// hidden files and subdirectories are skipped, and a missing dir is
+// ignored. Later dirs win over earlier ones. Watch and NewLive reload when
+// a file in dir changes, if dir exists when they start.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithSecretsDir("/run/secrets"),
+//	)
+func WithSecretsDir(dir string) Option {
+	return func(l *loader) {
+		l.secretsDirs = append(l.secretsDirs, dir)
+	}
+}
+
+// readSecretsDir adds every file in dir to secrets, keyed by file name.
+// Symlinks are followed, as Kubernetes mounts secrets through them.
+func readSecretsDir(dir string, secrets map[string]string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		secrets[e.Name()] = strings.TrimRight(string(data), "\r\n")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package gonfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_WithSecretsDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"db_password": "s3cret\n",
+		"api_token":   "from-file",
+		".hidden":     "skip",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("write secret: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "db_password"), filepath.Join(dir, "linked")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	type config struct {
+		Password string `yaml:"password"`
+		Token    string `yaml:"token"`
+		Linked   string `yaml:"linked"`
+		Hidden   string `yaml:"hidden"`
+	}
+	env := map[string]string{"api_token": "from-env"}
+	cfg, err := Load[config](
+		WithBytes([]byte("password: ${db_password}\ntoken: ${api_token}\nlinked: ${linked}\nhidden: ${.hidden:-none}\n")),
+		WithSecretsDir(dir),
+		WithSecretsDir(filepath.Join(dir, "missing")),
+		WithEnvLookup(func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		}),
+		WithStrict(),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := config{Password: "s3cret", Token: "from-env", Linked: "s3cret", Hidden: "none"}
+	if cfg != want {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+}
@@ -0,0 +1,117 @@
+// sections.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sections maps top-level config keys to pointers that the sections are
+// decoded into, so each package of a larger program can own the type of its
+// own section of a shared config file:
+//
+//	sections := gonfig.Sections{}
+//	sections["server"] = &server.Config   // owned by package server
+//	sections["db"] = &db.Config           // owned by package db
+type Sections map[string]any
+
+// LoadAll loads one config file (with all the usual layers, overrides and
+// checks) and decodes each top-level section listed in sections into its
+// own target. Paths in errors and reports are the same as for a single
+// struct, e.g. "db.password". A section type's Validate() and AfterLoad()
+// methods, if any, run after the whole config has loaded; their errors name
+// the section.
+//
+// Targets are only written when the whole load succeeds. Top-level keys no
+// section is registered for are reported as unknown keys.
+//
+// Example:
+//
+//	var (
+//	    serverCfg server.Config
+//	    dbCfg     db.Config
+//	)
+//	err := gonfig.LoadAll(gonfig.Sections{
+//	    "server": &serverCfg,
+//	    "db":     &dbCfg,
+//	}, gonfig.WithConfigFile("config.yaml"))
+func LoadAll(sections Sections, opts ...Option) error {
+	keys := make([]string, 0, len(sections))
+	for key, dst := range sections {
+		if v := reflect.ValueOf(dst); v.Kind() != reflect.Pointer || v.IsNil() {
+			return fmt.Errorf("load all: section %s must be a non-nil pointer, got %T", key, dst)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Decode into a struct with one field per section so the whole
+	// pipeline sees the sections as a single config.
+	fields := make([]reflect.StructField, len(keys))
+	for i, key := range keys {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Section%d", i),
+			Type: reflect.TypeOf(sections[key]).Elem(),
+			Tag:  reflect.StructTag(fmt.Sprintf(`yaml:%q`, key)),
+		}
+	}
+	cfg := reflect.New(reflect.StructOf(fields)).Elem()
+	report, err := newLoader(opts).loadValue(cfg)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		if a, ok := cfg.Field(i).Addr().Interface().(afterLoader); ok {
+			if err := a.AfterLoad(report); err != nil {
+				return &ValidationError{Err: &FieldError{Path: key, Err: err}}
+			}
+		}
+	}
+	for i, key := range keys {
+		reflect.ValueOf(sections[key]).Elem().Set(cfg.Field(i))
+	}
+	return nil
+}
+
+// sectionNode returns the node at the YAML path in doc (see WithSection),
+// or an empty node if there is none.
+func sectionNode(doc *yaml.Node, path string) *yaml.Node {
+	if n := nodeAt(doc, splitPath(path)); n != nil {
+		return n
+	}
+	return &yaml.Node{}
+}
+
+// nodeAt returns the node below doc at the path segments segs (mapping
+// keys and list indexes), or nil if there is none.
+func nodeAt(doc *yaml.Node, segs []string) *yaml.Node {
+	n := doc
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, seg := range segs {
+		n = resolveAlias(n)
+		switch n.Kind {
+		case yaml.MappingNode:
+			i := mappingIndex(n, seg)
+			if i < 0 {
+				return nil
+			}
+			n = n.Content[i+1]
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[i]
+		default:
+			return nil
+		}
+	}
+	return resolveAlias(n)
+}
@@ -0,0 +1,93 @@
+package gonfig
+
+import (
+	"errors"
+	"testing"
+)
+
+type sectionDB struct {
+	Host     string `yaml:"host" default:"localhost"`
+	Password string `yaml:"password" gonfig:"required"`
+}
+
+func (c sectionDB) Validate() error {
+	if c.Password == "changeme" {
+		return errors.New("password must be changed")
+	}
+	return nil
+}
+
+func TestLoadAll(t *testing.T) {
+	t.Setenv("APP_DB_PASSWORD", "s3cret")
+	path := writeConfig(t, "server:\n  port: 8080\ndb:\n  password: changeme\n")
+
+	var (
+		server testServerConfig
+		db     sectionDB
+	)
+	sections := Sections{"server": &server, "db": &db}
+	if err := LoadAll(sections, WithConfigFile(path), WithEnvOverrides("APP")); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	if server.Port != 8080 || db.Host != "localhost" || db.Password != "s3cret" {
+		t.Fatalf("unexpected sections: %+v %+v", server, db)
+	}
+
+	db = sectionDB{}
+	err := LoadAll(sections, WithConfigFile(path))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "db" {
+		t.Fatalf("expected a FieldError for db, got %v", err)
+	}
+	if db.Password != "" {
+		t.Fatalf("section written on error: %+v", db)
+	}
+
+	if err := LoadAll(Sections{"db": db}, WithConfigFile(path)); err == nil {
+		t.Fatalf("expected an error for a non-pointer section")
+	}
+}
+
+func TestLoad_WithSection(t *testing.T) {
+	t.Setenv("APP_DATABASE_HOST", "db.internal")
+	path := writeConfig(t, "server:\n  port: 8080\ndatabase:\n  user: app\n  pasword: x\n")
+
+	type dbConfig struct {
+		Host     string `yaml:"host"`
+		User     string `yaml:"user"`
+		Password string `yaml:"password" gonfig:"required"`
+	}
+	cfg, report, err := LoadWithReport[dbConfig](
+		WithConfigFile(path),
+		WithSection("database"),
+		WithEnvOverrides("APP"),
+		WithOverride("database.password", "s3cret"),
+	)
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg != (dbConfig{Host: "db.internal", User: "app", Password: "s3cret"}) {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if got := report.Origins["database.user"].String(); got != path+":4:9" {
+		t.Fatalf("origin of database.user: got %q", got)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Path != "database.pasword" {
+		t.Fatalf("unexpected warnings: %v", report.Warnings)
+	}
+
+	_, err = Load[dbConfig](WithConfigFile(path), WithSection("database"))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "database.password" {
+		t.Fatalf("expected a FieldError for database.password, got %v", err)
+	}
+
+	if _, err := Load[dbConfig](WithConfigFile(path), WithSection("database"), WithOverride("server.port", 1)); err == nil {
+		t.Fatalf("expected an error for an override outside the section")
+	}
+
+	cfg, err = Load[dbConfig](WithConfigFile(path), WithSection("cache"), WithOverride("cache.password", "x"))
+	if err != nil || cfg != (dbConfig{Password: "x"}) {
+		t.Fatalf("missing section: cfg=%+v err=%v", cfg, err)
+	}
+}
@@ -0,0 +1,174 @@
+// source.go
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// Source is where the raw config document comes from. The built-in options
+// (WithConfigFile, WithConfigDir, WithFS, WithReader, WithBytes,
+// WithConfigURL) are all
+// sources; implement Source to plug in anything else, such as an internal
+// config API or a database, and pass it to WithSource.
+type Source interface {
+	// Fetch returns the raw YAML document.
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// Watcher is implemented by sources that can report changes, e.g. through
+// file notifications, long polling or a server-side watch. Watch and NewLive
+// require the config source to implement it.
+type Watcher interface {
+	// Watch starts watching in the background and returns once the watch
+	// is in place, so no change after the initial Fetch is missed. Until
+	// ctx is done, it calls changed whenever the document may have
+	// changed; spurious calls only cause an extra Fetch. Errors setting
+	// up the watch are returned; later, transient errors should be
+	// retried in the background.
+	Watch(ctx context.Context, changed func()) error
+}
+
+// WithSource reads the config from src instead of a file.
+//
+// If src implements fmt.Stringer, its String() is used to name the source
+// in errors (e.g. "s3://bucket/config.yaml"). If src implements Watcher,
+// the config can be used with Watch and NewLive.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithSource(myConfigAPI{service: "billing"}),
+//	)
+func WithSource(src Source) Option {
+	return func(l *loader) {
+		l.source = src
+		l.configFile = sourceName(src)
+	}
+}
+
+// fetch fetches src, bounding each attempt by WithSourceTimeout and
+// retrying failures as set by WithSourceRetries. Sources that can stop
+// reading at the WithMaxConfigSize limit are asked to.
+func (l *loader) fetch(ctx context.Context, src Source) ([]byte, error) {
+	var err error
+	for attempt := 0; attempt <= l.sourceRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<(attempt-1)) * 200 * time.Millisecond
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-time.After(backoff):
+			}
+		}
+		var raw []byte
+		raw, err = l.fetchOnce(ctx, src)
+		// Missing and oversized documents won't get better by retrying.
+		if err == nil || errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrLimitExceeded) || ctx.Err() != nil {
+			return raw, err
+		}
+	}
+	return nil, err
+}
+
+// fetchOnce makes a single fetch attempt.
+func (l *loader) fetchOnce(parent context.Context, src Source) ([]byte, error) {
+	ctx := parent
+	if l.sourceTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.sourceTimeout)
+		defer cancel()
+	}
+	var raw []byte
+	var err error
+	if f, ok := src.(limitedFetcher); ok && l.limits.MaxBytes > 0 {
+		raw, err = f.fetchLimited(ctx, int64(l.limits.MaxBytes))
+	} else {
+		raw, err = src.Fetch(ctx)
+	}
+	if err != nil && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s: %w", l.sourceTimeout, err)
+	}
+	return raw, err
+}
+
+// sourceName names src in errors.
+func sourceName(src Source) string {
+	if s, ok := src.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return "<source>"
+}
+
+// fileSource reads a file on disk and watches it with fsnotify.
+type fileSource string
+
+func (f fileSource) Fetch(context.Context) ([]byte, error) { return os.ReadFile(string(f)) }
+
+func (f fileSource) fetchLimited(_ context.Context, max int64) ([]byte, error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Size() > max {
+		return nil, fmt.Errorf("%w: document is %d bytes, over the limit of %d", ErrLimitExceeded, info.Size(), max)
+	}
+	return readLimited(file, max)
+}
+
+func (f fileSource) Watch(ctx context.Context, changed func()) error {
+	return watchFiles(ctx, []string{string(f)}, changed)
+}
+
+func (f fileSource) String() string { return string(f) }
+
+// fsSource reads a file from an fs.FS, e.g. an embed.FS.
+type fsSource struct {
+	fsys fs.FS
+	path string
+}
+
+func (s fsSource) Fetch(context.Context) ([]byte, error) { return fs.ReadFile(s.fsys, s.path) }
+
+func (s fsSource) String() string { return s.path }
+
+// readerSource drains an io.Reader on the first fetch and keeps its bytes,
+// so reloads see the same document rather than an empty reader.
+type readerSource struct {
+	r    io.Reader
+	mu   sync.Mutex
+	read bool
+	data []byte
+	err  error
+}
+
+func (s *readerSource) Fetch(ctx context.Context) ([]byte, error) { return s.fetchLimited(ctx, 0) }
+
+func (s *readerSource) fetchLimited(_ context.Context, max int64) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.read {
+		s.data, s.err = readLimited(s.r, max)
+		s.read = true
+	}
+	if s.err == nil && max > 0 && int64(len(s.data)) > max {
+		return nil, tooLarge(max)
+	}
+	return s.data, s.err
+}
+
+func (*readerSource) String() string { return "<reader>" }
+
+// bytesSource is a config held in memory.
+type bytesSource []byte
+
+func (b bytesSource) Fetch(context.Context) ([]byte, error) { return b, nil }
+
+func (bytesSource) String() string { return "<bytes>" }
@@ -0,0 +1,220 @@
+// squash.go
+package gonfig
+
+import (
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isSquashed reports whether f is inlined by a `gonfig:"squash"` tag alone,
+// so yaml.v3 doesn't inline it itself.
+func isSquashed(f reflect.StructField) bool {
+	if !hasTagOption(f, "squash") {
+		return false
+	}
+	for _, p := range strings.Split(f.Tag.Get("yaml"), ",")[1:] {
+		if p == "inline" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSquash reports whether values of t can contain a squashed field.
+func hasSquash(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.IsExported() && (isSquashed(f) || hasSquash(f.Type, seen)) {
+				return true
+			}
+		}
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return hasSquash(t.Elem(), seen)
+	}
+	return false
+}
+
+// decodeSquashed walks n alongside the decoded value v and decodes the
+// mapping of every struct with squashed fields into those fields too.
+func decodeSquashed(n *yaml.Node, v reflect.Value) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return decodeSquashed(n.Content[0], v)
+	case yaml.AliasNode:
+		return decodeSquashed(n.Alias, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return decodeSquashed(n, v.Elem())
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode || isLeafType(v.Type()) {
+			return nil
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && isSquashed(f) {
+				if err := n.Decode(v.Field(i).Addr().Interface()); err != nil {
+					return err
+				}
+				if err := decodeSquashed(n, v.Field(i)); err != nil {
+					return err
+				}
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, val := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				if err := decodeSquashed(val, v); err != nil {
+					return err
+				}
+				continue
+			}
+			if f, _, ok := fieldByKey(v, k.Value); ok {
+				if err := decodeSquashed(val, f); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode || v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := reflect.ValueOf(n.Content[i].Value).Convert(v.Type().Key())
+			cur := v.MapIndex(key)
+			if !cur.IsValid() {
+				continue
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(cur)
+			if err := decodeSquashed(n.Content[i+1], elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, item := range n.Content {
+			if i >= v.Len() {
+				break
+			}
+			if err := decodeSquashed(item, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flattenSquashed moves the keys of squashed fields in n, the YAML encoding
+// of v, up into their parent mapping, the way they are decoded. yaml.v3
+// encodes them as a nested mapping under the field's own key.
+func flattenSquashed(n *yaml.Node, v reflect.Value) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			flattenSquashed(n.Content[0], v)
+		}
+		return
+	case yaml.AliasNode:
+		return
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode || isLeafType(v.Type()) {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if !isSquashed(f) {
+				if key, inline, skip := yamlFieldName(f); !skip {
+					if inline {
+						flattenSquashed(n, v.Field(i))
+					} else if c := mappingValue(n, key); c != nil {
+						flattenSquashed(c, v.Field(i))
+					}
+				}
+				continue
+			}
+			key := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			if key == "" {
+				key = strings.ToLower(f.Name)
+			}
+			for j := 0; j+1 < len(n.Content); j += 2 {
+				if n.Content[j].Value != key {
+					continue
+				}
+				inner := n.Content[j+1]
+				flattenSquashed(inner, v.Field(i))
+				content := append(n.Content[:j:j], n.Content[j+2:]...)
+				if inner.Kind == yaml.MappingNode {
+					content = append(content, inner.Content...)
+				}
+				n.Content = content
+				break
+			}
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for j := 0; j+1 < len(n.Content); j += 2 {
+			key := reflect.ValueOf(n.Content[j].Value)
+			if key.Type().ConvertibleTo(v.Type().Key()) {
+				if e := v.MapIndex(key.Convert(v.Type().Key())); e.IsValid() {
+					flattenSquashed(n.Content[j+1], e)
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range n.Content {
+			if i < v.Len() {
+				flattenSquashed(item, v.Index(i))
+			}
+		}
+	}
+}
+
+// mappingValue returns the value of key in mapping node n, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
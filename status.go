@@ -0,0 +1,96 @@
+// status.go
+package gonfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Status is the state of a config kept up to date by NewLive, for health
+// checks and readiness probes.
+type Status struct {
+	// Source names the config source, e.g. the config file or URL.
+	Source string
+	// LoadedAt is when the config last loaded successfully, initially or
+	// on a reload.
+	LoadedAt time.Time
+	// CheckedAt is when the config was last loaded or reloaded, whether
+	// that succeeded or not.
+	CheckedAt time.Time
+	// Err is why the last reload failed, or nil if it succeeded. The
+	// config is then still the one loaded at LoadedAt.
+	Err error
+}
+
+// Stale reports whether the config hasn't loaded successfully for longer
+// than maxAge. Reloads only happen when a watched file changes or on the
+// WithRefreshInterval timer, so use it with a refresh interval and a maxAge
+// of a few intervals.
+func (s Status) Stale(maxAge time.Duration) bool {
+	return time.Since(s.LoadedAt) > maxAge
+}
+
+// Status returns the state of the config: when it last loaded, and why the
+// last reload failed, if it did.
+func (lv *Live[T]) Status() Status {
+	return lv.watched.getStatus()
+}
+
+// HealthHandler returns an http.Handler for readiness probes. It responds
+// 200 while the last reload succeeded and, if maxAge is positive, the
+// config isn't Stale(maxAge); otherwise 503. The body is the Status as
+// JSON:
+//
+//	{"status":"error","source":"https://config.internal/api.yaml",
+//	 "loaded_at":"2026-10-15T09:00:00Z","checked_at":"2026-10-15T09:05:00Z",
+//	 "error":"fetch config: 502 Bad Gateway"}
+//
+// Example:
+//
+//	live, err := gonfig.NewLive[Config](ctx,
+//	    gonfig.WithConfigURL("https://config.internal/api.yaml"),
+//	    gonfig.WithRefreshInterval(time.Minute),
+//	)
+//	http.Handle("/readyz/config", live.HealthHandler(5*time.Minute))
+func (lv *Live[T]) HealthHandler(maxAge time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := lv.Status()
+		body := struct {
+			Status    string    `json:"status"`
+			Source    string    `json:"source"`
+			LoadedAt  time.Time `json:"loaded_at"`
+			CheckedAt time.Time `json:"checked_at"`
+			Error     string    `json:"error,omitempty"`
+		}{Status: "ok", Source: s.Source, LoadedAt: s.LoadedAt, CheckedAt: s.CheckedAt}
+		code := http.StatusOK
+		switch {
+		case s.Err != nil:
+			body.Status, body.Error, code = "error", s.Err.Error(), http.StatusServiceUnavailable
+		case maxAge > 0 && s.Stale(maxAge):
+			body.Status, code = "stale", http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(body)
+	})
+}
+
+// setStatus records the result of a load.
+func (w *watched[T]) setStatus(err error) {
+	now := time.Now()
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+	w.status.Source = w.l.configFile
+	w.status.CheckedAt = now
+	w.status.Err = err
+	if err == nil {
+		w.status.LoadedAt = now
+	}
+}
+
+func (w *watched[T]) getStatus() Status {
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+	return w.status
+}
@@ -0,0 +1,54 @@
+// status_test.go
+package gonfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLive_StatusAndHealthHandler(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+	live, err := NewLive[testConfig](t.Context(), WithConfigFile(path), WithReloadDebounce(0))
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+	s := live.Status()
+	if s.Err != nil || s.Source != path || s.LoadedAt.IsZero() || !s.LoadedAt.Equal(s.CheckedAt) {
+		t.Fatalf("initial status = %+v", s)
+	}
+
+	probe := func(maxAge time.Duration) (int, string) {
+		rec := httptest.NewRecorder()
+		live.HealthHandler(maxAge).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body struct{ Status string }
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("unmarshal %q: %v", rec.Body.String(), err)
+		}
+		return rec.Code, body.Status
+	}
+	if code, status := probe(time.Hour); code != http.StatusOK || status != "ok" {
+		t.Fatalf("healthy probe = %d %s", code, status)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if code, status := probe(time.Millisecond); code != http.StatusServiceUnavailable || status != "stale" {
+		t.Fatalf("stale probe = %d %s", code, status)
+	}
+
+	replaceFile(t, path, "server: [\n")
+	waitFor(t, func() bool { return live.Status().Err != nil })
+	if s := live.Status(); !s.CheckedAt.After(s.LoadedAt) {
+		t.Fatalf("failed reload status = %+v", s)
+	}
+	if code, status := probe(0); code != http.StatusServiceUnavailable || status != "error" {
+		t.Fatalf("broken probe = %d %s", code, status)
+	}
+
+	replaceFile(t, path, "server:\n  port: 9090\n")
+	waitFor(t, func() bool { return live.Status().Err == nil })
+	if code, _ := probe(time.Hour); code != http.StatusOK {
+		t.Fatalf("probe after recovery = %d", code)
+	}
+}
@@ -0,0 +1,107 @@
+// template.go
+package gonfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// WithTemplating renders every config layer as a Go text/template before
+// parsing it, with the sprig functions and Helm's required, toYaml and
+// fromYaml, so Helm-style configs work unchanged:
+//
+//	# config.yaml
+//	replicas: {{ env "REPLICAS" | default "2" }}
+//	database:
+//	  password: {{ required "DB_PASSWORD is required" (env "DB_PASSWORD") }}
+//	tls:
+//	  cert: {{ env "TLS_CERT_B64" | b64dec | toJson }}
+//	{{- if eq (env "APP_ENV") "prod" }}
+//	  verify: true
+//	{{- end }}
+//
+// env and expandenv read the same variables as ${VAR} placeholders
+// (including dotenv files and WithEnvLookup). The template has no data;
+// ${VAR} placeholders are expanded afterwards as usual. Line numbers in
+// later errors refer to the rendered document, and Limits.MaxBytes applies
+// to it as well as to the template. sprig's getHostByName is left out, so
+// loading a config never does DNS lookups.
+func WithTemplating() Option {
+	return func(l *loader) {
+		l.templating = true
+	}
+}
+
+// render executes raw, the config layer name, as a template. Rendering
+// stops once the output is over Limits.MaxBytes.
+func (l *loader) render(name string, raw []byte) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(l.templateFuncs()).Parse(string(raw))
+	if err != nil {
+		return nil, err
+	}
+	out := &renderBuffer{max: l.limits.MaxBytes}
+	if err := tmpl.Execute(out, nil); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// renderBuffer is a bytes.Buffer that fails writes past max bytes, if max
+// is set.
+type renderBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *renderBuffer) Write(p []byte) (int, error) {
+	if b.max > 0 && b.Len()+len(p) > b.max {
+		return 0, fmt.Errorf("%w: rendered document is over the limit of %d bytes", ErrLimitExceeded, b.max)
+	}
+	return b.Buffer.Write(p)
+}
+
+// templateFuncs returns the sprig functions, with env and expandenv going
+// through lookupEnv and without getHostByName, and the Helm functions
+// sprig lacks.
+func (l *loader) templateFuncs() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "getHostByName")
+	funcs["env"] = func(name string) string {
+		v, _ := l.lookupEnv(name)
+		return v
+	}
+	funcs["expandenv"] = func(s string) string {
+		return os.Expand(s, func(name string) string {
+			v, _ := l.lookupEnv(name)
+			return v
+		})
+	}
+	funcs["required"] = func(msg string, v any) (any, error) {
+		if s, ok := v.(string); v == nil || ok && s == "" {
+			return nil, errors.New(msg)
+		}
+		return v, nil
+	}
+	funcs["toYaml"] = func(v any) string {
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSuffix(string(out), "\n")
+	}
+	funcs["fromYaml"] = func(s string) map[string]any {
+		m := map[string]any{}
+		if err := yaml.Unmarshal([]byte(s), &m); err != nil {
+			m["Error"] = err.Error()
+		}
+		return m
+	}
+	return funcs
+}
@@ -0,0 +1,77 @@
+// template_test.go
+package gonfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoad_WithTemplating(t *testing.T) {
+	type config struct {
+		AppName  string            `yaml:"app_name"`
+		Replicas int               `yaml:"replicas"`
+		Cert     string            `yaml:"cert"`
+		Labels   map[string]string `yaml:"labels"`
+		Password string            `yaml:"password"`
+		Verify   bool              `yaml:"verify"`
+	}
+	env := map[string]string{
+		"APP_ENV":  "prod",
+		"CERT_B64": "LS0tLS1CRUdJTgpsaW5lMgo=",
+		"PASSWORD": "s3cret",
+	}
+	lookup := WithEnvLookup(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+	path := writeConfig(t, `app_name: {{ "api" | upper }}
+replicas: {{ env "REPLICAS" | default "2" }}
+cert: {{ env "CERT_B64" | b64dec | toJson }}
+labels:
+  {{- dict "team" "core" "env" (env "APP_ENV") | toYaml | nindent 2 }}
+password: {{ required "PASSWORD is required" (env "PASSWORD") }}
+{{- if eq (env "APP_ENV") "prod" }}
+verify: true
+{{- end }}
+`)
+
+	cfg, err := Load[config](WithConfigFile(path), WithTemplating(), lookup)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "API" || cfg.Replicas != 2 || cfg.Cert != "-----BEGIN\nline2\n" || !cfg.Verify || cfg.Password != "s3cret" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Labels["team"] != "core" || cfg.Labels["env"] != "prod" {
+		t.Fatalf("unexpected labels: %v", cfg.Labels)
+	}
+
+	delete(env, "PASSWORD")
+	_, err = Load[config](WithConfigFile(path), WithTemplating(), lookup)
+	var pe *ParseError
+	if !errors.As(err, &pe) || !strings.Contains(err.Error(), "PASSWORD is required") {
+		t.Fatalf("expected a ParseError from required, got %v", err)
+	}
+	if !strings.Contains(err.Error(), ":6:") {
+		t.Fatalf("expected the template line in %v", err)
+	}
+
+	// Without WithTemplating the braces are just YAML.
+	if _, err := Load[config](WithConfigFile(path), lookup); err == nil {
+		t.Fatal("expected the unrendered template not to parse")
+	}
+}
+
+func TestLoad_WithTemplatingLimits(t *testing.T) {
+	small := []byte(`app_name: {{ repeat 5000 "x" }}` + "\n")
+	_, err := Load[testConfig](WithBytes(small), WithTemplating(), WithMaxConfigSize(1024))
+	if !errors.Is(err, ErrLimitExceeded) || !strings.Contains(err.Error(), "rendered document is over the limit of 1024") {
+		t.Fatalf("expected the rendered size to be limited, got %v", err)
+	}
+
+	_, err = Load[testConfig](WithBytes([]byte(`app_name: {{ getHostByName "localhost" }}`)), WithTemplating())
+	if err == nil || !strings.Contains(err.Error(), `function "getHostByName" not defined`) {
+		t.Fatalf("expected getHostByName to be unavailable, got %v", err)
+	}
+}
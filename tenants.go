@@ -0,0 +1,144 @@
+// tenants.go
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// tenantBaseName is the file in a tenant dir every tenant is layered on.
+const tenantBaseName = "_base"
+
+// LoadDir loads one config per tenant from the YAML files in dir, keyed by
+// file name without its extension. Each tenant file is layered over
+// _base.yaml in the same dir, if there is one, so shared defaults live in
+// one place:
+//
+//	tenants/
+//	  _base.yaml   # shared by every tenant
+//	  acme.yaml    # tenants["acme"]
+//	  globex.yaml  # tenants["globex"]
+//
+//	tenants, err := gonfig.LoadDir[TenantConfig]("tenants/",
+//	    gonfig.WithEnvOverrides("APP"),
+//	)
+//
+// The other options apply to every tenant as in Load, except those that set
+// the config source, and WithSHA256 and WithSignature, which verify a single
+// document and make LoadDir fail. With WithProfile, acme.prod.yaml is layered over
+// acme.yaml (and _base.prod.yaml over _base.yaml); file names with another
+// dot are profile variants, and names starting with "_" are reserved, so
+// neither is a tenant. The errors of all tenants that fail are returned
+// together, each prefixed with the tenant name.
+//
+// Use NewLiveDir to keep the tenants up to date as files change.
+func LoadDir[T any](dir string, opts ...Option) (map[string]T, error) {
+	tenants, _, err := loadDir[T](newLoader(opts), dir)
+	return tenants, err
+}
+
+// NewLiveDir is NewLive for LoadDir: it loads the tenants in dir and
+// reloads all of them when a file in dir, or a dotenv file, changes.
+// Tenants are added and removed as their files are.
+//
+// Example:
+//
+//	live, err := gonfig.NewLiveDir[TenantConfig](ctx, "tenants/")
+//	// ...
+//	cfg, ok := live.Get()[tenantID]
+func NewLiveDir[T any](ctx context.Context, dir string, opts ...Option) (*Live[map[string]T], error) {
+	l := newLoader(opts)
+	l.source, l.configFile = dirSource(dir), dir
+	return newLive(ctx, l, func(l *loader) (map[string]T, Report, error) {
+		return loadDir[T](l, dir)
+	})
+}
+
+// loadDir loads every tenant in dir with l. The report holds the origins of
+// all tenants, prefixed with the tenant name, and their warnings.
+func loadDir[T any](l *loader, dir string) (map[string]T, Report, error) {
+	report := Report{Origins: make(map[string]Origin)}
+	if l.sha256 != "" || l.signature != nil {
+		return nil, report, fmt.Errorf("verify %s: WithSHA256 and WithSignature need a single config document, not a tenant dir", dir)
+	}
+	base, files, err := tenantFiles(dir)
+	if err != nil {
+		return nil, report, err
+	}
+
+	configFile := l.configFile
+	defer func() {
+		l.tenantBase, l.tenant, l.configFile = "", "", configFile
+	}()
+	tenants := make(map[string]T, len(files))
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		file := files[name]
+		l.tenantBase, l.tenant, l.configFile = base, file, file
+		cfg, r, err := load[T](l)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", name, err))
+			continue
+		}
+		tenants[name] = cfg
+		for path, o := range r.Origins {
+			report.Origins[joinPath(name, path)] = o
+		}
+		report.Warnings = append(report.Warnings, r.Warnings...)
+	}
+	if len(errs) > 0 {
+		return nil, report, errors.Join(errs...)
+	}
+	return tenants, report, nil
+}
+
+// tenantFiles returns the _base file of a tenant dir, if any, and the file
+// of every tenant by name.
+func tenantFiles(dir string) (base string, files map[string]string, err error) {
+	paths, err := dirSource(dir).files()
+	if err != nil {
+		return "", nil, fmt.Errorf("read tenant dir %s: %w", dir, err)
+	}
+	files = make(map[string]string)
+	for _, path := range paths {
+		name, _ := cutEncryptedSuffix(filepath.Base(path))
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		switch {
+		case name == tenantBaseName:
+			if base != "" {
+				return "", nil, fmt.Errorf("tenant dir %s: both %s and %s", dir, base, path)
+			}
+			base = path
+		case strings.HasPrefix(name, "_") || strings.Contains(name, "."):
+			// Reserved, or a profile variant.
+		case files[name] != "":
+			return "", nil, fmt.Errorf("tenant dir %s: tenant %s has two files, %s and %s", dir, name, files[name], path)
+		default:
+			files[name] = path
+		}
+	}
+	return base, files, nil
+}
+
+// tenantLayers returns the layers of the tenant being loaded by loadDir:
+// the _base file and then the tenant's file, each followed by its profile
+// variant.
+func (l *loader) tenantLayers() []layer {
+	var layers []layer
+	for _, path := range []string{l.tenantBase, l.tenant} {
+		if path == "" {
+			continue
+		}
+		layers = append(layers, layer{src: fileSource(path), name: path})
+		if l.profile != "" {
+			p := profilePath(path, l.profile)
+			layers = append(layers, layer{src: fileSource(p), name: p, optional: true})
+		}
+	}
+	return layers
+}
@@ -0,0 +1,93 @@
+// tenants_test.go
+package gonfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTenantDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := writeTenantDir(t, map[string]string{
+		"_base.yaml":      "app_name: saas\nserver:\n  port: 8080\n  log_level: info\n",
+		"_base.prod.yaml": "server:\n  log_level: warn\n",
+		"acme.yaml":       "app_name: acme\n",
+		"acme.prod.yaml":  "server:\n  port: 9000\n",
+		"globex.yml":      "server:\n  port: 8081\n",
+		"_notes.yaml":     "not: a tenant\n",
+		"README.md":       "# tenants\n",
+	})
+
+	tenants, err := LoadDir[testConfig](dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if len(tenants) != 2 {
+		t.Fatalf("expected tenants acme and globex, got %v", tenants)
+	}
+	acme, globex := tenants["acme"], tenants["globex"]
+	if acme.AppName != "acme" || acme.Server.Port != 8080 || acme.Server.LogLevel != "info" {
+		t.Fatalf("unexpected acme config: %+v", acme)
+	}
+	if globex.AppName != "saas" || globex.Server.Port != 8081 {
+		t.Fatalf("unexpected globex config: %+v", globex)
+	}
+
+	tenants, err = LoadDir[testConfig](dir, WithProfile("prod"))
+	if err != nil {
+		t.Fatalf("LoadDir with profile: %v", err)
+	}
+	if acme := tenants["acme"]; acme.Server.Port != 9000 || acme.Server.LogLevel != "warn" {
+		t.Fatalf("unexpected prod acme config: %+v", acme)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "initech.yaml"), []byte("server:\n  port: many\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadDir[testConfig](dir)
+	if err == nil || !strings.Contains(err.Error(), "tenant initech: parse "+filepath.Join(dir, "initech.yaml")) {
+		t.Fatalf("expected the initech error, got %v", err)
+	}
+
+	_, err = LoadDir[testConfig](dir, WithSHA256(strings.Repeat("0", 64)))
+	if err == nil || !strings.Contains(err.Error(), "need a single config document") {
+		t.Fatalf("expected WithSHA256 to be rejected, got %v", err)
+	}
+}
+
+func TestNewLiveDir(t *testing.T) {
+	dir := writeTenantDir(t, map[string]string{
+		"_base.yaml": "server:\n  port: 8080\n",
+		"acme.yaml":  "app_name: acme\n",
+	})
+	live, err := NewLiveDir[testConfig](t.Context(), dir, WithReloadDebounce(0))
+	if err != nil {
+		t.Fatalf("NewLiveDir: %v", err)
+	}
+	if got := live.Get()["acme"]; got.AppName != "acme" || got.Server.Port != 8080 {
+		t.Fatalf("unexpected acme config: %+v", got)
+	}
+
+	replaceFile(t, filepath.Join(dir, "globex.yaml"), "app_name: globex\n")
+	waitFor(t, func() bool { return live.Get()["globex"].AppName == "globex" })
+
+	replaceFile(t, filepath.Join(dir, "_base.yaml"), "server:\n  port: 9090\n")
+	waitFor(t, func() bool { return live.Get()["acme"].Server.Port == 9090 })
+
+	if err := os.Remove(filepath.Join(dir, "acme.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { _, ok := live.Get()["acme"]; return !ok })
+}
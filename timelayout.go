@@ -0,0 +1,72 @@
+// timelayout.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// timeLayouts are the names a `layout:"..."` tag may use instead of a
+// reference-time layout.
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	"Kitchen":     time.Kitchen,
+}
+
+// defaultTimeLayouts are tried in order for a field with a `tz` tag but no
+// `layout`.
+var defaultTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", time.DateTime, time.DateOnly}
+
+// timeDecoder returns the decoder selected by the `layout:"..."` and
+// `tz:"..."` tags of a time.Time (or *time.Time) field. The layout is a
+// reference-time layout like "2006-01-02" or the name of a time package
+// constant like "DateOnly"; tz is an IANA zone like "America/New_York"
+// that values without an offset are read in (UTC by default).
+func timeDecoder(f reflect.StructField) (decodeFunc, bool) {
+	layout, hasLayout := f.Tag.Lookup("layout")
+	zone, hasZone := f.Tag.Lookup("tz")
+	if !hasLayout && !hasZone {
+		return nil, false
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != reflect.TypeFor[time.Time]() {
+		return nil, false
+	}
+	if named, ok := timeLayouts[layout]; ok {
+		layout = named
+	}
+	return func(s string) (reflect.Value, error) {
+		loc := time.UTC
+		if hasZone {
+			var err error
+			if loc, err = time.LoadLocation(zone); err != nil {
+				return reflect.Value{}, fmt.Errorf("tz %q: %w", zone, err)
+			}
+		}
+		if hasLayout {
+			tm, err := time.ParseInLocation(layout, s, loc)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%q doesn't match layout %q", s, layout)
+			}
+			return reflect.ValueOf(tm), nil
+		}
+		for _, l := range defaultTimeLayouts {
+			if tm, err := time.ParseInLocation(l, s, loc); err == nil {
+				return reflect.ValueOf(tm), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("%q is not a date or RFC 3339 time; set a layout tag", s)
+	}, true
+}
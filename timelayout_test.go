@@ -0,0 +1,50 @@
+// timelayout_test.go
+package gonfig
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_TimeLayoutTags(t *testing.T) {
+	type config struct {
+		Launch   time.Time  `yaml:"launch" layout:"2006-01-02" tz:"America/New_York"`
+		Freeze   *time.Time `yaml:"freeze" layout:"DateOnly"`
+		Maint    time.Time  `yaml:"maint" tz:"Europe/Berlin"`
+		Cutoff   time.Time  `yaml:"cutoff" layout:"02/01/2006 15:04" default:"31/12/2026 23:59"`
+		Untagged time.Time  `yaml:"untagged"`
+	}
+	t.Setenv("APP_MAINT", "2026-03-01 02:00:00")
+	raw := "launch: 2026-11-03\nfreeze: \"2026-12-20\"\nuntagged: 2026-01-02T03:04:05Z\n"
+	cfg, err := Load[config](WithBytes([]byte(raw)), WithEnvOverrides("APP"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	ny, _ := time.LoadLocation("America/New_York")
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	for name, c := range map[string]struct{ got, want time.Time }{
+		"launch":   {cfg.Launch, time.Date(2026, 11, 3, 0, 0, 0, 0, ny)},
+		"freeze":   {*cfg.Freeze, time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)},
+		"maint":    {cfg.Maint, time.Date(2026, 3, 1, 2, 0, 0, 0, berlin)},
+		"cutoff":   {cfg.Cutoff, time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC)},
+		"untagged": {cfg.Untagged, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+	} {
+		if !c.got.Equal(c.want) || c.got.Location().String() != c.want.Location().String() {
+			t.Errorf("%s = %v, want %v", name, c.got, c.want)
+		}
+	}
+
+	_, err = Load[config](WithBytes([]byte("launch: 11/03/2026\n")))
+	if err == nil || !strings.Contains(err.Error(), `line 1: cannot decode "11/03/2026" into time.Time: "11/03/2026" doesn't match layout "2006-01-02"`) {
+		t.Fatalf("expected a layout mismatch error, got %v", err)
+	}
+
+	type badZone struct {
+		At time.Time `yaml:"at" tz:"Mars/Olympus_Mons"`
+	}
+	if _, err := Load[badZone](WithBytes([]byte("at: 2026-01-01\n"))); err == nil || !strings.Contains(err.Error(), `tz "Mars/Olympus_Mons"`) {
+		t.Fatalf("expected an unknown time zone error, got %v", err)
+	}
+}
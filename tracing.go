@@ -0,0 +1,69 @@
+// tracing.go
+package gonfig
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of gonfig's spans.
+const tracerName = "github.com/TypeTerrors/gonfig"
+
+// WithTracerProvider traces loading with OpenTelemetry. Every load (and
+// every reload of Watch and NewLive) becomes a "gonfig.Load" span, with
+// child spans for each phase:
+//
+//   - gonfig.dotenv: loading one dotenv file
+//   - gonfig.fetch: fetching one config layer from its source
+//   - gonfig.expand: expanding the placeholders of one layer
+//   - gonfig.resolve: one ${scheme:key} resolver call, e.g. to Vault
+//   - gonfig.decode: decoding the merged document into the config type
+//   - gonfig.validate: required fields, validators, Validate and AfterLoad
+//
+// The load span is a child of the span in the LoadContext context, if any.
+// Spans record errors, never config values.
+//
+// Example:
+//
+//	cfg, err := gonfig.LoadContext[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithTracerProvider(otel.GetTracerProvider()),
+//	)
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(l *loader) {
+		l.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan starts a span as a child of l.ctx and makes it the parent of
+// the spans started until the returned function ends it with the phase's
+// error. Without WithTracerProvider, it does nothing.
+func (l *loader) startSpan(name string, attrs ...attribute.KeyValue) (end func(err error)) {
+	parent := l.ctx
+	ctx, endSpan := l.span(parent, name, attrs...)
+	l.ctx = ctx
+	return func(err error) {
+		endSpan(err)
+		l.ctx = parent
+	}
+}
+
+// span starts a span as a child of ctx, for phases that run concurrently
+// and so can't use startSpan. It returns ctx unchanged without
+// WithTracerProvider.
+func (l *loader) span(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	if l.tracer == nil {
+		return ctx, func(error) {}
+	}
+	ctx, span := l.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,64 @@
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestLoad_WithTracerProvider(t *testing.T) {
+	path := writeConfig(t, "app_name: ${vault:app#name}\nserver:\n  port: 8080\n")
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	_, err := Load[testConfig](
+		WithConfigFile(path),
+		WithDotenv(filepath.Join(t.TempDir(), "missing.env")),
+		WithResolver("vault", func(context.Context, string) (string, error) { return "api", nil }),
+		WithValidator(func(any) error { return errors.New("port is reserved") }),
+		WithTracerProvider(tp),
+	)
+	if err == nil {
+		t.Fatalf("expected a validation error")
+	}
+
+	spans := rec.Ended()
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	var names []string
+	for _, s := range spans {
+		byName[s.Name()] = s
+		names = append(names, s.Name())
+	}
+	want := []string{"gonfig.dotenv", "gonfig.fetch", "gonfig.resolve", "gonfig.expand", "gonfig.decode", "gonfig.validate", "gonfig.Load"}
+	if len(names) != len(want) {
+		t.Fatalf("spans = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("spans = %v, want %v", names, want)
+		}
+	}
+
+	root := byName["gonfig.Load"].SpanContext().SpanID()
+	for _, name := range []string{"gonfig.dotenv", "gonfig.fetch", "gonfig.expand", "gonfig.decode", "gonfig.validate"} {
+		if got := byName[name].Parent().SpanID(); got != root {
+			t.Fatalf("%s is not a child of gonfig.Load", name)
+		}
+	}
+	if got := byName["gonfig.resolve"].Parent().SpanID(); got != byName["gonfig.expand"].SpanContext().SpanID() {
+		t.Fatalf("gonfig.resolve is not a child of gonfig.expand")
+	}
+	if got := byName["gonfig.dotenv"].Status().Code; got != codes.Unset {
+		t.Fatalf("missing dotenv status = %v, want unset", got)
+	}
+	for _, name := range []string{"gonfig.validate", "gonfig.Load"} {
+		if got := byName[name].Status().Code; got != codes.Error {
+			t.Fatalf("%s status = %v, want error", name, got)
+		}
+	}
+}
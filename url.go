@@ -0,0 +1,212 @@
+// url.go
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// URLOption configures a remote config source created by WithConfigURL.
+type URLOption func(*urlSource)
+
+// WithURLHeader sets a request header, e.g. an Authorization header.
+func WithURLHeader(name, value string) URLOption {
+	return func(s *urlSource) {
+		s.header.Set(name, value)
+	}
+}
+
+// WithURLTimeout bounds each HTTP attempt. The default is 10 seconds.
+func WithURLTimeout(d time.Duration) URLOption {
+	return func(s *urlSource) {
+		s.timeout = d
+	}
+}
+
+// WithURLRetries retries failed fetches (network errors, 429 and 5xx) up to
+// n more times with exponential backoff. The default is no retries.
+func WithURLRetries(n int) URLOption {
+	return func(s *urlSource) {
+		s.retries = n
+	}
+}
+
+// WithURLFallbackFile keeps a copy of the last successfully fetched config
+// at path and loads it when the server can't be reached. This lets services
+// start with their last known config while the config service is down.
+// Only network errors, 429 and 5xx responses fall back; other responses,
+// such as 401, 403 and 404, are returned as errors.
+func WithURLFallbackFile(path string) URLOption {
+	return func(s *urlSource) {
+		s.fallback = path
+	}
+}
+
+// WithURLClient replaces the HTTP client, e.g. to configure TLS or a proxy.
+func WithURLClient(c *http.Client) URLOption {
+	return func(s *urlSource) {
+		s.client = c
+	}
+}
+
+// urlSource fetches a config document over HTTP(S). It remembers the last
+// response so repeated loads (e.g. from Watch or NewLive) send
+// If-None-Match / If-Modified-Since and reuse the body on 304.
+type urlSource struct {
+	url      string
+	client   *http.Client
+	timeout  time.Duration
+	header   http.Header
+	retries  int
+	fallback string
+
+	mu           sync.Mutex
+	body         []byte
+	etag         string
+	lastModified string
+}
+
+func newURLSource(url string, opts []URLOption) *urlSource {
+	s := &urlSource{
+		url:     url,
+		client:  http.DefaultClient,
+		timeout: 10 * time.Second,
+		header:  make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Fetch returns the config document, falling back to the fallback file when
+// every attempt fails with a retryable error.
+func (s *urlSource) Fetch(ctx context.Context) ([]byte, error) {
+	return s.fetchLimited(ctx, 0)
+}
+
+// fetchLimited is Fetch that stops reading a response body larger than max
+// bytes (zero means no limit).
+func (s *urlSource) fetchLimited(ctx context.Context, max int64) ([]byte, error) {
+	var (
+		err   error
+		retry bool
+	)
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<(attempt-1)) * 200 * time.Millisecond
+			select {
+			case <-ctx.Done():
+				return s.useFallback(ctx.Err())
+			case <-time.After(backoff):
+			}
+		}
+
+		var body []byte
+		body, retry, err = s.get(ctx, max)
+		if err == nil {
+			return body, nil
+		}
+		if !retry || ctx.Err() != nil {
+			break
+		}
+	}
+	if !retry {
+		// The server answered, e.g. 401, 403 or 404, or the document is
+		// too large; a stale copy would hide a real misconfiguration.
+		return nil, err
+	}
+	return s.useFallback(err)
+}
+
+func (s *urlSource) String() string { return s.url }
+
+// get performs a single conditional GET, reading at most max bytes of the
+// body. retry reports whether a failure is worth retrying.
+func (s *urlSource) get(ctx context.Context, max int64) (body []byte, retry bool, err error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, v := range s.header {
+		req.Header[k] = v
+	}
+
+	s.mu.Lock()
+	if s.body != nil {
+		if s.etag != "" {
+			req.Header.Set("If-None-Match", s.etag)
+		}
+		if s.lastModified != "" {
+			req.Header.Set("If-Modified-Since", s.lastModified)
+		}
+	}
+	s.mu.Unlock()
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// Network errors and attempt timeouts are retryable; Fetch stops
+		// retrying once the parent context is done.
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.body != nil {
+			return s.body, false, nil
+		}
+		return nil, false, fmt.Errorf("GET %s: unexpected 304 without a cached body", s.url)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		if max > 0 && resp.ContentLength > max {
+			return nil, false, fmt.Errorf("GET %s: %w: document is %d bytes, over the limit of %d", s.url, ErrLimitExceeded, resp.ContentLength, max)
+		}
+		body, err := readLimited(resp.Body, max)
+		if errors.Is(err, ErrLimitExceeded) {
+			return nil, false, fmt.Errorf("GET %s: %w", s.url, err)
+		}
+		if err != nil {
+			return nil, true, err
+		}
+		s.mu.Lock()
+		s.body = body
+		s.etag = resp.Header.Get("ETag")
+		s.lastModified = resp.Header.Get("Last-Modified")
+		s.mu.Unlock()
+		if s.fallback != "" {
+			// Best-effort: failing to refresh the fallback must not fail
+			// a successful fetch.
+			_ = os.WriteFile(s.fallback, body, 0o600)
+		}
+		return body, false, nil
+	default:
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retry, fmt.Errorf("GET %s: %s", s.url, resp.Status)
+	}
+}
+
+// useFallback returns the fallback file if one is configured and readable,
+// and fetchErr otherwise.
+func (s *urlSource) useFallback(fetchErr error) ([]byte, error) {
+	if s.fallback == "" {
+		return nil, fetchErr
+	}
+	data, err := os.ReadFile(s.fallback)
+	if err != nil {
+		return nil, errors.Join(fetchErr, fmt.Errorf("read fallback: %w", err))
+	}
+	return data, nil
+}
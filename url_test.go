@@ -0,0 +1,106 @@
+package gonfig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoad_ConfigURL(t *testing.T) {
+	var hits, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if n == 1 {
+			// First attempt fails transiently and must be retried.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("app_name: remote\n"))
+	}))
+	defer srv.Close()
+
+	fallback := filepath.Join(t.TempDir(), "config.yaml")
+	opt := WithConfigURL(srv.URL,
+		WithURLHeader("Authorization", "Bearer t0k3n"),
+		WithURLRetries(2),
+		WithURLFallbackFile(fallback),
+	)
+
+	for i := 0; i < 2; i++ {
+		cfg, err := Load[testConfig](opt)
+		if err != nil {
+			t.Fatalf("Load #%d: %v", i, err)
+		}
+		if cfg.AppName != "remote" {
+			t.Fatalf("expected app_name remote, got %q", cfg.AppName)
+		}
+	}
+	if notModified.Load() != 1 {
+		t.Fatalf("expected second load to be served from the ETag cache")
+	}
+
+	data, err := os.ReadFile(fallback)
+	if err != nil || string(data) != "app_name: remote\n" {
+		t.Fatalf("expected fallback file to hold the fetched config, got %q (%v)", data, err)
+	}
+
+	srv.Close()
+	cfg, err := Load[testConfig](WithConfigURL(srv.URL, WithURLFallbackFile(fallback)))
+	if err != nil {
+		t.Fatalf("expected fallback to be used when the server is down, got %v", err)
+	}
+	if cfg.AppName != "remote" {
+		t.Fatalf("expected app_name from fallback, got %q", cfg.AppName)
+	}
+}
+
+func TestLoad_ConfigURLClientError(t *testing.T) {
+	fallback := filepath.Join(t.TempDir(), "last.yaml")
+	if err := os.WriteFile(fallback, []byte("app_name: stale\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		_, err := Load[testConfig](WithConfigURL(srv.URL, WithURLRetries(3), WithURLFallbackFile(fallback)))
+		srv.Close()
+		if err == nil || !strings.Contains(err.Error(), http.StatusText(status)) {
+			t.Fatalf("expected the %d error instead of the fallback, got %v", status, err)
+		}
+	}
+}
+
+func TestLoad_ConfigURLMaxConfigSize(t *testing.T) {
+	fallback := filepath.Join(t.TempDir(), "last.yaml")
+	if err := os.WriteFile(fallback, []byte("app_name: stale\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flush first so the response is chunked, without a Content-Length.
+		w.Write([]byte("app_name: "))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(strings.Repeat("x", 10_000)))
+	}))
+	defer srv.Close()
+
+	_, err := Load[testConfig](WithConfigURL(srv.URL, WithURLFallbackFile(fallback)), WithMaxConfigSize(1024))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded instead of the fallback, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package validate runs go-playground/validator struct tags on configs
+// loaded with gonfig.
+//
+// Failures are reported as *gonfig.FieldError values keyed by YAML path
+// inside the usual *gonfig.ValidationError:
+//
+//	type ServerConfig struct {
+//	    Port int    `yaml:"port" validate:"min=1,max=65535"`
+//	    Env  string `yaml:"env" validate:"oneof=dev prod"`
+//	}
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    validate.WithTags(),
+//	)
+//	// config validation failed: server.port (config.yaml:3:11): must satisfy min=1
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// WithTags validates `validate:"..."` struct tags with a default validator.
+func WithTags() gonfig.Option {
+	return WithValidator(validator.New(validator.WithRequiredStructEnabled()))
+}
+
+// WithValidator validates struct tags with v, e.g. one with custom
+// validations registered. v's tag name function is replaced so that errors
+// use YAML keys.
+func WithValidator(v *validator.Validate) gonfig.Option {
+	v.RegisterTagNameFunc(yamlName)
+	return gonfig.WithValidator(func(cfg any) error {
+		if t := reflect.TypeOf(cfg); t == nil || indirect(t).Kind() != reflect.Struct {
+			return nil
+		}
+		err := v.Struct(cfg)
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return err
+		}
+		root := indirect(reflect.TypeOf(cfg))
+		errs := make([]error, len(verrs))
+		for i, fe := range verrs {
+			errs[i] = &gonfig.FieldError{Path: fieldPath(root, fe), Err: ruleError(fe)}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// yamlName names struct fields by their YAML key, like yaml.v3 does.
+func yamlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// inline reports whether f's fields are decoded into its parent, with
+// `yaml:",inline"` or `gonfig:"squash"`, so it has no key of its own.
+func inline(f reflect.StructField) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	return slices.Contains(strings.Split(opts, ","), "inline") ||
+		slices.Contains(strings.Split(f.Tag.Get("gonfig"), ","), "squash")
+}
+
+// fieldPath turns the error's struct namespace, e.g.
+// "Config.Server.TLS.Cert" or "Config.Workers[mail].Port", into the YAML
+// path gonfig uses for the field, e.g. "server.cert" when TLS is inline
+// or "workers.mail.port". root is the type of the validated config.
+func fieldPath(root reflect.Type, fe validator.FieldError) string {
+	_, ns, _ := strings.Cut(fe.StructNamespace(), ".")
+	var path strings.Builder
+	t := root
+	for _, seg := range strings.Split(ns, ".") {
+		name, index, _ := strings.Cut(seg, "[")
+		var f reflect.StructField
+		ok := t.Kind() == reflect.Struct
+		if ok {
+			f, ok = t.FieldByName(name)
+		}
+		if !ok {
+			// Not a field we can follow; fall back to the tag names.
+			_, p, _ := strings.Cut(fe.Namespace(), ".")
+			return p
+		}
+		if !inline(f) {
+			if path.Len() > 0 {
+				path.WriteByte('.')
+			}
+			path.WriteString(yamlName(f))
+		}
+		t = indirect(f.Type)
+		for index != "" {
+			key, rest, _ := strings.Cut(index, "]")
+			index = strings.TrimPrefix(rest, "[")
+			if t.Kind() == reflect.Map {
+				path.WriteString("." + key)
+			} else {
+				path.WriteString("[" + key + "]")
+			}
+			t = indirect(t.Elem())
+		}
+	}
+	return path.String()
+}
+
+// ruleError describes the failed rule, e.g. "must satisfy min=1".
+func ruleError(fe validator.FieldError) error {
+	rule := fe.Tag()
+	if fe.Param() != "" {
+		rule += "=" + fe.Param()
+	}
+	return fmt.Errorf("must satisfy %s", rule)
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+type serverConfig struct {
+	Port int    `yaml:"port" validate:"min=1,max=65535"`
+	Env  string `yaml:"env" validate:"oneof=dev prod"`
+	URL  string `yaml:"public_url" validate:"omitempty,url"`
+}
+
+type config struct {
+	Server serverConfig `yaml:"server"`
+}
+
+func TestWithTags(t *testing.T) {
+	_, err := gonfig.Load[config](
+		gonfig.WithBytes([]byte("server:\n  port: 0\n  env: staging\n  public_url: not a url\n")),
+		WithTags(),
+	)
+	var vErr *gonfig.ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected *gonfig.ValidationError, got %T: %v", err, err)
+	}
+	var fe *gonfig.FieldError
+	if !errors.As(err, &fe) || fe.Path != "server.port" {
+		t.Fatalf("expected a FieldError for server.port, got %v", err)
+	}
+	for _, want := range []string{
+		"server.port (<bytes>:2:9): must satisfy min=1",
+		"server.env (<bytes>:3:8): must satisfy oneof=dev prod",
+		"server.public_url (<bytes>:4:15): must satisfy url",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got %v", want, err)
+		}
+	}
+
+	cfg, err := gonfig.Load[config](
+		gonfig.WithBytes([]byte("server:\n  port: 8080\n  env: prod\n")),
+		WithTags(),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 8080 {
+		t.Fatalf("expected port 8080, got %d", cfg.Server.Port)
+	}
+}
+
+func TestWithTags_Section(t *testing.T) {
+	_, err := gonfig.Load[serverConfig](
+		gonfig.WithBytes([]byte("app: api\nserver:\n  port: 0\n  env: prod\n")),
+		gonfig.WithSection("server"),
+		WithTags(),
+	)
+	var fe *gonfig.FieldError
+	if !errors.As(err, &fe) || fe.Path != "server.port" {
+		t.Fatalf("expected a FieldError for server.port, got %v", err)
+	}
+	if want := "server.port (<bytes>:3:9): must satisfy min=1"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected %q in error, got %v", want, err)
+	}
+}
+
+type tlsConfig struct {
+	Cert string `yaml:"cert" validate:"required"`
+}
+
+type limitsConfig struct {
+	Max int `yaml:"max" validate:"min=1"`
+}
+
+type embeddedConfig struct {
+	Server struct {
+		tlsConfig `yaml:",inline"`
+		Limits    limitsConfig            `yaml:"limits" gonfig:"squash"`
+		Workers   map[string]limitsConfig `yaml:"workers" validate:"dive"`
+		Hosts     []string                `yaml:"hosts" validate:"dive,hostname"`
+	} `yaml:"server"`
+}
+
+func TestWithTags_InlinePaths(t *testing.T) {
+	_, err := gonfig.Load[embeddedConfig](
+		gonfig.WithBytes([]byte("server:\n  cert: \"\"\n  max: 0\n  workers:\n    mail:\n      max: 0\n  hosts: [\"not a host\"]\n")),
+		WithTags(),
+	)
+	for _, want := range []string{
+		"server.cert (<bytes>:2:9): must satisfy required",
+		"server.max (<bytes>:3:8): must satisfy min=1",
+		"server.workers.mail.max (<bytes>:6:12): must satisfy min=1",
+		"server.hosts[0] (<bytes>:7:11): must satisfy hostname",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in error, got %v", want, err)
+		}
+	}
+}
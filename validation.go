@@ -0,0 +1,169 @@
+// validation.go
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validator is implemented by configs and sections with a Validate hook.
+type validator interface {
+	Validate() error
+}
+
+// validateNested calls Validate() on every section below v that has it,
+// with a value or pointer receiver, and returns the failures as FieldErrors
+// at the section's path. Inner sections come first; a section whose inner
+// sections failed isn't validated itself, since its Validate often calls
+// theirs. Map entries are validated in key order, so errors come out the
+// same way every time.
+func validateNested(v reflect.Value, path string) []error {
+	var errs []error
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return validateNested(v.Elem(), path)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateSection(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			errs = append(errs, validateSection(v.MapIndex(k), joinPath(path, k.String()))...)
+		}
+	case reflect.Struct:
+		if isLeafType(v.Type()) {
+			return nil
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			key, inline, skip := yamlFieldName(f)
+			if skip {
+				continue
+			}
+			if inline {
+				errs = append(errs, validateNested(v.Field(i), path)...)
+			} else {
+				errs = append(errs, validateSection(v.Field(i), joinPath(path, key))...)
+			}
+		}
+	}
+	return errs
+}
+
+// validateSection validates the sections below v, then v itself.
+func validateSection(v reflect.Value, path string) []error {
+	if errs := validateNested(v, path); len(errs) > 0 {
+		return errs
+	}
+	var hook validator
+	switch {
+	case v.Kind() == reflect.Pointer && v.IsNil():
+		return nil
+	case v.CanInterface() && implements[validator](v):
+		hook = v.Interface().(validator)
+	case v.CanAddr() && implements[validator](v.Addr()):
+		hook = v.Addr().Interface().(validator)
+	default:
+		return nil
+	}
+	if err := hook.Validate(); err != nil {
+		return []error{&FieldError{Path: path, Err: err}}
+	}
+	return nil
+}
+
+// implements reports whether v's type implements I.
+func implements[I any](v reflect.Value) bool {
+	return v.Type().Implements(reflect.TypeFor[I]())
+}
+
+// locateFieldErrors fills in the source location of every FieldError in
+// err's tree that has none: where its value came from if it was read from
+// a file, or else where its key is in doc (for sections). root is the YAML
+// path of doc (WithSection).
+func (l *loader) locateFieldErrors(err error, doc *yaml.Node, root string, report *Report) {
+	switch e := err.(type) {
+	case *FieldError:
+		if e.File != "" {
+			break
+		}
+		if o, ok := report.Origins[e.Path]; ok && o.Kind == FromFile {
+			e.File, e.Line, e.Column = o.File, o.Line, o.Column
+		} else if segs, ok := relativePath(root, e.Path); ok && len(segs) > 0 {
+			if n := keyNodeAt(doc, segs); n != nil {
+				e.File, e.Line, e.Column = l.fileOf(n), n.Line, n.Column
+			}
+		}
+		l.locateFieldErrors(e.Err, doc, root, report)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			l.locateFieldErrors(err, doc, root, report)
+		}
+	default:
+		if err := errors.Unwrap(err); err != nil {
+			l.locateFieldErrors(err, doc, root, report)
+		}
+	}
+}
+
+// rebaseFieldErrors prefixes the paths of the FieldErrors in err, which a
+// WithValidator function reported relative to the section it was given,
+// with root, the section's path.
+func rebaseFieldErrors(err error, root string) {
+	if root == "" {
+		return
+	}
+	switch e := err.(type) {
+	case *FieldError:
+		if e.Path == "" || strings.HasPrefix(e.Path, "[") {
+			e.Path = root + e.Path
+		} else {
+			e.Path = joinPath(root, e.Path)
+		}
+		rebaseFieldErrors(e.Err, root)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			rebaseFieldErrors(err, root)
+		}
+	default:
+		if err := errors.Unwrap(err); err != nil {
+			rebaseFieldErrors(err, root)
+		}
+	}
+}
+
+// keyNodeAt returns the mapping key (or list item) of the value at segs
+// below doc, or nil if there is none.
+func keyNodeAt(doc *yaml.Node, segs []string) *yaml.Node {
+	parent := nodeAt(doc, segs[:len(segs)-1])
+	if parent == nil {
+		return nil
+	}
+	last := segs[len(segs)-1]
+	switch parent.Kind {
+	case yaml.MappingNode:
+		if i := mappingIndex(parent, last); i >= 0 {
+			return parent.Content[i]
+		}
+	case yaml.SequenceNode:
+		return nodeAt(parent, []string{last})
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+// values.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithOverride forces the value at a YAML path, e.g. "server.port" or
+// "servers[0].host", after the config file and env overrides have been
+// applied. value is converted like a YAML value, so 9090, "9090" and
+// "30s" land in int, int and time.Duration fields; a map or struct value is
+// merged into the fields below path. A path that doesn't exist in the
+// config type makes Load fail.
+//
+// Overrides are applied in the order they are given, so CLIs can map flags
+// onto config paths and tests can pin values without writing temp files.
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithOverride("server.port", 9090),
+//	)
+func WithOverride(path string, value any) Option {
+	return func(l *loader) {
+		l.overrides = append(l.overrides, override{path: path, value: value})
+	}
+}
+
+// WithValues is WithOverride for every entry of values. Entries are applied
+// in key order, so "server" is applied before "server.port".
+//
+// Example:
+//
+//	cfg, err := gonfig.Load[Config](
+//	    gonfig.WithValues(map[string]any{
+//	        "app_name":         "test",
+//	        "server.log_level": "debug",
+//	    }),
+//	)
+func WithValues(values map[string]any) Option {
+	paths := make([]string, 0, len(values))
+	for p := range values {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return func(l *loader) {
+		for _, p := range paths {
+			l.overrides = append(l.overrides, override{path: p, value: values[p]})
+		}
+	}
+}
+
+// override is a single WithOverride value.
+type override struct {
+	path  string
+	value any
+}
+
+// applyOverrides sets every override on the decoded config v, whose YAML
+// path is root, and calls record with the path of every value it set.
+func applyOverrides(v reflect.Value, root string, overrides []override, record func(path string)) error {
+	for _, o := range overrides {
+		var n yaml.Node
+		if err := n.Encode(revealSecrets(reflect.ValueOf(o.value))); err != nil {
+			return fmt.Errorf("override %s: %w", o.path, err)
+		}
+		segs, ok := relativePath(root, o.path)
+		if !ok {
+			return fmt.Errorf("override %s: outside of section %s", o.path, root)
+		}
+		if err := setPath(v, segs, &n); err != nil {
+			return fmt.Errorf("override %s: %w", o.path, err)
+		}
+		leafPaths(&n, o.path, record)
+	}
+	return nil
+}
+
+// revealSecrets returns v for encoding as an override, with every Secret in
+// it replaced by its value: Secret.MarshalYAML would otherwise store the
+// mask. Values without a Secret are returned as they are.
+func revealSecrets(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if !hasSecret(v.Type(), map[reflect.Type]bool{}) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return revealSecrets(v.Elem())
+	case reflect.Slice, reflect.Array:
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = revealSecrets(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		out := make(map[any]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().Interface()] = revealSecrets(iter.Value())
+		}
+		return out
+	case reflect.Struct:
+		if _, ok := v.Interface().(yaml.Marshaler); ok {
+			return v.Interface()
+		}
+		out := make(map[string]any)
+		revealFields(v, out)
+		return out
+	}
+	return v.Interface()
+}
+
+// revealFields adds the fields of the struct v to out by YAML key, the
+// fields of inline structs included.
+func revealFields(v reflect.Value, out map[string]any) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		key, inline, skip := yamlFieldName(f)
+		fv := v.Field(i)
+		switch {
+		case skip:
+		case inline && fv.Kind() == reflect.Struct:
+			revealFields(fv, out)
+		case strings.Contains(f.Tag.Get("yaml"), ",omitempty") && fv.IsZero():
+		default:
+			out[key] = revealSecrets(fv)
+		}
+	}
+}
+
+// hasSecret reports whether values of type t can hold a Secret.
+func hasSecret(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t == secretType {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return hasSecret(t.Elem(), seen)
+	case reflect.Map:
+		return hasSecret(t.Elem(), seen)
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && hasSecret(t.Field(i).Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relativePath returns the segments of path below root, and false if path
+// is not below root.
+func relativePath(root, path string) ([]string, bool) {
+	segs, rootSegs := splitPath(path), splitPath(root)
+	if len(segs) < len(rootSegs) {
+		return nil, false
+	}
+	for i, seg := range rootSegs {
+		if segs[i] != seg {
+			return nil, false
+		}
+	}
+	return segs[len(rootSegs):], true
+}
+
+// splitPath splits a YAML path like "servers[0].host" into the segments
+// "servers", "0", "host".
+func splitPath(path string) []string {
+	var segs []string
+	for _, part := range strings.Split(path, ".") {
+		for {
+			i := strings.IndexByte(part, '[')
+			if i < 0 || !strings.HasSuffix(part, "]") {
+				break
+			}
+			if i > 0 {
+				segs = append(segs, part[:i])
+			}
+			part = part[i+1:]
+			j := strings.IndexByte(part, ']')
+			segs = append(segs, part[:j])
+			part = part[j+1:]
+			if part == "" {
+				break
+			}
+		}
+		if part != "" {
+			segs = append(segs, part)
+		}
+	}
+	return segs
+}
+
+// setPath decodes n into the value below v at segs, allocating nil pointers
+// and maps on the way.
+func setPath(v reflect.Value, segs []string, n *yaml.Node) error {
+	if len(segs) == 0 {
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+			// Like an env override: "9090" sets an int field.
+			return setFromString(v, n.Value)
+		}
+		return decodeWithHooks(n, v.Addr().Interface())
+	}
+	seg := segs[0]
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setPath(v.Elem(), segs, n)
+	case reflect.Struct:
+		if isLeafType(v.Type()) {
+			break
+		}
+		f, sf, ok := fieldByKey(v, seg)
+		if !ok {
+			return fmt.Errorf("unknown key %q", seg)
+		}
+		if fn, ok := fieldDecoder(sf); ok && len(segs) == 1 && n.Kind == yaml.ScalarNode {
+			return setDecoded(f, fn, n.Value)
+		}
+		return setPath(f, segs[1:], n)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// Map elements are not addressable: copy, set, store back.
+		key := reflect.ValueOf(seg).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if cur := v.MapIndex(key); cur.IsValid() {
+			elem.Set(cur)
+		}
+		if err := setPath(elem, segs[1:], n); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(seg)
+		if err != nil || i < 0 || i >= v.Len() {
+			return fmt.Errorf("no list item %q (length %d)", seg, v.Len())
+		}
+		return setPath(v.Index(i), segs[1:], n)
+	}
+	return fmt.Errorf("can't set %q inside a %s", seg, v.Type())
+}
+
+// leafPaths calls fn with the path of every scalar in n.
+func leafPaths(n *yaml.Node, path string, fn func(path string)) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			leafPaths(c, path, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			leafPaths(n.Content[i+1], joinPath(path, n.Content[i].Value), fn)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			leafPaths(c, path+"["+strconv.Itoa(i)+"]", fn)
+		}
+	default:
+		fn(path)
+	}
+}
@@ -0,0 +1,95 @@
+package gonfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_WithOverride(t *testing.T) {
+	type config struct {
+		AppName string            `yaml:"app_name"`
+		Server  testServerConfig  `yaml:"server"`
+		Labels  map[string]string `yaml:"labels"`
+		Hosts   []string          `yaml:"hosts"`
+	}
+	t.Setenv("APP_SERVER_PORT", "8081")
+	path := writeConfig(t, "app_name: svc\nserver:\n  port: 8080\n  log_level: info\nhosts: [a, b]\n")
+
+	cfg, report, err := LoadWithReport[config](
+		WithConfigFile(path),
+		WithEnvOverrides("APP"),
+		WithOverride("server.port", 9090),
+		WithValues(map[string]any{
+			"server":           map[string]any{"timeout": "5s"},
+			"server.log_level": "debug",
+			"labels.team":      "core",
+			"hosts[1]":         "c",
+		}),
+	)
+	if err != nil {
+		t.Fatalf("LoadWithReport: %v", err)
+	}
+	if cfg.AppName != "svc" || cfg.Server.Port != 9090 || cfg.Server.LogLevel != "debug" || cfg.Server.Timeout != 5*time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Labels["team"] != "core" || cfg.Hosts[1] != "c" {
+		t.Fatalf("unexpected labels or hosts: %v %v", cfg.Labels, cfg.Hosts)
+	}
+	for _, p := range []string{"server.port", "server.timeout", "labels.team", "hosts[1]"} {
+		if got := report.Origins[p].String(); got != "override" {
+			t.Fatalf("origin of %s: got %q", p, got)
+		}
+	}
+
+	cfg, err = Load[config](WithBytes(nil), WithOverride("server.port", "9091"))
+	if err != nil || cfg.Server.Port != 9091 {
+		t.Fatalf("string override: cfg=%+v err=%v", cfg, err)
+	}
+
+	for path, want := range map[string]string{
+		"server.prot": `unknown key "prot"`,
+		"hosts[5]":    `no list item "5"`,
+		"app_name.x":  `can't set "x" inside a string`,
+	} {
+		_, err := Load[config](WithConfigFile(writeConfig(t, "hosts: [a]\n")), WithOverride(path, 1))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("override %s: got %v, want %q", path, err, want)
+		}
+	}
+}
+
+func TestLoad_WithOverrideSecret(t *testing.T) {
+	type database struct {
+		Password Secret `yaml:"password"`
+	}
+	type config struct {
+		Database database          `yaml:"database"`
+		Tokens   map[string]Secret `yaml:"tokens"`
+	}
+
+	cfg, err := Load[config](WithBytes(nil),
+		WithOverride("database.password", Secret("hunter2")),
+		WithOverride("tokens", map[string]Secret{"github": "ghp_x"}),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Database.Password.Value() != "hunter2" || cfg.Tokens["github"].Value() != "ghp_x" {
+		t.Fatalf("overrides stored masked secrets: %q %q", cfg.Database.Password.Value(), cfg.Tokens["github"].Value())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	live, err := NewLive[config](ctx, WithConfigFile(writeConfig(t, "database:\n  password: old\n")))
+	if err != nil {
+		t.Fatalf("NewLive: %v", err)
+	}
+	if err := live.Override("database", database{Password: "rotated"}, "ops"); err != nil {
+		t.Fatalf("Override: %v", err)
+	}
+	if got := live.Get().Database.Password.Value(); got != "rotated" {
+		t.Fatalf("password after Override = %q, want rotated", got)
+	}
+}
@@ -0,0 +1,172 @@
+// variants.go
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// variantSet holds the concrete types registered for an interface type.
+type variantSet struct {
+	// key is the discriminator field, "type" unless set with
+	// RegisterDiscriminator.
+	key   string
+	types map[string]reflect.Type
+}
+
+var (
+	variantsMu sync.RWMutex
+	variants   = map[reflect.Type]*variantSet{}
+)
+
+// RegisterVariant makes gonfig decode sections into fields of interface type
+// I (or []I, map[string]I) as the concrete type T, when the section's
+// discriminator field ("type" by default, see RegisterDiscriminator) is kind.
+// T, or *T, must implement I; with *T, the field holds a pointer.
+//
+// Register variants during init; registering a kind again replaces it.
+//
+// Example:
+//
+//	type Storage interface{ Open() (Bucket, error) }
+//
+//	type S3Storage struct {
+//	    Bucket string `yaml:"bucket"`
+//	    Region string `yaml:"region"`
+//	}
+//	type LocalStorage struct {
+//	    Dir string `yaml:"dir"`
+//	}
+//
+//	func init() {
+//	    gonfig.RegisterVariant[Storage, S3Storage]("s3")
+//	    gonfig.RegisterVariant[Storage, LocalStorage]("local")
+//	}
+//
+//	// config.yaml:
+//	//   storage:
+//	//     type: s3
+//	//     bucket: uploads
+//	//     region: eu-west-1
+//
+//	type Config struct {
+//	    Storage Storage `yaml:"storage"`
+//	}
+func RegisterVariant[I, T any](kind string) {
+	it, t := reflect.TypeFor[I](), reflect.TypeFor[T]()
+	if it.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("gonfig: RegisterVariant: %s is not an interface type", it))
+	}
+	if !t.Implements(it) {
+		if !reflect.PointerTo(t).Implements(it) {
+			panic(fmt.Sprintf("gonfig: RegisterVariant: %s does not implement %s", t, it))
+		}
+		t = reflect.PointerTo(t)
+	}
+	variantsMu.Lock()
+	defer variantsMu.Unlock()
+	variantsFor(it).types[kind] = t
+}
+
+// RegisterDiscriminator sets the field that selects the variant of
+// interface type I, e.g. "kind" for sections written Kubernetes-style. The
+// default is "type".
+func RegisterDiscriminator[I any](key string) {
+	variantsMu.Lock()
+	defer variantsMu.Unlock()
+	variantsFor(reflect.TypeFor[I]()).key = key
+}
+
+// variantsFor returns the variant set of it, creating it if needed. The
+// caller holds variantsMu.
+func variantsFor(it reflect.Type) *variantSet {
+	vs, ok := variants[it]
+	if !ok {
+		vs = &variantSet{key: "type", types: make(map[string]reflect.Type)}
+		variants[it] = vs
+	}
+	return vs
+}
+
+// hasVariants reports whether variants are registered for t.
+func hasVariants(t reflect.Type) bool {
+	if t.Kind() != reflect.Interface {
+		return false
+	}
+	variantsMu.RLock()
+	defer variantsMu.RUnlock()
+	_, ok := variants[t]
+	return ok
+}
+
+// variantType returns the concrete type registered for interface type it
+// that mapping node n selects, and the discriminator key.
+func variantType(it reflect.Type, n *yaml.Node) (reflect.Type, string, error) {
+	variantsMu.RLock()
+	defer variantsMu.RUnlock()
+	vs := variants[it]
+	var kind *yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == vs.key {
+			kind = n.Content[i+1]
+			break
+		}
+	}
+	if kind == nil || kind.Kind != yaml.ScalarNode {
+		return nil, vs.key, fmt.Errorf("line %d: %s needs a %q field (one of %s)", n.Line, it, vs.key, vs.kinds())
+	}
+	t, ok := vs.types[kind.Value]
+	if !ok {
+		return nil, vs.key, fmt.Errorf("line %d: unknown %s %s %q (one of %s)", kind.Line, it, vs.key, kind.Value, vs.kinds())
+	}
+	return t, vs.key, nil
+}
+
+// kinds lists the registered kinds for error messages.
+func (vs *variantSet) kinds() string {
+	kinds := make([]string, 0, len(vs.types))
+	for k := range vs.types {
+		kinds = append(kinds, k)
+	}
+	slices.Sort(kinds)
+	return strings.Join(kinds, ", ")
+}
+
+// decodeVariant decodes mapping node n into the variant of interface type
+// it that it selects.
+func decodeVariant(it reflect.Type, n *yaml.Node) (reflect.Value, error) {
+	t, _, err := variantType(it, n)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	elem := t
+	if t.Kind() == reflect.Pointer {
+		elem = t.Elem()
+	}
+	p := reflect.New(elem)
+	if err := decodeWithHooks(n, p.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if t.Kind() == reflect.Pointer {
+		return p, nil
+	}
+	return p.Elem(), nil
+}
+
+// withoutKey returns a copy of mapping node n without key, so the
+// discriminator isn't reported as an unknown key of the variant.
+func withoutKey(n *yaml.Node, key string) *yaml.Node {
+	c := *n
+	c.Content = nil
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != key {
+			c.Content = append(c.Content, n.Content[i], n.Content[i+1])
+		}
+	}
+	return &c
+}
@@ -0,0 +1,124 @@
+// variants_test.go
+package gonfig
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type testStorage interface{ Name() string }
+
+type testS3Storage struct {
+	Bucket   string  `yaml:"bucket"`
+	Endpoint url.URL `yaml:"endpoint"`
+	Retries  int     `yaml:"retries"`
+}
+
+func (s testS3Storage) Name() string { return "s3:" + s.Bucket }
+
+type testLocalStorage struct {
+	Dir string `yaml:"dir"`
+}
+
+func (s *testLocalStorage) Name() string { return "local:" + s.Dir }
+
+type testKindStorage interface{ Kind() string }
+
+type testMemStorage struct{}
+
+func (testMemStorage) Kind() string { return "mem" }
+
+func init() {
+	RegisterVariant[testStorage, testS3Storage]("s3")
+	RegisterVariant[testStorage, testLocalStorage]("local")
+	RegisterDiscriminator[testKindStorage]("kind")
+	RegisterVariant[testKindStorage, testMemStorage]("mem")
+}
+
+func TestLoad_Variants(t *testing.T) {
+	type config struct {
+		Primary  testStorage            `yaml:"primary"`
+		Replicas []testStorage          `yaml:"replicas"`
+		ByName   map[string]testStorage `yaml:"by_name"`
+		Cache    testKindStorage        `yaml:"cache"`
+		Unset    testStorage            `yaml:"unset"`
+	}
+	path := writeConfig(t, `primary:
+  type: s3
+  bucket: uploads
+  endpoint: https://s3.example.com
+replicas:
+  - type: local
+    dir: /srv/a
+  - type: s3
+    bucket: backup
+by_name:
+  tmp: {type: local, dir: /tmp}
+cache:
+  kind: mem
+`)
+	cfg, err := Load[config](WithConfigFile(path), WithKnownFieldsOnly())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	s3, ok := cfg.Primary.(testS3Storage)
+	if !ok || s3.Bucket != "uploads" || s3.Endpoint.Host != "s3.example.com" {
+		t.Fatalf("Primary = %#v", cfg.Primary)
+	}
+	if len(cfg.Replicas) != 2 || cfg.Replicas[0].Name() != "local:/srv/a" || cfg.Replicas[1].Name() != "s3:backup" {
+		t.Fatalf("Replicas = %#v", cfg.Replicas)
+	}
+	if _, ok := cfg.Replicas[0].(*testLocalStorage); !ok {
+		t.Fatalf("pointer variant should decode to a pointer, got %T", cfg.Replicas[0])
+	}
+	if cfg.ByName["tmp"] == nil || cfg.ByName["tmp"].Name() != "local:/tmp" {
+		t.Fatalf("ByName = %#v", cfg.ByName)
+	}
+	if cfg.Cache == nil || cfg.Cache.Kind() != "mem" {
+		t.Fatalf("Cache = %#v", cfg.Cache)
+	}
+	if cfg.Unset != nil {
+		t.Fatalf("Unset = %#v", cfg.Unset)
+	}
+}
+
+func TestLoad_VariantsWithWeakTypes(t *testing.T) {
+	type config struct {
+		Primary testStorage `yaml:"primary"`
+	}
+	path := writeConfig(t, "primary:\n  type: s3\n  retries: \"3\"\n")
+	cfg, err := Load[config](WithConfigFile(path), WithWeakTypes())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s3, ok := cfg.Primary.(testS3Storage); !ok || s3.Retries != 3 {
+		t.Fatalf("Primary = %#v", cfg.Primary)
+	}
+}
+
+func TestLoad_VariantErrors(t *testing.T) {
+	type config struct {
+		Primary testStorage `yaml:"primary"`
+	}
+	for name, tc := range map[string]struct {
+		yaml, want string
+		opts       []Option
+	}{
+		"unknown type": {yaml: "primary:\n  type: ftp\n", want: `unknown gonfig.testStorage type "ftp" (one of local, s3)`},
+		"no type":      {yaml: "primary:\n  bucket: x\n", want: `needs a "type" field`},
+		"unknown key": {
+			yaml: "primary:\n  type: local\n  dri: /srv\n",
+			want: "primary.dri",
+			opts: []Option{WithKnownFieldsOnly()},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, tc.yaml)
+			_, err := Load[config](append(tc.opts, WithConfigFile(path))...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
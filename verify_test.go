@@ -0,0 +1,85 @@
+// verify_test.go
+package gonfig
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad_WithSHA256(t *testing.T) {
+	content := "app_name: signed\n"
+	path := writeConfig(t, content)
+	sum := sha256.Sum256([]byte(content))
+
+	cfg, err := Load[testConfig](WithConfigFile(path), WithSHA256(hex.EncodeToString(sum[:])))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AppName != "signed" {
+		t.Fatalf("AppName = %q", cfg.AppName)
+	}
+
+	if err := os.WriteFile(path, []byte("app_name: tampered\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load[testConfig](WithConfigFile(path), WithSHA256(hex.EncodeToString(sum[:])))
+	if !errors.Is(err, ErrVerification) {
+		t.Fatalf("expected ErrVerification, got %v", err)
+	}
+
+	_, err = Load[testConfig](WithConfigFile(path), WithSHA256("not-hex"))
+	if err == nil || !strings.Contains(err.Error(), "invalid digest") {
+		t.Fatalf("expected invalid digest error, got %v", err)
+	}
+}
+
+func TestLoad_WithSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := "app_name: signed\n"
+	path := writeConfig(t, content)
+	sig := ed25519.Sign(priv, []byte(content))
+
+	for name, data := range map[string][]byte{
+		"raw":    sig,
+		"base64": []byte(base64.StdEncoding.EncodeToString(sig) + "\n"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			sigPath := filepath.Join(t.TempDir(), "config.yaml.sig")
+			if err := os.WriteFile(sigPath, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load[testConfig](WithConfigFile(path), WithSignature(pub, sigPath))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.AppName != "signed" {
+				t.Fatalf("AppName = %q", cfg.AppName)
+			}
+		})
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	sigPath := filepath.Join(t.TempDir(), "config.yaml.sig")
+	if err := os.WriteFile(sigPath, sig, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load[testConfig](WithConfigFile(path), WithSignature(otherPub, sigPath))
+	if !errors.Is(err, ErrVerification) {
+		t.Fatalf("expected ErrVerification for the wrong key, got %v", err)
+	}
+
+	_, err = Load[testConfig](WithConfigFile(path), WithSignature(pub, sigPath+".missing"))
+	if err == nil || errors.Is(err, ErrVerification) {
+		t.Fatalf("expected a read error for a missing signature, got %v", err)
+	}
+}
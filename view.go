@@ -0,0 +1,161 @@
+// view.go
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// View is a read-only, untyped view of a config for code that can't define
+// a struct up front, such as plugins and templates. Values are looked up by
+// YAML path, with list items as indexes: "server.host", "servers[0].port"
+// or "servers.0.port".
+//
+// The Get* methods return the zero value when the path is missing or the
+// value doesn't convert to the requested type; use IsSet to tell the two
+// apart, or the generic Get to see the error.
+//
+// Example:
+//
+//	view, err := gonfig.LoadView(gonfig.WithConfigFile("config.yaml"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	db := view.Sub("database")
+//	connect(db.GetString("host"), db.GetInt("port"), db.GetDuration("timeout"))
+type View struct {
+	node *yaml.Node
+}
+
+// ErrNotSet is returned by Get when the path has no value.
+var ErrNotSet = errors.New("not set")
+
+// LoadView loads the config like Load, with the same options, but returns
+// a View instead of decoding into a struct.
+func LoadView(opts ...Option) (View, error) {
+	cfg, err := Load[map[string]any](opts...)
+	if err != nil {
+		return View{}, err
+	}
+	return NewView(cfg)
+}
+
+// NewView returns a View of an already loaded config, so a typed result
+// can be handed to code that only knows paths. Keys are the YAML keys of
+// cfg's fields, and Secret values read as "***".
+func NewView(cfg any) (View, error) {
+	n := &yaml.Node{}
+	if err := n.Encode(cfg); err != nil {
+		return View{}, err
+	}
+	flattenSquashed(n, reflect.ValueOf(cfg))
+	return View{node: n}, nil
+}
+
+// lookup returns the node at path, or nil if there is none.
+func (v View) lookup(path string) *yaml.Node {
+	if v.node == nil {
+		return nil
+	}
+	n := nodeAt(v.node, splitPath(path))
+	if n == nil || n.Kind == 0 || n.Tag == "!!null" {
+		return nil
+	}
+	return n
+}
+
+// IsSet reports whether path has a non-null value.
+func (v View) IsSet(path string) bool {
+	return v.lookup(path) != nil
+}
+
+// Get returns the value at path as a string, int, float64, bool,
+// []any or map[string]any.
+func (v View) Get(path string) (any, bool) {
+	n := v.lookup(path)
+	if n == nil {
+		return nil, false
+	}
+	var out any
+	if err := n.Decode(&out); err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// GetString returns the scalar at path as written, e.g. "8080" for a port.
+func (v View) GetString(path string) string {
+	if n := v.lookup(path); n != nil && n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	return ""
+}
+
+// GetInt returns the integer at path.
+func (v View) GetInt(path string) int {
+	i, _ := Get[int](v, path)
+	return i
+}
+
+// GetBool returns the bool at path.
+func (v View) GetBool(path string) bool {
+	b, _ := Get[bool](v, path)
+	return b
+}
+
+// GetDuration returns the duration at path, written like "30s" or "1h30m".
+func (v View) GetDuration(path string) time.Duration {
+	d, _ := Get[time.Duration](v, path)
+	return d
+}
+
+// Keys returns the keys of the mapping at path ("" for the top level). They
+// are sorted for a LoadView and in field order for a NewView of a struct.
+func (v View) Keys(path string) []string {
+	n := v.lookup(path)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]string, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keys = append(keys, n.Content[i].Value)
+	}
+	return keys
+}
+
+// Get returns the value at path in view as a T. Scalars are converted like
+// env overrides, so a quoted "8080" reads as an int, "true" as a bool and
+// "30s" as a time.Duration, and registered decoders apply. Lists and
+// mappings are decoded into T as a whole:
+//
+//	minVersion, err := gonfig.Get[string](view, "server.tls.min_version")
+//	ports, err := gonfig.Get[[]int](view, "server.ports")
+//
+// It fails with ErrNotSet if the path has no value.
+func Get[T any](view View, path string) (T, error) {
+	var out T
+	n := view.lookup(path)
+	if n == nil {
+		return out, fmt.Errorf("%s: %w", path, ErrNotSet)
+	}
+	var err error
+	if n.Kind == yaml.ScalarNode {
+		err = setFromString(reflect.ValueOf(&out).Elem(), n.Value)
+	} else {
+		err = decodeWithHooks(n, &out)
+	}
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// Sub returns the View below path. It is empty if path is missing.
+func (v View) Sub(path string) View {
+	return View{node: v.lookup(path)}
+}
@@ -0,0 +1,108 @@
+package gonfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadView(t *testing.T) {
+	path := writeConfig(t, `app_name: svc
+server:
+  host: ${HOST:-localhost}
+  port: 8080
+  debug: true
+  timeout: 30s
+servers:
+  - name: a
+  - name: b
+`)
+	view, err := LoadView(WithConfigFile(path), WithEnvLookup(func(string) (string, bool) { return "", false }))
+	if err != nil {
+		t.Fatalf("LoadView: %v", err)
+	}
+	if got := view.GetString("server.host"); got != "localhost" {
+		t.Fatalf("server.host = %q", got)
+	}
+	if got := view.GetString("server.port"); got != "8080" {
+		t.Fatalf("server.port as string = %q", got)
+	}
+	if view.GetInt("server.port") != 8080 || !view.GetBool("server.debug") || view.GetDuration("server.timeout") != 30*time.Second {
+		t.Fatalf("unexpected typed values")
+	}
+	if view.GetString("servers[1].name") != "b" || view.GetString("servers.0.name") != "a" {
+		t.Fatalf("list lookups failed")
+	}
+	if view.IsSet("server.missing") || view.GetInt("server.missing") != 0 || view.GetInt("server.host") != 0 {
+		t.Fatalf("missing or mistyped values should be zero")
+	}
+
+	sub := view.Sub("server")
+	if sub.GetInt("port") != 8080 || strings.Join(sub.Keys(""), ",") != "debug,host,port,timeout" {
+		t.Fatalf("unexpected sub view: %v", sub.Keys(""))
+	}
+	if empty := view.Sub("nope"); empty.IsSet("") || empty.GetString("x") != "" {
+		t.Fatalf("Sub of a missing path should be empty")
+	}
+	if v, ok := view.Get("servers"); !ok || len(v.([]any)) != 2 {
+		t.Fatalf("Get(servers) = %v, %v", v, ok)
+	}
+
+	cfg, err := Load[testConfig](WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	typed, err := NewView(cfg)
+	if err != nil {
+		t.Fatalf("NewView: %v", err)
+	}
+	if typed.GetString("app_name") != "svc" || typed.GetDuration("server.timeout") != 30*time.Second {
+		t.Fatalf("unexpected typed view: %v", typed.Keys(""))
+	}
+}
+
+func TestGet(t *testing.T) {
+	path := writeConfig(t, `server:
+  port: "8080"
+  debug: "true"
+  timeout: "1m"
+  size: 10MB
+  ports: [80, 443]
+  tls:
+    min_version: 1.2
+`)
+	view, err := LoadView(WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("LoadView: %v", err)
+	}
+
+	if port, err := Get[int](view, "server.port"); err != nil || port != 8080 {
+		t.Fatalf("Get[int] = %d, %v", port, err)
+	}
+	if debug, err := Get[bool](view, "server.debug"); err != nil || !debug {
+		t.Fatalf("Get[bool] = %v, %v", debug, err)
+	}
+	if d, err := Get[time.Duration](view, "server.timeout"); err != nil || d != time.Minute {
+		t.Fatalf("Get[time.Duration] = %v, %v", d, err)
+	}
+	if size, err := Get[ByteSize](view, "server.size"); err != nil || size != 10_000_000 {
+		t.Fatalf("Get[ByteSize] = %v, %v", size, err)
+	}
+	if v, err := Get[string](view, "server.tls.min_version"); err != nil || v != "1.2" {
+		t.Fatalf("Get[string] = %q, %v", v, err)
+	}
+	if ports, err := Get[[]int](view, "server.ports"); err != nil || len(ports) != 2 || ports[1] != 443 {
+		t.Fatalf("Get[[]int] = %v, %v", ports, err)
+	}
+	if view.GetInt("server.port") != 8080 {
+		t.Fatalf("GetInt should coerce quoted numbers")
+	}
+
+	if _, err := Get[int](view, "server.missing"); !errors.Is(err, ErrNotSet) {
+		t.Fatalf("expected ErrNotSet, got %v", err)
+	}
+	if _, err := Get[int](view, "server.timeout"); err == nil || !strings.HasPrefix(err.Error(), "server.timeout: ") {
+		t.Fatalf("expected a conversion error, got %v", err)
+	}
+}
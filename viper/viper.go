@@ -0,0 +1,83 @@
+// Package viper connects gonfig and viper, for codebases moving from one
+// to the other a package at a time.
+//
+// Serve a gonfig config to code that still reads viper:
+//
+//	import gonfigviper "github.com/TypeTerrors/gonfig/viper"
+//
+//	view, err := gonfig.LoadView(gonfig.WithConfigFile("config.yaml"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := gonfigviper.Merge(viper.GetViper(), view); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Or load a gonfig struct from an existing viper instance:
+//
+//	cfg, err := gonfig.Load[Config](gonfigviper.WithViper(viper.GetViper()))
+//
+// viper lowercases keys, so YAML keys with upper case letters don't match
+// after a round trip.
+package viper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+// New returns a viper instance holding view's values. Use a View from
+// gonfig.LoadView to pass secrets through as they are; one from
+// gonfig.NewView has them masked.
+func New(view gonfig.View) (*viper.Viper, error) {
+	v := viper.New()
+	if err := Merge(v, view); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Merge merges view's values into v's config, overwriting the keys both
+// have. Values set with v.Set, flags and env bindings still take
+// precedence, as usual in viper.
+func Merge(v *viper.Viper, view gonfig.View) error {
+	all, ok := view.Get("")
+	if !ok {
+		return nil
+	}
+	m, ok := all.(map[string]any)
+	if !ok {
+		return fmt.Errorf("gonfig config is a %T, not a mapping", all)
+	}
+	return v.MergeConfigMap(m)
+}
+
+// Source is a gonfig.Source reading the settings of a viper instance.
+type Source struct {
+	v *viper.Viper
+}
+
+// NewSource returns a source of v's settings as they are at each fetch:
+// its config merged with defaults, flags, env bindings and overrides.
+func NewSource(v *viper.Viper) Source {
+	return Source{v: v}
+}
+
+// WithViper reads the config from v, like gonfig.WithSource(NewSource(v)).
+// Placeholders in its values are expanded, and env overrides, defaults and
+// validation apply as usual.
+func WithViper(v *viper.Viper) gonfig.Option {
+	return gonfig.WithSource(NewSource(v))
+}
+
+// Fetch returns v's settings as a YAML document.
+func (s Source) Fetch(context.Context) ([]byte, error) {
+	return yaml.Marshal(s.v.AllSettings())
+}
+
+func (s Source) String() string { return "viper" }
@@ -0,0 +1,51 @@
+package viper
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/TypeTerrors/gonfig"
+)
+
+type config struct {
+	Server struct {
+		Port int    `yaml:"port"`
+		Host string `yaml:"host" default:"localhost"`
+	} `yaml:"server"`
+	Password gonfig.Secret `yaml:"password"`
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+	view, err := gonfig.LoadView(gonfig.WithBytes([]byte("server:\n  port: 8080\npassword: ${DB_PASSWORD}\n")))
+	if err != nil {
+		t.Fatalf("LoadView: %v", err)
+	}
+
+	v, err := New(view)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := v.GetInt("server.port"); got != 8080 {
+		t.Fatalf("server.port = %d, want 8080", got)
+	}
+	if got := v.GetString("password"); got != "hunter2" {
+		t.Fatalf("password = %q, want hunter2", got)
+	}
+}
+
+func TestWithViper(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "hunter2")
+	v := viper.New()
+	v.SetDefault("server.port", 9090)
+	v.Set("password", "${DB_PASSWORD}")
+
+	cfg, err := gonfig.Load[config](WithViper(v))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Port != 9090 || cfg.Server.Host != "localhost" || cfg.Password.Value() != "hunter2" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
@@ -0,0 +1,292 @@
+// watch.go
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/TypeTerrors/gonfig/internal/fswatch"
+)
+
+// Watch loads the config like Load and then keeps watching the config file
+// and any dotenv files for changes.
+//
+// The returned channel first receives the initial config. Every time one of
+// the watched files changes, the config is re-read, re-expanded and
+// re-validated, and the new snapshot is sent on the channel. Reloads that
+// fail (e.g. a half-written file or a Validate() error) are skipped and the
+// previous snapshot stays current; use WithReloadErrorHandler to be told
+// about them. Bursts of changes are coalesced into one reload (see
+// WithReloadDebounce).
+//
+// The channel holds at most one pending snapshot: a slow receiver always
+// gets the latest config rather than a backlog of stale ones. It is closed
+// when ctx is cancelled.
+//
+// Watch needs a config source that implements Watcher, such as a file on
+// disk or a Consul or etcd source; it returns an error when combined with
+// WithFS, WithReader or WithBytes, unless WithRefreshInterval is set.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//
+//	updates, err := gonfig.Watch[Config](ctx,
+//	    gonfig.WithConfigFile("config.yaml"),
+//	    gonfig.WithDotenv(".env.dev"),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for cfg := range updates {
+//	    log.Printf("config loaded: port=%d", cfg.Server.Port)
+//	}
+func Watch[T any](ctx context.Context, opts ...Option) (<-chan T, error) {
+	ch := make(chan T, 1)
+	_, err := startWatch(ctx, newLoader(opts), load[T],
+		func(cfg T) { publishLatest(ch, cfg) },
+		func() { close(ch) },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// startWatch performs the initial load with load, passes it to publish and
+// then reloads in the background whenever the config source or a dotenv
+// file changes. Successful reloads are passed to publish, failures to the
+// handler set by WithReloadErrorHandler. done is called once the watcher
+// stops.
+func startWatch[T any](ctx context.Context, l *loader, load func(*loader) (T, Report, error), publish func(T), done func()) (*watched[T], error) {
+	l.ctx = ctx
+	w, ok := l.source.(Watcher)
+	if !ok && l.refreshInterval <= 0 {
+		return nil, fmt.Errorf("watch: config source %s can't be watched", l.configFile)
+	}
+
+	// changed coalesces notifications from all watchers.
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	// Start watching before the initial load so no change in between is
+	// missed; an early notification only causes an extra reload.
+	watchCtx, cancel := context.WithCancel(ctx)
+	if w != nil {
+		if err := w.Watch(watchCtx, notify); err != nil {
+			cancel()
+			return nil, fmt.Errorf("watch %s: %w", l.configFile, err)
+		}
+	}
+	if ly, ok := l.profileLayer(); ok {
+		if w, ok := ly.src.(Watcher); ok {
+			if err := w.Watch(watchCtx, notify); err != nil {
+				cancel()
+				return nil, fmt.Errorf("watch %s: %w", ly.name, err)
+			}
+		}
+	}
+	if dotenvs := l.dotenvPaths(); len(dotenvs) > 0 {
+		if err := watchFiles(watchCtx, dotenvs, notify); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	if l.signature != nil {
+		// A new document and its signature land in two writes; reload on
+		// both so the pair is verified once it is complete.
+		if err := watchFiles(watchCtx, []string{l.signature.path}, notify); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	for _, dir := range l.secretsDirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := fswatch.Dirs(watchCtx, []string{dir}, func(string) bool { return true }, notify); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	cfg, _, err := load(l)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	publish(cfg)
+	l.reloading = true
+	wd := &watched[T]{l: l, load: load, publish: publish, cur: cfg}
+	wd.setStatus(nil)
+
+	if l.refreshInterval > 0 {
+		go func() {
+			t := time.NewTicker(l.refreshInterval)
+			defer t.Stop()
+			for {
+				select {
+				case <-watchCtx.Done():
+					return
+				case <-t.C:
+					notify()
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer done()
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+				if !debounce(ctx, changed, l.reloadDebounce) {
+					return
+				}
+				err := wd.reload(AuditReload, "")
+				if ctx.Err() != nil {
+					// Stopped mid-reload; that's not a reload error.
+					return
+				}
+				wd.setStatus(err)
+				if err != nil {
+					l.reloadError(err)
+				}
+			}
+		}
+	}()
+
+	return wd, nil
+}
+
+// watched is a config kept up to date by startWatch.
+type watched[T any] struct {
+	l       *loader
+	load    func(*loader) (T, Report, error)
+	publish func(T)
+
+	// mu serializes reloads and overrides; cur is the last config
+	// published.
+	mu  sync.Mutex
+	cur T
+
+	statusMu sync.Mutex
+	status   Status
+}
+
+// reload loads the config again and publishes it, and records what changed
+// with the WithAuditSink sink.
+func (w *watched[T]) reload(kind AuditKind, by string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.reloadLocked(kind, by)
+}
+
+func (w *watched[T]) reloadLocked(kind AuditKind, by string) error {
+	next, _, err := w.load(w.l)
+	if err == nil {
+		// Don't publish a load that raced with the watcher stopping.
+		err = w.l.ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	prev := w.cur
+	w.cur = next
+	w.publish(next)
+	w.l.audit(kind, by, diff(reflect.ValueOf(prev), reflect.ValueOf(next)))
+	return nil
+}
+
+// override reloads the config with value forced at path, like WithOverride,
+// for this and every later reload. The override is dropped if the config
+// doesn't load with it.
+func (w *watched[T]) override(path string, value any, by string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prev := w.l.overrides
+	w.l.overrides = append(slices.Clip(prev), override{path: path, value: value})
+	if err := w.reloadLocked(AuditOverride, by); err != nil {
+		w.l.overrides = prev
+		return err
+	}
+	return nil
+}
+
+// debounce waits until changed has been quiet for d, and reports false if
+// ctx is done first.
+func debounce(ctx context.Context, changed <-chan struct{}, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-changed:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(d)
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// watchFiles watches the parent directory of every path and calls changed
+// when one of the paths is written, created, renamed or removed. Watching
+// directories rather than files keeps working when editors replace files
+// via rename, and when Kubernetes swaps a ConfigMap (see isDataSwap). It
+// stops when ctx is done.
+func watchFiles(ctx context.Context, paths []string, changed func()) error {
+	files := make(map[string]bool, len(paths))
+	var dirs []string
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		files[p] = true
+		dirs = append(dirs, filepath.Dir(p))
+	}
+	return fswatch.Dirs(ctx, dirs, func(name string) bool {
+		return files[filepath.Clean(name)] || isDataSwap(name)
+	}, changed)
+}
+
+// isDataSwap reports whether name is the "..data" symlink of a Kubernetes
+// ConfigMap or Secret volume. Kubernetes updates such a volume by writing a
+// new timestamped directory and renaming a symlink to it over "..data";
+// the files themselves are symlinks through "..data" and see no events.
+func isDataSwap(name string) bool {
+	return filepath.Base(name) == "..data"
+}
+
+// publishLatest sends v on ch, replacing any snapshot the receiver hasn't
+// picked up yet. ch must have a buffer of one and a single sender.
+func publishLatest[T any](ch chan T, v T) {
+	select {
+	case ch <- v:
+	default:
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- v
+	}
+}
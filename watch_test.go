@@ -0,0 +1,229 @@
+package gonfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch_DeliversInitialAndReloadedConfig(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := Watch[testConfig](ctx, WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	cfg := <-updates
+	if cfg.Server.Port != 8080 {
+		t.Fatalf("expected initial port 8080, got %d", cfg.Server.Port)
+	}
+
+	if err := os.WriteFile(path, []byte("server:\n  port: 9090\n"), 0o644); err != nil {
+		t.Fatalf("rewrite config: %v", err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case cfg := <-updates:
+			if cfg.Server.Port == 9090 {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for reloaded config")
+		}
+	}
+}
+
+func TestWatch_ClosesChannelOnCancel(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := Watch[testConfig](ctx, WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	<-updates
+	cancel()
+
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Fatalf("expected channel to be closed after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for channel to close")
+	}
+}
+
+func TestWatch_RequiresFileOnDisk(t *testing.T) {
+	if _, err := Watch[testConfig](context.Background(), WithBytes([]byte("{}"))); err == nil {
+		t.Fatalf("expected error when watching an in-memory config")
+	}
+}
+
+// watchedSource is an in-memory Source whose Watch fires on every set.
+type watchedSource struct {
+	mu      sync.Mutex
+	data    []byte
+	changes chan struct{}
+}
+
+func (s *watchedSource) Fetch(context.Context) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data, nil
+}
+
+func (s *watchedSource) Watch(ctx context.Context, changed func()) error {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.changes:
+				changed()
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *watchedSource) set(data string) {
+	s.mu.Lock()
+	s.data = []byte(data)
+	s.mu.Unlock()
+	s.changes <- struct{}{}
+}
+
+func TestWatch_ReloadsWatchableSource(t *testing.T) {
+	src := &watchedSource{data: []byte("server:\n  port: 8080\n"), changes: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := Watch[testConfig](ctx, WithSource(src))
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if cfg := <-updates; cfg.Server.Port != 8080 {
+		t.Fatalf("expected initial port 8080, got %d", cfg.Server.Port)
+	}
+
+	src.set("server:\n  port: 9090\n")
+
+	select {
+	case cfg := <-updates:
+		if cfg.Server.Port != 9090 {
+			t.Fatalf("expected reloaded port 9090, got %d", cfg.Server.Port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reloaded config")
+	}
+}
+
+func TestWatch_ConfigMapSymlinkSwap(t *testing.T) {
+	// Lay out a directory like a Kubernetes ConfigMap volume:
+	//   config.yaml -> ..data/config.yaml, ..data -> ..v1
+	dir := t.TempDir()
+	writeVersion := func(version, content string) {
+		t.Helper()
+		if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, version, "config.yaml"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	writeVersion("..v1", "server:\n  port: 8080\n")
+	if err := os.Symlink("..v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), path); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates, err := Watch[testConfig](ctx, WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if cfg := <-updates; cfg.Server.Port != 8080 {
+		t.Fatalf("expected initial port 8080, got %d", cfg.Server.Port)
+	}
+
+	// The atomic swap: new version dir, temp symlink, rename over ..data.
+	writeVersion("..v2", "server:\n  port: 9090\n")
+	if err := os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case cfg := <-updates:
+			if cfg.Server.Port == 9090 {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for reloaded config")
+		}
+	}
+}
+
+func TestWatch_DebouncesChunkedWrites(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var reloadErrs []error
+	updates, err := Watch[testConfig](ctx, WithConfigFile(path),
+		WithReloadDebounce(300*time.Millisecond),
+		WithReloadErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reloadErrs = append(reloadErrs, err)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	<-updates
+
+	// An editor writing the file in two chunks: the first one alone is
+	// invalid YAML.
+	if err := os.WriteFile(path, []byte("server:\n  port: [90"), 0o644); err != nil {
+		t.Fatalf("write chunk: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("server:\n  port: 9090\n"), 0o644); err != nil {
+		t.Fatalf("write chunk: %v", err)
+	}
+
+	select {
+	case cfg := <-updates:
+		if cfg.Server.Port != 9090 {
+			t.Fatalf("expected port 9090, got %d", cfg.Server.Port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for reloaded config")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reloadErrs) > 0 {
+		t.Fatalf("the half-written file should never be loaded: %v", reloadErrs)
+	}
+}
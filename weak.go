@@ -0,0 +1,105 @@
+// weak.go
+package gonfig
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// weakenScalars walks n alongside the type t it will be decoded into and
+// re-types every quoted string whose target is a bool or a number, so
+// "8080" decodes into an int and "true" into a bool (WithWeakTypes).
+// Strings that don't parse are left alone and fail to decode as usual.
+func weakenScalars(n *yaml.Node, t reflect.Type) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			weakenScalars(n.Content[0], t)
+		}
+		return
+	case yaml.AliasNode:
+		weakenScalars(n.Alias, t)
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !weakTarget(t) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+			if b, err := strconv.ParseBool(strings.TrimSpace(n.Value)); err == nil {
+				n.Tag, n.Style, n.Value = "!!bool", 0, strconv.FormatBool(b)
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+			// Let yaml.v3 resolve the plain value again.
+			plain := yaml.Node{Kind: yaml.ScalarNode, Value: strings.TrimSpace(n.Value)}
+			if tag := plain.ShortTag(); tag == "!!int" || tag == "!!float" {
+				n.Tag, n.Style, n.Value = tag, 0, plain.Value
+			}
+		}
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		fields, _ := structFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				weakenScalars(v, t)
+				continue
+			}
+			if f, ok := fields[k.Value]; ok {
+				if _, hooked := fieldDecoder(f); !hooked {
+					weakenScalars(v, f.Type)
+				}
+			}
+		}
+	case reflect.Interface:
+		if n.Kind == yaml.MappingNode && hasVariants(t) {
+			if vt, _, err := variantType(t, n); err == nil {
+				weakenScalars(n, vt)
+			}
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			weakenScalars(n.Content[i+1], t.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		if n.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range n.Content {
+			weakenScalars(item, t.Elem())
+		}
+	}
+}
+
+// weakTarget reports whether values of t are decoded by yaml.v3 itself,
+// rather than by a registered decoder or the type's own unmarshal method.
+// time.Duration is left out: a bare "30" is a mistake, not 30ns.
+func weakTarget(t reflect.Type) bool {
+	if t == durationType {
+		return false
+	}
+	if _, ok := decoderFor(t); ok {
+		return false
+	}
+	pt := reflect.PointerTo(t)
+	return !pt.Implements(reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()) &&
+		!pt.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}